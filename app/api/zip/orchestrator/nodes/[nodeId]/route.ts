@@ -9,6 +9,7 @@ const updateNodeSchema = z.object({
   workflowId: z.string(),
   graphId: z.string().optional().default('main'),
   properties: z.record(z.any()).optional(),
+  instanceName: z.string().min(1).max(255).optional(),
   position: z.object({
     x: z.number(),
     y: z.number(),
@@ -41,13 +42,17 @@ export const PATCH = withZIPAuthorization(async (
       }, { status: 400 })
     }
 
-    const { workflowId, graphId, properties, position } = validation.data
+    const { workflowId, graphId, properties, instanceName, position } = validation.data
 
     // Use CRDT operations to update node
     if (properties) {
       await ServerCRDTOperations.updateNodeProperties(workflowId, graphId || 'main', nodeId, properties)
     }
 
+    if (instanceName !== undefined) {
+      await ServerCRDTOperations.updateNodeTitle(workflowId, graphId || 'main', nodeId, instanceName)
+    }
+
     if (position) {
       await ServerCRDTOperations.updateNodePosition(workflowId, graphId || 'main', nodeId, position)
     }
@@ -56,7 +61,7 @@ export const PATCH = withZIPAuthorization(async (
     emitZipEvent(workflowId, createNodeUpdatedEvent(
       workflowId,
       nodeId,
-      { properties, position } as any,
+      { properties, instanceName, position } as any,
       graphId || 'main'
     ))
 
@@ -65,6 +70,7 @@ export const PATCH = withZIPAuthorization(async (
       nodeId,
       updated: {
         properties: !!properties,
+        instanceName: instanceName !== undefined,
         position: !!position,
       },
     })
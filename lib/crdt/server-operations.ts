@@ -762,6 +762,119 @@ export class ServerCRDTOperations {
     return updatedNode!
   }
 
+  /**
+   * Update node title (the instance name shown on the canvas)
+   */
+  static async updateNodeTitle(
+    workflowId: string,
+    graphId: string,
+    nodeId: string,
+    title: string
+  ): Promise<WorkflowNode> {
+    const doc = this.getDoc(workflowId)
+    
+    let updatedNode: WorkflowNode | null = null
+    
+    // Update CRDT document
+    doc.transact(() => {
+      const graphs = doc.getMap('graphs')
+      let graph = graphs.get(graphId) as Y.Map<any>
+      
+      // Initialize graph if it doesn't exist
+      if (!graph) {
+        graph = new Y.Map()
+        graph.set('id', graphId)
+        graph.set('nodes', new Y.Map())
+        graph.set('connections', new Y.Map())
+        graph.set('groups', new Y.Map())
+        graphs.set(graphId, graph)
+      }
+      
+      let nodes = graph.get('nodes') as Y.Map<any>
+      if (!nodes) {
+        nodes = new Y.Map()
+        graph.set('nodes', nodes)
+      }
+      
+      let yNode = nodes.get(nodeId) as Y.Map<any>
+      
+      // If node doesn't exist in CRDT, create a placeholder
+      if (!yNode) {
+        console.log(`[ServerCRDTOperations] Node ${nodeId} not in CRDT, creating placeholder...`)
+        yNode = new Y.Map()
+        yNode.set('id', nodeId)
+        yNode.set('metadata', {})
+        yNode.set('position', { x: 0, y: 0 })
+        nodes.set(nodeId, yNode)
+      }
+      
+      const updatedMetadata = {
+        ...(yNode.get('metadata') || {}),
+        title
+      }
+      yNode.set('metadata', updatedMetadata)
+      
+      updatedNode = {
+        id: nodeId,
+        metadata: updatedMetadata,
+        position: yNode.get('position')
+      }
+    })
+    
+    // Broadcast the update
+    await this.broadcastUpdate({
+      type: 'node-updated',
+      workflowId,
+      graphId,
+      data: {
+        nodeId,
+        title
+      },
+      timestamp: Date.now()
+    })
+    
+    // Emit webhook event
+    await webhookEvents.nodeUpdated(workflowId, graphId, nodeId, { title })
+    
+    // Also update title in database for persistence
+    try {
+      const db = await getDatabaseOperations()
+      console.log(`[ServerCRDTOperations] Updating node title in database for workflow ${workflowId}`)
+      
+      // Get the latest version which contains the graphs
+      const { versions } = await db.listWorkflowVersions(workflowId, { limit: 1 })
+      const latestVersion = versions[0]
+      
+      if (latestVersion && latestVersion.graphs) {
+        const graphs = typeof latestVersion.graphs === 'string'
+          ? JSON.parse(latestVersion.graphs)
+          : latestVersion.graphs || []
+        
+        const graph = graphs.find((g: any) => g.id === graphId)
+        const node = graph?.nodes?.find((n: any) => n.id === nodeId)
+        if (node) {
+          node.metadata = { ...(node.metadata || {}), title }
+          
+          // Update the version with modified graphs
+          await db.updateWorkflowVersion(latestVersion.id, {
+            graphs: JSON.stringify(graphs),
+            isDraft: true,
+            createdAt: new Date().toISOString(),
+          })
+          
+          console.log(`[ServerCRDTOperations] Successfully saved node title to database`)
+        } else {
+          console.warn(`[ServerCRDTOperations] Node ${nodeId} not found in database, skipping database update`)
+        }
+      }
+    } catch (error) {
+      console.error('[ServerCRDTOperations] Error updating title in database:', error)
+      // Continue anyway - CRDT update was successful
+    }
+    
+    return updatedNode!
+  }
+
   /**
    * Update group properties
    */
@@ -1,6 +1,7 @@
 package zeal
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
@@ -9,8 +10,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -29,11 +35,20 @@ type TokenSubject struct {
 
 // TokenOptions contains token generation options
 type TokenOptions struct {
-	ExpiresIn  int      `json:"expires_in,omitempty"`  // seconds
-	Issuer     string   `json:"issuer,omitempty"`
-	Audience   []string `json:"audience,omitempty"`
-	NotBefore  int64    `json:"not_before,omitempty"` // timestamp
-	SecretKey  string   `json:"secret_key,omitempty"` // ZEAL_SECRET_KEY for signing
+	ExpiresIn      int          `json:"expires_in,omitempty"` // seconds
+	Issuer         string       `json:"issuer,omitempty"`
+	Audience       []string     `json:"audience,omitempty"`
+	NotBefore      int64        `json:"not_before,omitempty"`      // timestamp
+	SecretKey      SecretString `json:"secret_key,omitempty"`      // ZEAL_SECRET_KEY for signing
+	OrganizationID string       `json:"organization_id,omitempty"` // used when the subject has none
+	SessionID      string       `json:"session_id,omitempty"`      // random when empty
+}
+
+// WithSessionID returns a copy of the options whose tokens carry id as their
+// session ID, such as the ID of a trace session from TracesAPI.CreateSession
+func (o TokenOptions) WithSessionID(id string) TokenOptions {
+	o.SessionID = id
+	return o
 }
 
 // TokenPayload represents the token payload structure expected by zeal-auth
@@ -55,6 +70,81 @@ type TokenPayload struct {
 	SDKVersion     string                 `json:"sdk_version,omitempty"`
 	ApplicationID  string                 `json:"application_id,omitempty"`
 	SessionID      string                 `json:"session_id,omitempty"`
+
+	sets *tokenPayloadSets // set by the parse functions, see TeamSet
+}
+
+// IssuedAt returns the token issue time
+func (p *TokenPayload) IssuedAt() time.Time {
+	return time.Unix(p.Iat, 0)
+}
+
+// ExpiresAt returns the token expiry time, or nil if the token does not expire
+func (p *TokenPayload) ExpiresAt() *time.Time {
+	if p.Exp <= 0 {
+		return nil
+	}
+	t := time.Unix(p.Exp, 0)
+	return &t
+}
+
+// NotBefore returns the time before which the token is not valid, or nil if unset
+func (p *TokenPayload) NotBefore() *time.Time {
+	if p.Nbf <= 0 {
+		return nil
+	}
+	t := time.Unix(p.Nbf, 0)
+	return &t
+}
+
+// RemainingValidity returns how long the token remains valid.
+// Returns (0, false) for tokens without expiry; expired tokens report 0.
+func (p *TokenPayload) RemainingValidity() (time.Duration, bool) {
+	expiresAt := p.ExpiresAt()
+	if expiresAt == nil {
+		return 0, false
+	}
+	remaining := time.Until(*expiresAt)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// Age returns the time elapsed since the token was issued
+func (p *TokenPayload) Age() time.Duration {
+	return time.Since(p.IssuedAt())
+}
+
+// tokenIdentitySettings holds the SDK version and application ID written
+// into generated tokens
+var tokenIdentitySettings = struct {
+	sync.RWMutex
+	sdkVersion    string
+	applicationID string
+}{sdkVersion: SDKVersion, applicationID: ApplicationID}
+
+// SetSDKApplicationID sets the application_id claim of generated tokens.
+// It defaults to ApplicationID.
+func SetSDKApplicationID(appID string) {
+	tokenIdentitySettings.Lock()
+	defer tokenIdentitySettings.Unlock()
+	tokenIdentitySettings.applicationID = appID
+}
+
+// SetSDKVersion sets the sdk_version claim of generated tokens. It defaults
+// to SDKVersion.
+func SetSDKVersion(version string) {
+	tokenIdentitySettings.Lock()
+	defer tokenIdentitySettings.Unlock()
+	tokenIdentitySettings.sdkVersion = version
+}
+
+// tokenIdentity returns the current SDK version and application ID
+func tokenIdentity() (string, string) {
+	tokenIdentitySettings.RLock()
+	defer tokenIdentitySettings.RUnlock()
+	return tokenIdentitySettings.sdkVersion, tokenIdentitySettings.applicationID
 }
 
 // GenerateAuthToken generates a signed token for self-hosted Zeal integrators
@@ -70,7 +160,7 @@ func GenerateAuthToken(subject *TokenSubject, options *TokenOptions) (string, er
 	}
 
 	// Get secret key from options or environment
-	secretKey := options.SecretKey
+	secretKey := options.SecretKey.Reveal()
 	if secretKey == "" {
 		secretKey = os.Getenv("ZEAL_SECRET_KEY")
 	}
@@ -80,11 +170,17 @@ func GenerateAuthToken(subject *TokenSubject, options *TokenOptions) (string, er
 
 	now := time.Now().Unix()
 
-	// Generate session ID
-	sessionBytes := make([]byte, 8)
-	rand.Read(sessionBytes)
-	sessionID := hex.EncodeToString(sessionBytes)
+	// Generate session ID unless one was given
+	sessionID := options.SessionID
+	if sessionID == "" {
+		sessionBytes := make([]byte, 8)
+		if _, err := io.ReadFull(rand.Reader, sessionBytes); err != nil {
+			return "", fmt.Errorf("failed to generate session ID: %w", err)
+		}
+		sessionID = hex.EncodeToString(sessionBytes)
+	}
 
+	sdkVersion, applicationID := tokenIdentity()
 	payload := TokenPayload{
 		Sub:            subject.ID,
 		Iat:            now,
@@ -96,12 +192,15 @@ func GenerateAuthToken(subject *TokenSubject, options *TokenOptions) (string, er
 		Roles:          subject.Roles,
 		Permissions:    subject.Permissions,
 		Metadata:       subject.Metadata,
-		SDKVersion:     "1.0.0",
-		ApplicationID:  "zeal-go-sdk",
+		SDKVersion:     sdkVersion,
+		ApplicationID:  applicationID,
 		SessionID:      sessionID,
 	}
 
 	// Add optional claims
+	if payload.OrganizationID == "" {
+		payload.OrganizationID = options.OrganizationID
+	}
 	if options.ExpiresIn > 0 {
 		payload.Exp = now + int64(options.ExpiresIn)
 	}
@@ -131,9 +230,38 @@ func GenerateAuthToken(subject *TokenSubject, options *TokenOptions) (string, er
 	return encodedPayload + "." + signature, nil
 }
 
+var (
+	// ErrSDKVersionTooOld is returned when a token was generated by an SDK
+	// older than VerifyOptions.MinSDKVersion
+	ErrSDKVersionTooOld = errors.New("token was generated by an outdated SDK version")
+	// ErrApplicationIDMismatch is returned when a token was generated for a
+	// different application than VerifyOptions.ExpectedApplicationID
+	ErrApplicationIDMismatch = errors.New("token application ID does not match")
+	// ErrOrganizationIDMismatch is returned when a token belongs to a
+	// different organization than VerifyOptions.ExpectedOrganizationID
+	ErrOrganizationIDMismatch = errors.New("token organization ID does not match")
+)
+
+// VerifyOptions contains optional claim checks for token verification
+type VerifyOptions struct {
+	// MinSDKVersion rejects tokens whose sdk_version is older than this
+	// semantic version
+	MinSDKVersion string
+	// ExpectedApplicationID rejects tokens issued for other applications
+	ExpectedApplicationID string
+	// ExpectedOrganizationID rejects tokens for other organizations
+	ExpectedOrganizationID string
+}
+
 // VerifyAndParseToken verifies and parses a signed token
 // Returns parsed token payload or error if invalid
 func VerifyAndParseToken(token string, secretKey string) (*TokenPayload, error) {
+	return VerifyAndParseTokenWithOptions(token, secretKey, nil)
+}
+
+// VerifyAndParseTokenWithOptions verifies and parses a signed token, then
+// applies the claim checks enabled in opts. A nil opts skips them.
+func VerifyAndParseTokenWithOptions(token string, secretKey string, opts *VerifyOptions) (*TokenPayload, error) {
 	if secretKey == "" {
 		secretKey = os.Getenv("ZEAL_SECRET_KEY")
 	}
@@ -164,14 +292,80 @@ func VerifyAndParseToken(token string, secretKey string) (*TokenPayload, error)
 		return nil, fmt.Errorf("failed to decode payload: %w", err)
 	}
 
-	var payload TokenPayload
+	payload := TokenPayload{sets: &tokenPayloadSets{}}
 	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
+	if opts != nil {
+		if err := opts.check(&payload); err != nil {
+			return nil, err
+		}
+	}
+
 	return &payload, nil
 }
 
+// check applies the enabled claim checks to payload
+func (o *VerifyOptions) check(payload *TokenPayload) error {
+	if o.MinSDKVersion != "" {
+		older, err := semverLess(payload.SDKVersion, o.MinSDKVersion)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrSDKVersionTooOld, err)
+		}
+		if older {
+			return fmt.Errorf("%w: %s < %s", ErrSDKVersionTooOld, payload.SDKVersion, o.MinSDKVersion)
+		}
+	}
+	if o.ExpectedApplicationID != "" && payload.ApplicationID != o.ExpectedApplicationID {
+		return fmt.Errorf("%w: got %q", ErrApplicationIDMismatch, payload.ApplicationID)
+	}
+	if o.ExpectedOrganizationID != "" && payload.OrganizationID != o.ExpectedOrganizationID {
+		return fmt.Errorf("%w: got %q", ErrOrganizationIDMismatch, payload.OrganizationID)
+	}
+	return nil
+}
+
+// semverLess reports whether version a is older than version b. Pre-release
+// and build suffixes are ignored.
+func semverLess(a, b string) (bool, error) {
+	va, err := parseSemver(a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := parseSemver(b)
+	if err != nil {
+		return false, err
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return va[i] < vb[i], nil
+		}
+	}
+	return false, nil
+}
+
+// parseSemver parses "major.minor.patch" with an optional "v" prefix
+func parseSemver(version string) ([3]int, error) {
+	var parsed [3]int
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if core == "" || len(parts) > 3 {
+		return parsed, fmt.Errorf("invalid version %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("invalid version %q", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
 // ParseTokenUnsafe parses a token without verification (USE WITH CAUTION)
 // Only use this for debugging or when you don't have the secret key
 func ParseTokenUnsafe(token string) (*TokenPayload, error) {
@@ -187,7 +381,7 @@ func ParseTokenUnsafe(token string) (*TokenPayload, error) {
 		return nil, fmt.Errorf("failed to decode payload: %w", err)
 	}
 
-	var payload TokenPayload
+	payload := TokenPayload{sets: &tokenPayloadSets{}}
 	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
@@ -224,9 +418,21 @@ func CreateUserToken(userID, tenantID string, roles []string, options *TokenOpti
 	}, options)
 }
 
+var (
+	// ErrAPIKeyMustExpire is returned when an API key token is created
+	// without ExpiresIn
+	ErrAPIKeyMustExpire = errors.New("API key tokens must have an expiry")
+	// ErrInvalidScope is returned for scopes not in "resource:action" form
+	ErrInvalidScope = errors.New(`scope must have the form "resource:action"`)
+)
+
 // CreateAPIKeyToken creates an API key token
-// Convenience function for creating API key authentication tokens
+// Convenience function for creating API key authentication tokens.
+// options.ExpiresIn is required; API keys never get non-expiring tokens.
 func CreateAPIKeyToken(apiKeyID, tenantID string, permissions []string, options *TokenOptions) (string, error) {
+	if options == nil || options.ExpiresIn <= 0 {
+		return "", ErrAPIKeyMustExpire
+	}
 	return GenerateAuthToken(&TokenSubject{
 		ID:          apiKeyID,
 		Type:        "api_key",
@@ -239,6 +445,104 @@ func CreateAPIKeyToken(apiKeyID, tenantID string, permissions []string, options
 	}, options)
 }
 
+// CreateAPIKeyTokenWithScopes creates an API key token expiring after
+// expiresIn seconds whose permissions are "resource:action" scopes. Either
+// part may be "*".
+func CreateAPIKeyTokenWithScopes(apiKeyID, tenantID string, scopes []string, expiresIn int, secretKey string) (string, error) {
+	for _, scope := range scopes {
+		if _, _, ok := splitScope(scope); !ok {
+			return "", fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+	}
+	return CreateAPIKeyToken(apiKeyID, tenantID, scopes, &TokenOptions{
+		ExpiresIn: expiresIn,
+		SecretKey: SecretString(secretKey),
+	})
+}
+
+// HasPermission reports whether the token grants permission. Plain
+// permissions must match exactly; "resource:action" scopes also match
+// granted scopes with a "*" resource or action, and "*" grants everything.
+func (p *TokenPayload) HasPermission(permission string) bool {
+	resource, action, isScope := splitScope(permission)
+	for _, granted := range p.Permissions {
+		if granted == permission || granted == "*" {
+			return true
+		}
+		if !isScope {
+			continue
+		}
+		grantedResource, grantedAction, ok := splitScope(granted)
+		if ok && (grantedResource == "*" || grantedResource == resource) && (grantedAction == "*" || grantedAction == action) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitScope splits a "resource:action" scope
+func splitScope(scope string) (resource, action string, ok bool) {
+	resource, action, ok = strings.Cut(scope, ":")
+	if !ok || resource == "" || action == "" || strings.Contains(action, ":") {
+		return "", "", false
+	}
+	return resource, action, true
+}
+
+// TokenPayloadDiff describes how a token changed, see TokenPayload.Diff
+type TokenPayloadDiff struct {
+	AddedRoles         []string
+	RemovedRoles       []string
+	AddedPermissions   []string
+	RemovedPermissions []string
+	AddedTeams         []string
+	RemovedTeams       []string
+	SubjectChanged     bool
+	TenantChanged      bool
+}
+
+// HasSecurityChange reports whether roles, permissions, the subject or the
+// tenant changed. Team, metadata and session changes do not count.
+func (d *TokenPayloadDiff) HasSecurityChange() bool {
+	return d.SubjectChanged || d.TenantChanged ||
+		len(d.AddedRoles) > 0 || len(d.RemovedRoles) > 0 ||
+		len(d.AddedPermissions) > 0 || len(d.RemovedPermissions) > 0
+}
+
+// Diff compares p with other, typically the payloads of a token before and
+// after a refresh. Added entries are in other but not p.
+func (p *TokenPayload) Diff(other *TokenPayload) *TokenPayloadDiff {
+	diff := &TokenPayloadDiff{
+		SubjectChanged: p.Sub != other.Sub,
+		TenantChanged:  p.TenantID != other.TenantID,
+	}
+	diff.AddedRoles, diff.RemovedRoles = diffStrings(p.Roles, other.Roles)
+	diff.AddedPermissions, diff.RemovedPermissions = diffStrings(p.Permissions, other.Permissions)
+	diff.AddedTeams, diff.RemovedTeams = diffStrings(p.Teams, other.Teams)
+	return diff
+}
+
+// diffStrings returns the values only in after and only in before
+func diffStrings(before, after []string) (added, removed []string) {
+	return missingFrom(before, after), missingFrom(after, before)
+}
+
+// missingFrom returns the values of values that are not in set
+func missingFrom(set, values []string) []string {
+	present := make(map[string]bool, len(set))
+	for _, v := range set {
+		present[v] = true
+	}
+	var missing []string
+	for _, v := range values {
+		if !present[v] {
+			missing = append(missing, v)
+			present[v] = true
+		}
+	}
+	return missing
+}
+
 // IsTokenValid validates token expiration and signature
 // Returns true if token is valid and not expired, false otherwise
 func IsTokenValid(token string, secretKey string) bool {
@@ -260,4 +564,196 @@ func IsTokenValid(token string, secretKey string) bool {
 	}
 
 	return true
-}
\ No newline at end of file
+}
+// TokenCache caches a generated token and refreshes it before it expires.
+// It is safe for concurrent use; concurrent refreshes share a single
+// generator call.
+type TokenCache struct {
+	generator     func() (string, error)
+	refreshBefore time.Duration
+	token         string
+	expiresAt     time.Time // zero when the token does not expire
+	inflight      *tokenRefresh
+	mu            sync.RWMutex
+}
+
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// NewTokenCache creates a token cache around a token generator such as
+// a closure over CreateServiceToken. Tokens are refreshed in the background
+// once they are within refreshBefore of expiring.
+func NewTokenCache(generator func() (string, error), refreshBefore time.Duration) *TokenCache {
+	return &TokenCache{
+		generator:     generator,
+		refreshBefore: refreshBefore,
+	}
+}
+
+// Get returns the cached token, generating a new one if none is cached or
+// the cached token has expired
+func (c *TokenCache) Get(ctx context.Context) (string, error) {
+	c.mu.RLock()
+	token, expiresAt := c.token, c.expiresAt
+	c.mu.RUnlock()
+
+	if token != "" && (expiresAt.IsZero() || time.Now().Before(expiresAt)) {
+		if !expiresAt.IsZero() && time.Until(expiresAt) < c.refreshBefore {
+			c.refresh()
+		}
+		return token, nil
+	}
+
+	r := c.refresh()
+	select {
+	case <-r.done:
+		return r.token, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// refresh starts a token refresh, or joins the one already in flight
+func (c *TokenCache) refresh() *tokenRefresh {
+	c.mu.Lock()
+	if c.inflight != nil {
+		r := c.inflight
+		c.mu.Unlock()
+		return r
+	}
+	r := &tokenRefresh{done: make(chan struct{})}
+	c.inflight = r
+	c.mu.Unlock()
+
+	go func() {
+		token, err := c.generator()
+		var expiresAt time.Time
+		if err == nil {
+			if payload, parseErr := ParseTokenUnsafe(token); parseErr == nil {
+				if exp := payload.ExpiresAt(); exp != nil {
+					expiresAt = *exp
+				}
+			}
+		}
+
+		c.mu.Lock()
+		if err == nil {
+			c.token = token
+			c.expiresAt = expiresAt
+		}
+		c.inflight = nil
+		c.mu.Unlock()
+
+		r.token, r.err = token, err
+		close(r.done)
+	}()
+
+	return r
+}
+
+// TokenProvider supplies bearer tokens for authenticating SDK requests
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// oauth2ExpiryDelta is how long before expiry an OAuth2 token is refreshed
+const oauth2ExpiryDelta = 30 * time.Second
+
+// OAuth2Token is an access token issued by an OAuth2 authorization server
+type OAuth2Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"`
+	Expiry      time.Time `json:"-"`
+}
+
+// OAuth2ClientCredentialsProvider exchanges client credentials for an access
+// token using the RFC 6749 client credentials grant. Tokens are cached and
+// refreshed shortly before Expiry.
+type OAuth2ClientCredentialsProvider struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient is used for token requests; defaults to http.DefaultClient.
+	// Set it to route through a corporate proxy.
+	HTTPClient *http.Client
+
+	token *OAuth2Token
+	mu    sync.Mutex
+}
+
+// OAuth2TokenProvider creates a TokenProvider backed by the OAuth2 client credentials grant
+func OAuth2TokenProvider(tokenURL, clientID, clientSecret string, scopes []string) TokenProvider {
+	return &OAuth2ClientCredentialsProvider{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+	}
+}
+
+// Token returns a cached access token, fetching a new one when it is missing
+// or about to expire
+func (p *OAuth2ClientCredentialsProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != nil && (p.token.Expiry.IsZero() || time.Until(p.token.Expiry) > oauth2ExpiryDelta) {
+		return p.token.AccessToken, nil
+	}
+
+	token, err := p.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.token = token
+	return token.AccessToken, nil
+}
+
+func (p *OAuth2ClientCredentialsProvider) fetchToken(ctx context.Context) (*OAuth2Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(p.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed: status %d", resp.StatusCode)
+	}
+
+	var token OAuth2Token
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token response did not include an access token")
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	return &token, nil
+}
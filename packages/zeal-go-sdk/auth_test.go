@@ -0,0 +1,355 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenPayloadTimes(t *testing.T) {
+	now := time.Now().Unix()
+	payload := &TokenPayload{Iat: now - 60, Exp: now + 3600, Nbf: now - 30}
+
+	if !payload.IssuedAt().Equal(time.Unix(now-60, 0)) {
+		t.Errorf("Unexpected IssuedAt: %v", payload.IssuedAt())
+	}
+	if exp := payload.ExpiresAt(); exp == nil || !exp.Equal(time.Unix(now+3600, 0)) {
+		t.Errorf("Unexpected ExpiresAt: %v", exp)
+	}
+	if nbf := payload.NotBefore(); nbf == nil || !nbf.Equal(time.Unix(now-30, 0)) {
+		t.Errorf("Unexpected NotBefore: %v", nbf)
+	}
+
+	remaining, ok := payload.RemainingValidity()
+	if !ok || remaining <= 3590*time.Second || remaining > 3600*time.Second {
+		t.Errorf("Unexpected RemainingValidity: %v, %v", remaining, ok)
+	}
+
+	if age := payload.Age(); age < 60*time.Second || age > 70*time.Second {
+		t.Errorf("Unexpected Age: %v", age)
+	}
+}
+
+func TestTokenPayloadTimesBoundaries(t *testing.T) {
+	noExpiry := &TokenPayload{Iat: time.Now().Unix()}
+	if noExpiry.ExpiresAt() != nil {
+		t.Error("Expected nil ExpiresAt for token without expiry")
+	}
+	if noExpiry.NotBefore() != nil {
+		t.Error("Expected nil NotBefore when unset")
+	}
+	if remaining, ok := noExpiry.RemainingValidity(); ok || remaining != 0 {
+		t.Errorf("Expected (0, false) for token without expiry, got (%v, %v)", remaining, ok)
+	}
+
+	expired := &TokenPayload{Exp: time.Now().Unix() - 10}
+	if remaining, ok := expired.RemainingValidity(); !ok || remaining != 0 {
+		t.Errorf("Expected (0, true) for expired token, got (%v, %v)", remaining, ok)
+	}
+}
+
+func TestTokenCacheSingleFlight(t *testing.T) {
+	var calls int32
+	cache := NewTokenCache(func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return CreateServiceToken("svc", "tenant", nil, &TokenOptions{SecretKey: "secret", ExpiresIn: 3600})
+	}, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Get(context.Background()); err != nil {
+				t.Errorf("Get failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected 1 generator call, got %d", calls)
+	}
+
+	if _, err := cache.Get(context.Background()); err != nil || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected cached token to be reused, calls=%d err=%v", calls, err)
+	}
+}
+
+func TestOAuth2TokenProvider(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "client" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "read write" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"abc123","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	provider := OAuth2TokenProvider(server.URL, "client", "secret", []string{"read", "write"})
+	for i := 0; i < 3; i++ {
+		token, err := provider.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		if token != "abc123" {
+			t.Errorf("Expected token 'abc123', got '%s'", token)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected token to be cached after 1 request, got %d", requests)
+	}
+}
+
+func TestSecretStringRedaction(t *testing.T) {
+	config := DefaultClientConfig()
+	config.AuthToken = "super-secret"
+
+	for _, out := range []string{fmt.Sprintf("%v", config), fmt.Sprintf("%+v", config), fmt.Sprintf("%#v", config)} {
+		if strings.Contains(out, "super-secret") {
+			t.Errorf("Expected token to be redacted, got %s", out)
+		}
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "super-secret") || !strings.Contains(string(data), `"authToken":"[REDACTED]"`) {
+		t.Errorf("Expected token to be redacted in JSON, got %s", data)
+	}
+
+	if config.AuthToken.Reveal() != "super-secret" {
+		t.Errorf("Expected Reveal to return the token, got %s", config.AuthToken.Reveal())
+	}
+}
+
+func TestVerifyAndParseTokenWithOptions(t *testing.T) {
+	token, err := GenerateAuthToken(&TokenSubject{ID: "user-1"}, &TokenOptions{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("GenerateAuthToken failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts *VerifyOptions
+		want error
+	}{
+		{"no options", nil, nil},
+		{"older minimum", &VerifyOptions{MinSDKVersion: "0.9"}, nil},
+		{"equal minimum", &VerifyOptions{MinSDKVersion: "v1.0.0"}, nil},
+		{"newer minimum", &VerifyOptions{MinSDKVersion: "1.2.0"}, ErrSDKVersionTooOld},
+		{"invalid minimum", &VerifyOptions{MinSDKVersion: "latest"}, ErrSDKVersionTooOld},
+		{"matching application", &VerifyOptions{ExpectedApplicationID: ApplicationID}, nil},
+		{"other application", &VerifyOptions{ExpectedApplicationID: "other-app"}, ErrApplicationIDMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := VerifyAndParseTokenWithOptions(token, "secret", test.opts)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("Expected %v, got %v", test.want, err)
+			}
+			if test.want == nil && payload.Sub != "user-1" {
+				t.Errorf("Unexpected payload %+v", payload)
+			}
+		})
+	}
+}
+
+func TestCreateAPIKeyTokenRequiresExpiry(t *testing.T) {
+	if _, err := CreateAPIKeyToken("key-1", "tenant", nil, &TokenOptions{SecretKey: "secret"}); !errors.Is(err, ErrAPIKeyMustExpire) {
+		t.Errorf("Expected ErrAPIKeyMustExpire, got %v", err)
+	}
+	if _, err := CreateAPIKeyToken("key-1", "tenant", nil, nil); !errors.Is(err, ErrAPIKeyMustExpire) {
+		t.Errorf("Expected ErrAPIKeyMustExpire for nil options, got %v", err)
+	}
+
+	token, err := CreateAPIKeyToken("key-1", "tenant", nil, &TokenOptions{SecretKey: "secret", ExpiresIn: 60})
+	if err != nil {
+		t.Fatalf("CreateAPIKeyToken failed: %v", err)
+	}
+	payload, _ := VerifyAndParseToken(token, "secret")
+	if payload.ExpiresAt() == nil {
+		t.Error("Expected API key token to expire")
+	}
+}
+
+func TestCreateAPIKeyTokenWithScopes(t *testing.T) {
+	if _, err := CreateAPIKeyTokenWithScopes("key-1", "tenant", []string{"workflows"}, 60, "secret"); !errors.Is(err, ErrInvalidScope) {
+		t.Errorf("Expected ErrInvalidScope, got %v", err)
+	}
+
+	token, err := CreateAPIKeyTokenWithScopes("key-1", "tenant", []string{"workflows:read", "traces:*"}, 60, "secret")
+	if err != nil {
+		t.Fatalf("CreateAPIKeyTokenWithScopes failed: %v", err)
+	}
+	payload, err := VerifyAndParseToken(token, "secret")
+	if err != nil {
+		t.Fatalf("VerifyAndParseToken failed: %v", err)
+	}
+
+	tests := map[string]bool{
+		"workflows:read":  true,
+		"workflows:write": false,
+		"traces:write":    true,
+		"templates:read":  false,
+		"workflows":       false,
+	}
+	for permission, want := range tests {
+		if got := payload.HasPermission(permission); got != want {
+			t.Errorf("HasPermission(%q) = %v, want %v", permission, got, want)
+		}
+	}
+
+	admin := &TokenPayload{Permissions: []string{"*"}}
+	if !admin.HasPermission("anything") {
+		t.Error("Expected * to grant every permission")
+	}
+	reader := &TokenPayload{Permissions: []string{"*:read", "deploy"}}
+	if !reader.HasPermission("templates:read") || reader.HasPermission("templates:write") || !reader.HasPermission("deploy") {
+		t.Errorf("Unexpected scope matching for %v", reader.Permissions)
+	}
+}
+
+func TestTokenOrganizationID(t *testing.T) {
+	options := &TokenOptions{SecretKey: "secret", ExpiresIn: 60, OrganizationID: "org-1"}
+	creators := map[string]func() (string, error){
+		"service": func() (string, error) { return CreateServiceToken("svc", "tenant", nil, options) },
+		"user":    func() (string, error) { return CreateUserToken("user", "tenant", nil, options) },
+		"api key": func() (string, error) { return CreateAPIKeyToken("key", "tenant", nil, options) },
+	}
+	for name, create := range creators {
+		token, err := create()
+		if err != nil {
+			t.Fatalf("%s: create failed: %v", name, err)
+		}
+		payload, err := VerifyAndParseTokenWithOptions(token, "secret", &VerifyOptions{ExpectedOrganizationID: "org-1"})
+		if err != nil {
+			t.Fatalf("%s: verify failed: %v", name, err)
+		}
+		if payload.OrganizationID != "org-1" {
+			t.Errorf("%s: expected org-1, got %q", name, payload.OrganizationID)
+		}
+		if _, err := VerifyAndParseTokenWithOptions(token, "secret", &VerifyOptions{ExpectedOrganizationID: "org-2"}); !errors.Is(err, ErrOrganizationIDMismatch) {
+			t.Errorf("%s: expected ErrOrganizationIDMismatch, got %v", name, err)
+		}
+	}
+
+	token, _ := GenerateAuthToken(&TokenSubject{ID: "user", OrganizationID: "org-3"}, options)
+	if payload, _ := VerifyAndParseToken(token, "secret"); payload.OrganizationID != "org-3" {
+		t.Errorf("Expected subject organization to win, got %q", payload.OrganizationID)
+	}
+}
+
+func TestSetSDKIdentity(t *testing.T) {
+	SetSDKApplicationID("acme-app")
+	SetSDKVersion("2.1.0")
+	defer SetSDKApplicationID(ApplicationID)
+	defer SetSDKVersion(SDKVersion)
+
+	token, err := GenerateAuthToken(&TokenSubject{ID: "user"}, &TokenOptions{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("GenerateAuthToken failed: %v", err)
+	}
+	payload, err := VerifyAndParseTokenWithOptions(token, "secret", &VerifyOptions{MinSDKVersion: "2.0.0", ExpectedApplicationID: "acme-app"})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if payload.SDKVersion != "2.1.0" || payload.ApplicationID != "acme-app" {
+		t.Errorf("Unexpected identity %s/%s", payload.SDKVersion, payload.ApplicationID)
+	}
+}
+
+func TestGenerateAuthTokenUniqueSessionIDs(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		token, err := GenerateAuthToken(&TokenSubject{ID: "user"}, &TokenOptions{SecretKey: "secret"})
+		if err != nil {
+			t.Fatalf("GenerateAuthToken failed: %v", err)
+		}
+		payload, err := ParseTokenUnsafe(token)
+		if err != nil {
+			t.Fatalf("ParseTokenUnsafe failed: %v", err)
+		}
+		if seen[payload.SessionID] {
+			t.Fatalf("Duplicate session ID %s after %d tokens", payload.SessionID, i)
+		}
+		seen[payload.SessionID] = true
+	}
+}
+
+func TestGenerateAuthTokenWithSessionID(t *testing.T) {
+	options := TokenOptions{SecretKey: "secret"}.WithSessionID("trace-session-1")
+	token, err := GenerateAuthToken(&TokenSubject{ID: "user"}, &options)
+	if err != nil {
+		t.Fatalf("GenerateAuthToken failed: %v", err)
+	}
+	payload, err := VerifyAndParseToken(token, "secret")
+	if err != nil {
+		t.Fatalf("VerifyAndParseToken failed: %v", err)
+	}
+	if payload.SessionID != "trace-session-1" {
+		t.Errorf("Expected session ID trace-session-1, got %q", payload.SessionID)
+	}
+}
+
+func TestTokenPayloadDiff(t *testing.T) {
+	before := &TokenPayload{
+		Sub:         "user-1",
+		TenantID:    "tenant-1",
+		Roles:       []string{"viewer", "editor"},
+		Permissions: []string{"workflows:read"},
+		Teams:       []string{"core"},
+		SessionID:   "s-1",
+	}
+
+	refreshed := *before
+	refreshed.SessionID = "s-2"
+	refreshed.Teams = []string{"core", "infra"}
+	diff := before.Diff(&refreshed)
+	if diff.HasSecurityChange() {
+		t.Errorf("Expected no security change, got %+v", diff)
+	}
+	if !reflect.DeepEqual(diff.AddedTeams, []string{"infra"}) || diff.RemovedTeams != nil {
+		t.Errorf("Unexpected team diff %+v", diff)
+	}
+
+	escalated := *before
+	escalated.Roles = []string{"editor", "admin"}
+	escalated.Permissions = []string{"workflows:read", "workflows:delete"}
+	diff = before.Diff(&escalated)
+	if !diff.HasSecurityChange() {
+		t.Error("Expected security change")
+	}
+	if !reflect.DeepEqual(diff.AddedRoles, []string{"admin"}) || !reflect.DeepEqual(diff.RemovedRoles, []string{"viewer"}) {
+		t.Errorf("Unexpected role diff %+v", diff)
+	}
+	if !reflect.DeepEqual(diff.AddedPermissions, []string{"workflows:delete"}) || diff.RemovedPermissions != nil {
+		t.Errorf("Unexpected permission diff %+v", diff)
+	}
+
+	moved := *before
+	moved.TenantID = "tenant-2"
+	if diff := before.Diff(&moved); !diff.TenantChanged || diff.SubjectChanged || !diff.HasSecurityChange() {
+		t.Errorf("Expected tenant change, got %+v", diff)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build pubsub
+
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PubSubPublisher is the subset of a Google Cloud Pub/Sub client used by PubSubEventBridge
+type PubSubPublisher interface {
+	Publish(ctx context.Context, topicID string, data []byte, attrs map[string]string) (serverID string, err error)
+}
+
+// PubSubSubscriber is the subset of a Google Cloud Pub/Sub client used by
+// SubscribePubSubEvents. The returned channel yields message data and closes
+// when the subscription ends.
+type PubSubSubscriber interface {
+	Subscribe(ctx context.Context, subscriptionID string) (<-chan []byte, error)
+}
+
+// PubSubEventBridge returns an event callback that publishes webhook events to
+// the topic chosen by topicFn
+func PubSubEventBridge(client PubSubPublisher, topicFn func(map[string]interface{}) string) WebhookEventCallback {
+	return func(ctx context.Context, event map[string]interface{}) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event for Pub/Sub: %w", err)
+		}
+
+		eventType, _ := event["type"].(string)
+		workflowID, _ := event["workflowId"].(string)
+		eventID, _ := event["id"].(string)
+		attrs := map[string]string{
+			"event_type":     eventType,
+			"workflow_id":    workflowID,
+			"event_id":       eventID,
+			"sdk_version":    SDKVersion,
+			"application_id": ApplicationID,
+		}
+
+		if _, err := client.Publish(ctx, topicFn(event), data, attrs); err != nil {
+			return fmt.Errorf("failed to publish event to Pub/Sub: %w", err)
+		}
+		return nil
+	}
+}
+
+// SubscribePubSubEvents consumes events published by PubSubEventBridge.
+// Messages that are not valid ZIP events are skipped.
+func SubscribePubSubEvents(client PubSubSubscriber, subscriptionID string) (<-chan ZipWebhookEvent, error) {
+	messages, err := client.Subscribe(context.Background(), subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to Pub/Sub subscription %s: %w", subscriptionID, err)
+	}
+
+	events := make(chan ZipWebhookEvent)
+	go func() {
+		defer close(events)
+		for data := range messages {
+			event, err := ParseZipWebhookEvent(data)
+			if err != nil {
+				continue
+			}
+			events <- event
+		}
+	}()
+
+	return events, nil
+}
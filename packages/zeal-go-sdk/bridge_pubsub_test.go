@@ -0,0 +1,56 @@
+//go:build pubsub
+
+package zeal
+
+import (
+	"context"
+	"testing"
+)
+
+type mockPubSub struct {
+	topic    string
+	attrs    map[string]string
+	messages chan []byte
+}
+
+func (m *mockPubSub) Publish(ctx context.Context, topicID string, data []byte, attrs map[string]string) (string, error) {
+	m.topic = topicID
+	m.attrs = attrs
+	m.messages <- data
+	return "msg-1", nil
+}
+
+func (m *mockPubSub) Subscribe(ctx context.Context, subscriptionID string) (<-chan []byte, error) {
+	return m.messages, nil
+}
+
+func TestPubSubEventBridge(t *testing.T) {
+	pubsub := &mockPubSub{messages: make(chan []byte, 1)}
+	bridge := PubSubEventBridge(pubsub, func(map[string]interface{}) string { return "zeal-events" })
+
+	err := bridge(context.Background(), map[string]interface{}{"id": "evt_1", "type": "node.failed", "workflowId": "wf-1", "nodeId": "n1"})
+	if err != nil {
+		t.Fatalf("Bridge failed: %v", err)
+	}
+
+	if pubsub.topic != "zeal-events" {
+		t.Errorf("Expected topic 'zeal-events', got '%s'", pubsub.topic)
+	}
+	for key, expected := range map[string]string{
+		"event_type":     "node.failed",
+		"workflow_id":    "wf-1",
+		"event_id":       "evt_1",
+		"sdk_version":    SDKVersion,
+		"application_id": ApplicationID,
+	} {
+		if pubsub.attrs[key] != expected {
+			t.Errorf("Expected attribute %s=%s, got %s", key, expected, pubsub.attrs[key])
+		}
+	}
+
+	events, _ := SubscribePubSubEvents(pubsub, "zeal-sub")
+	close(pubsub.messages)
+	if event, ok := (<-events).(*NodeFailedEvent); !ok || event.NodeID != "n1" {
+		t.Errorf("Expected NodeFailedEvent for n1, got %#v", event)
+	}
+}
@@ -0,0 +1,73 @@
+//go:build redis
+
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// RedisPublisher is the subset of a Redis client used by RedisPubSubBridge
+type RedisPublisher interface {
+	Publish(ctx context.Context, channel string, payload interface{}) error
+}
+
+// RedisSubscriber is the subset of a Redis client used by SubscribeRedisEvents.
+// The returned channel yields raw message payloads and closes when the
+// subscription ends.
+type RedisSubscriber interface {
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
+}
+
+// DefaultRedisChannel returns a channel function producing
+// "{prefix}:{workflowId}:{eventType}"
+func DefaultRedisChannel(prefix string) func(map[string]interface{}) string {
+	return func(event map[string]interface{}) string {
+		workflowID, _ := event["workflowId"].(string)
+		eventType, _ := event["type"].(string)
+		return fmt.Sprintf("%s:%s:%s", prefix, workflowID, eventType)
+	}
+}
+
+// RedisPubSubBridge returns an event callback that publishes webhook events to
+// Redis. A nil channelFn uses DefaultRedisChannel("zeal").
+func RedisPubSubBridge(client RedisPublisher, channelFn func(map[string]interface{}) string) WebhookEventCallback {
+	if channelFn == nil {
+		channelFn = DefaultRedisChannel("zeal")
+	}
+
+	return func(ctx context.Context, event map[string]interface{}) error {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event for Redis: %w", err)
+		}
+		if err := client.Publish(ctx, channelFn(event), string(payload)); err != nil {
+			return fmt.Errorf("failed to publish event to Redis: %w", err)
+		}
+		return nil
+	}
+}
+
+// SubscribeRedisEvents consumes events published by RedisPubSubBridge.
+// Messages that are not valid ZIP events are skipped.
+func SubscribeRedisEvents(client RedisSubscriber, channel string) (<-chan ZipWebhookEvent, error) {
+	messages, err := client.Subscribe(context.Background(), channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to Redis channel %s: %w", channel, err)
+	}
+
+	events := make(chan ZipWebhookEvent)
+	go func() {
+		defer close(events)
+		for message := range messages {
+			event, err := ParseZipWebhookEvent([]byte(message))
+			if err != nil {
+				continue
+			}
+			events <- event
+		}
+	}()
+
+	return events, nil
+}
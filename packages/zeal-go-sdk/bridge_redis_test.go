@@ -0,0 +1,45 @@
+//go:build redis
+
+package zeal
+
+import (
+	"context"
+	"testing"
+)
+
+type mockRedis struct {
+	channels map[string]chan string
+}
+
+func (m *mockRedis) Publish(ctx context.Context, channel string, payload interface{}) error {
+	if ch, ok := m.channels[channel]; ok {
+		ch <- payload.(string)
+	}
+	return nil
+}
+
+func (m *mockRedis) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	ch := make(chan string, 10)
+	m.channels[channel] = ch
+	return ch, nil
+}
+
+func TestRedisPubSubBridge(t *testing.T) {
+	redis := &mockRedis{channels: make(map[string]chan string)}
+	events, err := SubscribeRedisEvents(redis, "zeal:wf-1:node.completed")
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	bridge := RedisPubSubBridge(redis, nil)
+	if err := bridge(context.Background(), map[string]interface{}{"type": "node.completed", "workflowId": "wf-1", "nodeId": "n1"}); err != nil {
+		t.Fatalf("Bridge failed: %v", err)
+	}
+	close(redis.channels["zeal:wf-1:node.completed"])
+
+	event := <-events
+	completed, ok := event.(*NodeCompletedEvent)
+	if !ok || completed.NodeID != "n1" {
+		t.Errorf("Expected NodeCompletedEvent for n1, got %#v", event)
+	}
+}
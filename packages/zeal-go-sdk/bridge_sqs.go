@@ -0,0 +1,70 @@
+//go:build awssqs
+
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SQSClient is the subset of an SQS client used by SQSEventBridge
+type SQSClient interface {
+	SendMessage(ctx context.Context, queueURL, body, deduplicationID, groupID string) error
+}
+
+// SQSAttributeClient is optionally implemented by an SQSClient that can send
+// message attributes alongside the body
+type SQSAttributeClient interface {
+	SendMessageWithAttributes(ctx context.Context, queueURL, body, deduplicationID, groupID string, attributes map[string]string) error
+}
+
+// SQSBridgeOptions configures SQSEventBridge
+type SQSBridgeOptions struct {
+	// UseMessageGroupID sets the FIFO message group to the event's workflowId
+	UseMessageGroupID bool `json:"useMessageGroupId"`
+	// AttributesFromMetadata sends the event's metadata as message attributes
+	// when the client implements SQSAttributeClient
+	AttributesFromMetadata bool `json:"attributesFromMetadata"`
+}
+
+// SQSEventBridge returns an event callback that forwards webhook events to an
+// SQS queue. The event ID is used as the deduplication ID.
+func SQSEventBridge(client SQSClient, queueURL string, opts *SQSBridgeOptions) WebhookEventCallback {
+	if opts == nil {
+		opts = &SQSBridgeOptions{}
+	}
+
+	return func(ctx context.Context, event map[string]interface{}) error {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event for SQS: %w", err)
+		}
+
+		deduplicationID, _ := event["id"].(string)
+		groupID := ""
+		if opts.UseMessageGroupID {
+			groupID, _ = event["workflowId"].(string)
+		}
+
+		if opts.AttributesFromMetadata {
+			if attrClient, ok := client.(SQSAttributeClient); ok {
+				attributes := make(map[string]string)
+				if metadata, ok := event["metadata"].(map[string]interface{}); ok {
+					for key, value := range metadata {
+						attributes[key] = fmt.Sprint(value)
+					}
+				}
+				if err := attrClient.SendMessageWithAttributes(ctx, queueURL, string(body), deduplicationID, groupID, attributes); err != nil {
+					return fmt.Errorf("failed to send event to SQS: %w", err)
+				}
+				return nil
+			}
+		}
+
+		if err := client.SendMessage(ctx, queueURL, string(body), deduplicationID, groupID); err != nil {
+			return fmt.Errorf("failed to send event to SQS: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+//go:build awssqs
+
+package zeal
+
+import (
+	"context"
+	"testing"
+)
+
+type mockSQSClient struct {
+	bodies     []string
+	dedupIDs   []string
+	groupIDs   []string
+	attributes []map[string]string
+}
+
+func (m *mockSQSClient) SendMessage(ctx context.Context, queueURL, body, deduplicationID, groupID string) error {
+	m.bodies = append(m.bodies, body)
+	m.dedupIDs = append(m.dedupIDs, deduplicationID)
+	m.groupIDs = append(m.groupIDs, groupID)
+	return nil
+}
+
+func (m *mockSQSClient) SendMessageWithAttributes(ctx context.Context, queueURL, body, deduplicationID, groupID string, attributes map[string]string) error {
+	m.attributes = append(m.attributes, attributes)
+	return m.SendMessage(ctx, queueURL, body, deduplicationID, groupID)
+}
+
+func TestSQSEventBridge(t *testing.T) {
+	client := &mockSQSClient{}
+	bridge := SQSEventBridge(client, "https://sqs.example.com/queue.fifo", &SQSBridgeOptions{
+		UseMessageGroupID:      true,
+		AttributesFromMetadata: true,
+	})
+
+	err := bridge(context.Background(), map[string]interface{}{
+		"id":         "evt_1",
+		"type":       "node.completed",
+		"workflowId": "wf-1",
+		"metadata":   map[string]interface{}{"env": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("Bridge failed: %v", err)
+	}
+
+	if client.dedupIDs[0] != "evt_1" || client.groupIDs[0] != "wf-1" {
+		t.Errorf("Unexpected dedup/group IDs: %v %v", client.dedupIDs, client.groupIDs)
+	}
+	if client.attributes[0]["env"] != "prod" {
+		t.Errorf("Expected metadata attributes, got %v", client.attributes[0])
+	}
+}
@@ -0,0 +1,132 @@
+package zeal
+
+import "fmt"
+
+// WorkflowBuilder describes a workflow's nodes and connections locally so it
+// can be checked with Validate before any API call is made
+type WorkflowBuilder struct {
+	name        string
+	nodes       []builderNode
+	connections []builderConnection
+	templates   map[string]NodeTemplate
+}
+
+type builderNode struct {
+	ref        string
+	templateID string
+	position   Position
+	properties map[string]interface{}
+}
+
+type builderConnection struct {
+	source NodePort
+	target NodePort
+}
+
+// BuildValidationError describes a problem found by WorkflowBuilder.Validate.
+// NodeRef is set for node problems, Connection for connection problems.
+type BuildValidationError struct {
+	NodeRef    string
+	Connection *ConnectNodesRequest
+	Message    string
+}
+
+func (e BuildValidationError) Error() string {
+	if e.Connection != nil {
+		return fmt.Sprintf("connection %s:%s -> %s:%s: %s",
+			e.Connection.Source.NodeID, e.Connection.Source.PortID,
+			e.Connection.Target.NodeID, e.Connection.Target.PortID, e.Message)
+	}
+	return fmt.Sprintf("node %s: %s", e.NodeRef, e.Message)
+}
+
+// NewWorkflowBuilder creates an empty builder for a workflow named name
+func NewWorkflowBuilder(name string) *WorkflowBuilder {
+	return &WorkflowBuilder{name: name}
+}
+
+// WithTemplates supplies the templates, keyed by template ID, used to check
+// node templates and connection ports in Validate
+func (b *WorkflowBuilder) WithTemplates(templates map[string]NodeTemplate) *WorkflowBuilder {
+	b.templates = templates
+	return b
+}
+
+// AddNode adds a node created from templateID. ref identifies the node in
+// Connect calls.
+func (b *WorkflowBuilder) AddNode(ref, templateID string, position Position, properties map[string]interface{}) *WorkflowBuilder {
+	b.nodes = append(b.nodes, builderNode{ref: ref, templateID: templateID, position: position, properties: properties})
+	return b
+}
+
+// Connect connects sourcePort of the node sourceRef to targetPort of the node targetRef
+func (b *WorkflowBuilder) Connect(sourceRef, sourcePort, targetRef, targetPort string) *WorkflowBuilder {
+	b.connections = append(b.connections, builderConnection{
+		source: NodePort{NodeID: sourceRef, PortID: sourcePort},
+		target: NodePort{NodeID: targetRef, PortID: targetPort},
+	})
+	return b
+}
+
+// Validate checks the workflow without calling the API and returns every
+// problem found: duplicate node references, duplicate and self connections,
+// connections to unknown nodes and, when templates were supplied, unknown
+// templates and ports.
+func (b *WorkflowBuilder) Validate() []BuildValidationError {
+	var errs []BuildValidationError
+
+	nodes := make(map[string]builderNode, len(b.nodes))
+	for _, node := range b.nodes {
+		if _, ok := nodes[node.ref]; ok {
+			errs = append(errs, BuildValidationError{NodeRef: node.ref, Message: "duplicate node reference"})
+			continue
+		}
+		nodes[node.ref] = node
+		if b.templates != nil {
+			if _, ok := b.templates[node.templateID]; !ok {
+				errs = append(errs, BuildValidationError{NodeRef: node.ref, Message: fmt.Sprintf("unknown template %s", node.templateID)})
+			}
+		}
+	}
+
+	seen := make(map[builderConnection]bool, len(b.connections))
+	for _, conn := range b.connections {
+		connErr := func(format string, args ...interface{}) {
+			errs = append(errs, BuildValidationError{
+				Connection: &ConnectNodesRequest{Source: conn.source, Target: conn.target},
+				Message:    fmt.Sprintf(format, args...),
+			})
+		}
+
+		if seen[conn] {
+			connErr("duplicate connection")
+			continue
+		}
+		seen[conn] = true
+
+		if conn.source.NodeID == conn.target.NodeID {
+			connErr("node cannot connect to itself")
+		}
+		for _, end := range []NodePort{conn.source, conn.target} {
+			node, ok := nodes[end.NodeID]
+			if !ok {
+				connErr("unknown node %s", end.NodeID)
+				continue
+			}
+			if template, ok := b.templates[node.templateID]; ok && !templateHasPort(template, end.PortID) {
+				connErr("template %s has no port %s", node.templateID, end.PortID)
+			}
+		}
+	}
+
+	return errs
+}
+
+func templateHasPort(template NodeTemplate, portID string) bool {
+	for _, port := range template.Ports {
+		if port.ID == portID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package zeal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWorkflowBuilderValidate(t *testing.T) {
+	templates := map[string]NodeTemplate{
+		"http": {ID: "http", Ports: []Port{{ID: "in", Type: "input"}, {ID: "out", Type: "output"}}},
+	}
+
+	valid := NewWorkflowBuilder("ok").
+		WithTemplates(templates).
+		AddNode("a", "http", Position{}, nil).
+		AddNode("b", "http", Position{X: 200}, nil).
+		Connect("a", "out", "b", "in")
+	if errs := valid.Validate(); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+
+	invalid := NewWorkflowBuilder("bad").
+		WithTemplates(templates).
+		AddNode("a", "http", Position{}, nil).
+		AddNode("a", "http", Position{}, nil).
+		AddNode("c", "missing", Position{}, nil).
+		Connect("a", "out", "a", "in").
+		Connect("a", "nope", "x", "in").
+		Connect("a", "nope", "x", "in")
+
+	errs := invalid.Validate()
+	want := []string{
+		"duplicate node reference",
+		"unknown template missing",
+		"node cannot connect to itself",
+		"template http has no port nope",
+		"unknown node x",
+		"duplicate connection",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(want), len(errs), errs)
+	}
+	for i, msg := range want {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("Expected error %d to contain %q, got %q", i, msg, errs[i].Error())
+		}
+	}
+}
@@ -1,14 +1,18 @@
 package zeal
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -18,14 +22,96 @@ const (
 	ApplicationID = "zeal-go-sdk"
 )
 
+// FormatUserAgent builds a User-Agent of the form
+// "zeal-go-sdk/<sdkVersion> Go/<go version> <os>", followed by extra if set
+func FormatUserAgent(sdkVersion, extra string) string {
+	userAgent := fmt.Sprintf("zeal-go-sdk/%s Go/%s %s", sdkVersion, strings.TrimPrefix(runtime.Version(), "go"), runtime.GOOS)
+	if extra != "" {
+		userAgent += " " + extra
+	}
+	return userAgent
+}
+
+// ErrNotFound is returned when a requested resource does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrNoTemplateSource is returned by GetWorkflowTemplate for workflows that
+// were not created from a template
+var ErrNoTemplateSource = errors.New("workflow was not created from a template")
+
+// ErrTemplateInUse is returned by TemplatesAPI.SafeDelete when workflows
+// still use the template
+var ErrTemplateInUse = errors.New("template is used by workflows")
+
+// APIError is returned when the Zeal server responds with an HTTP error status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether the server responded with 404 Not Found
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// Is lets errors.Is match a 404 APIError against ErrNotFound
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.IsNotFound()
+}
+
+// ErrVersionConflict matches a VersionConflictError with errors.Is
+var ErrVersionConflict = errors.New("workflow version conflict")
+
+// VersionConflictError is returned when a change made with ExpectedVersion is
+// rejected because the workflow has changed since. Fetch the state again with
+// GetWorkflowState and retry with its Version.
+type VersionConflictError struct {
+	WorkflowID      string
+	ExpectedVersion int
+	// CurrentVersion is the version reported by the server, or 0 if it did
+	// not report one
+	CurrentVersion int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("workflow %s version conflict: expected %d, current %d", e.WorkflowID, e.ExpectedVersion, e.CurrentVersion)
+}
+
+// Is lets errors.Is match a VersionConflictError against ErrVersionConflict
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// versionConflict converts a 409 response to a change made with
+// expectedVersion into a VersionConflictError; other errors are returned
+// unchanged
+func versionConflict(err error, workflowID string, expectedVersion *int) error {
+	var apiErr *APIError
+	if expectedVersion == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		return err
+	}
+	var body struct {
+		CurrentVersion int `json:"currentVersion"`
+	}
+	json.Unmarshal([]byte(apiErr.Body), &body)
+	return &VersionConflictError{WorkflowID: workflowID, ExpectedVersion: *expectedVersion, CurrentVersion: body.CurrentVersion}
+}
+
 // Client represents the main Zeal SDK client
 type Client struct {
 	config     ClientConfig
 	httpClient *http.Client
+	transport    Transport
 	orchestrator *OrchestratorAPI
 	templates    *TemplatesAPI
 	traces       *TracesAPI
 	webhooks     *WebhooksAPI
+	stats        *requestStats
+	moduleStats  map[string]*requestStats
 }
 
 // NewClient creates a new Zeal client with the given configuration
@@ -42,12 +128,21 @@ func NewClient(config ClientConfig) (*Client, error) {
 	client := &Client{
 		config:     config,
 		httpClient: httpClient,
+		stats:      newRequestStats(),
+		moduleStats: map[string]*requestStats{
+			"orchestrator": newRequestStats(),
+			"templates":    newRequestStats(),
+			"traces":       newRequestStats(),
+			"webhooks":     newRequestStats(),
+		},
 	}
 
+	client.transport = &HTTPTransport{config: &client.config, httpClient: httpClient}
+
 	// Initialize API modules
 	client.orchestrator = &OrchestratorAPI{client: client}
 	client.templates = &TemplatesAPI{client: client}
-	client.traces = &TracesAPI{client: client}
+	client.traces = &TracesAPI{client: client, MaxPreviewBytes: defaultMaxPreviewBytes}
 	client.webhooks = &WebhooksAPI{client: client}
 
 	return client, nil
@@ -85,6 +180,19 @@ func (c *Client) Health(ctx context.Context) (*HealthCheckResponse, error) {
 	return &health, nil
 }
 
+// WithTokenProvider sets the provider used to authenticate requests,
+// taking precedence over ClientConfig.AuthToken
+func (c *Client) WithTokenProvider(provider TokenProvider) *Client {
+	c.config.TokenProvider = provider
+	return c
+}
+
+// WithTransport replaces the transport used for API calls (REST by default)
+func (c *Client) WithTransport(transport Transport) *Client {
+	c.transport = transport
+	return c
+}
+
 // BaseURL returns the configured base URL
 func (c *Client) BaseURL() string {
 	return c.config.BaseURL
@@ -115,73 +223,26 @@ func (c *Client) Webhooks() *WebhooksAPI {
 	return c.webhooks
 }
 
-// makeRequest is a helper method for making HTTP requests
-func (c *Client) makeRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
-	url := strings.TrimSuffix(c.config.BaseURL, "/") + path
-	
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewBuffer(jsonData)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", c.config.UserAgent)
-	
-	// Add auth token if provided
-	if c.config.AuthToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.config.AuthToken)
-	}
-
-	// Execute request with retries
-	var resp *http.Response
-	var lastErr error
-	
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// Wait before retry
-			time.Sleep(time.Duration(c.config.RetryBackoffMs) * time.Millisecond)
-		}
-
-		resp, lastErr = c.httpClient.Do(req)
-		if lastErr == nil && resp.StatusCode < 500 {
-			// Success or client error (don't retry client errors)
-			break
-		}
-		
-		if resp != nil {
-			resp.Body.Close()
-		}
-	}
-
-	if lastErr != nil {
-		return fmt.Errorf("request failed after %d retries: %w", c.config.MaxRetries, lastErr)
+// ListenAndServeWebhooks starts a webhook subscription that publishes events
+// to handler, blocks until ctx is cancelled, then stops it. It returns the
+// error from Start or Stop.
+func (c *Client) ListenAndServeWebhooks(ctx context.Context, handler EventBus, opts *SubscriptionOptions) error {
+	subscription := NewWebhookSubscription(c.webhooks, opts).WithEventBus(handler)
+	if err := subscription.Start(); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
+	<-ctx.Done()
+	return subscription.Stop()
+}
 
-	// Check for HTTP errors
-	if resp.StatusCode >= 400 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
-	}
+// makeRequest sends a request through the client's transport
+func (c *Client) makeRequest(ctx context.Context, method, path string, body interface{}, result interface{}, opts ...RequestOption) (err error) {
+	start := time.Now()
+	defer func() {
+		c.recordRequest(path, time.Since(start), err)
+	}()
 
-	// Decode response if result is provided
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
-
-	return nil
+	return c.transport.Do(withRequestOptions(ctx, opts), method, path, body, result)
 }
 
 // OrchestratorAPI handles workflow orchestration
@@ -196,8 +257,109 @@ func (api *OrchestratorAPI) CreateWorkflow(ctx context.Context, req CreateWorkfl
 	return &result, err
 }
 
+// UpdateWorkflowMetadata updates a workflow's name, description or metadata without touching its graph
+func (api *OrchestratorAPI) UpdateWorkflowMetadata(ctx context.Context, workflowID string, req UpdateWorkflowMetadataRequest) (*UpdateWorkflowMetadataResponse, error) {
+	path := fmt.Sprintf("/api/zip/orchestrator/workflows/%s", workflowID)
+	var result UpdateWorkflowMetadataResponse
+	err := api.client.makeRequest(ctx, "PATCH", path, req, &result)
+	return &result, err
+}
+
 // ListWorkflows lists existing workflows
 func (api *OrchestratorAPI) ListWorkflows(ctx context.Context, params *ListWorkflowsParams) (*ListWorkflowsResponse, error) {
+	var result ListWorkflowsResponse
+	err := api.client.makeRequest(ctx, "GET", listWorkflowsPath(params), nil, &result)
+	return &result, err
+}
+
+// ErrUnsupportedSearchFilter is returned by SearchWorkflows for filters the
+// list fallback cannot evaluate
+var ErrUnsupportedSearchFilter = errors.New("search filter not supported without the search endpoint")
+
+// ErrInvalidSearchPagination is returned by SearchWorkflows for a negative
+// Offset or Limit
+var ErrInvalidSearchPagination = errors.New("search offset and limit must not be negative")
+
+// searchFallbackPageSize is the page size SearchWorkflows lists with when
+// falling back to ListWorkflows
+const searchFallbackPageSize = 100
+
+// SearchWorkflows searches workflows on the server. Against servers without
+// the search endpoint (404) it pages through ListWorkflows and filters
+// client-side. The list response does not report whether a workflow is
+// active, so the fallback returns ErrUnsupportedSearchFilter when IsActive
+// is set. A negative Offset or Limit is rejected with
+// ErrInvalidSearchPagination before any request is sent.
+func (api *OrchestratorAPI) SearchWorkflows(ctx context.Context, query *WorkflowSearchQuery) (*ListWorkflowsResponse, error) {
+	if query == nil {
+		query = &WorkflowSearchQuery{}
+	}
+	if query.Offset < 0 || query.Limit < 0 {
+		return nil, fmt.Errorf("%w: offset %d, limit %d", ErrInvalidSearchPagination, query.Offset, query.Limit)
+	}
+
+	var result ListWorkflowsResponse
+	err := api.client.makeRequest(ctx, "POST", "/api/zip/orchestrator/workflows/search", query, &result)
+	if !errors.Is(err, ErrNotFound) {
+		return &result, err
+	}
+
+	if query.IsActive != nil {
+		return nil, fmt.Errorf("%w: isActive", ErrUnsupportedSearchFilter)
+	}
+
+	matched := make([]WorkflowSummary, 0)
+	limit := searchFallbackPageSize
+	for offset := 0; ; {
+		page, err := api.ListWorkflows(ctx, &ListWorkflowsParams{Limit: &limit, Offset: &offset})
+		if err != nil {
+			return nil, err
+		}
+		for i := range page.Workflows {
+			if query.Matches(&page.Workflows[i]) {
+				matched = append(matched, page.Workflows[i])
+			}
+		}
+		offset += len(page.Workflows)
+		if len(page.Workflows) == 0 || offset >= page.Total {
+			break
+		}
+	}
+
+	result = ListWorkflowsResponse{Total: len(matched), Limit: query.Limit, Offset: query.Offset}
+	if query.Offset < len(matched) {
+		matched = matched[query.Offset:]
+		if query.Limit > 0 && query.Limit < len(matched) {
+			matched = matched[:query.Limit]
+		}
+		result.Workflows = matched
+	} else {
+		result.Workflows = []WorkflowSummary{}
+	}
+	return &result, nil
+}
+
+// StreamListWorkflows lists workflows like ListWorkflows, but decodes the
+// response incrementally and calls fn for each workflow instead of holding
+// the whole list in memory. Streaming stops at the first error from fn.
+func (api *OrchestratorAPI) StreamListWorkflows(ctx context.Context, params *ListWorkflowsParams, fn func(workflow interface{}) error) (err error) {
+	transport, ok := api.client.transport.(StreamingTransport)
+	if !ok {
+		return fmt.Errorf("transport does not support streaming responses")
+	}
+
+	path := listWorkflowsPath(params)
+	start := time.Now()
+	defer func() {
+		api.client.recordRequest(path, time.Since(start), err)
+	}()
+
+	return transport.Stream(ctx, "GET", path, nil, func(body io.Reader) error {
+		return streamJSONArray(body, "workflows", fn)
+	})
+}
+
+func listWorkflowsPath(params *ListWorkflowsParams) string {
 	path := "/api/zip/orchestrator/workflows"
 	if params != nil {
 		query := make([]string, 0, 2)
@@ -211,18 +373,73 @@ func (api *OrchestratorAPI) ListWorkflows(ctx context.Context, params *ListWorkf
 			path += "?" + strings.Join(query, "&")
 		}
 	}
-	
-	var result ListWorkflowsResponse
-	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
-	return &result, err
+	return path
+}
+
+// streamJSONArray decodes the array under key in a JSON object one element at
+// a time, skipping the object's other fields
+func streamJSONArray(r io.Reader, key string, fn func(interface{}) error) error {
+	decoder := json.NewDecoder(r)
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		if name, _ := token.(string); name != key {
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			continue
+		}
+
+		token, err = decoder.Token()
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+		if token == nil {
+			continue
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("failed to decode %s: expected array, got %v", key, token)
+		}
+		for decoder.More() {
+			var element interface{}
+			if err := decoder.Decode(&element); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", key, err)
+			}
+			if err := fn(element); err != nil {
+				return err
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// GetWorkflowTemplate returns the template snapshot a workflow was created
+// from, or ErrNoTemplateSource if it was not created from a template
+func (api *OrchestratorAPI) GetWorkflowTemplate(ctx context.Context, workflowID string) (*NodeTemplate, error) {
+	path := fmt.Sprintf("/api/zip/orchestrator/workflows/%s/template", workflowID)
+	var result NodeTemplate
+	if err := api.client.makeRequest(ctx, "GET", path, nil, &result); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNoTemplateSource
+		}
+		return nil, err
+	}
+	return &result, nil
 }
 
 // GetWorkflowState gets the current state of a workflow
 func (api *OrchestratorAPI) GetWorkflowState(ctx context.Context, workflowID string, graphID *string) (*WorkflowState, error) {
-	gid := "main"
-	if graphID != nil {
-		gid = *graphID
-	}
+	gid := resolveGraphID(graphID)
 	
 	path := fmt.Sprintf("/api/zip/orchestrator/workflows/%s/state?graphId=%s", workflowID, gid)
 	var result WorkflowState
@@ -230,11 +447,97 @@ func (api *OrchestratorAPI) GetWorkflowState(ctx context.Context, workflowID str
 	return &result, err
 }
 
+// GetExecutionHistory lists past executions of a workflow
+func (api *OrchestratorAPI) GetExecutionHistory(ctx context.Context, workflowID string, opts *ExecutionHistoryOptions) (*ExecutionHistoryResponse, error) {
+	path := fmt.Sprintf("/api/zip/orchestrator/workflows/%s/executions", workflowID)
+	if opts != nil {
+		params := url.Values{}
+		if opts.Limit > 0 {
+			params.Set("limit", fmt.Sprintf("%d", opts.Limit))
+		}
+		if opts.Offset > 0 {
+			params.Set("offset", fmt.Sprintf("%d", opts.Offset))
+		}
+		if opts.Status != nil {
+			params.Set("status", *opts.Status)
+		}
+		if opts.From != nil {
+			params.Set("from", fmt.Sprintf("%d", opts.From.UnixMilli()))
+		}
+		if opts.To != nil {
+			params.Set("to", fmt.Sprintf("%d", opts.To.UnixMilli()))
+		}
+		if opts.SortDesc {
+			params.Set("sort", "desc")
+		}
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
+	}
+
+	var result ExecutionHistoryResponse
+	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
+	return &result, err
+}
+
+// GetNodeExecutionHistory lists past executions that ran through a node.
+// For a record's full trace, pass its SessionID and the node ID to
+// TracesAPI.GetSessionEvents, or set IncludeTraceData to embed the events.
+func (api *OrchestratorAPI) GetNodeExecutionHistory(ctx context.Context, nodeID, workflowID string, opts *NodeHistoryOptions) (*NodeHistoryResponse, error) {
+	params := url.Values{}
+	params.Set("workflowId", workflowID)
+	if opts != nil {
+		if opts.Limit > 0 {
+			params.Set("limit", fmt.Sprintf("%d", opts.Limit))
+		}
+		if opts.Offset > 0 {
+			params.Set("offset", fmt.Sprintf("%d", opts.Offset))
+		}
+		if opts.Status != nil {
+			params.Set("status", *opts.Status)
+		}
+		if opts.From != nil {
+			params.Set("from", fmt.Sprintf("%d", opts.From.UnixMilli()))
+		}
+		if opts.To != nil {
+			params.Set("to", fmt.Sprintf("%d", opts.To.UnixMilli()))
+		}
+		if opts.IncludeTraceData {
+			params.Set("includeTraceData", "true")
+		}
+	}
+
+	path := fmt.Sprintf("/api/zip/orchestrator/nodes/%s/executions?%s", nodeID, params.Encode())
+	var result NodeHistoryResponse
+	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
+	return &result, err
+}
+
 // AddNode adds a node to a workflow
 func (api *OrchestratorAPI) AddNode(ctx context.Context, req AddNodeRequest) (*AddNodeResponse, error) {
 	var result AddNodeResponse
 	err := api.client.makeRequest(ctx, "POST", "/api/zip/orchestrator/nodes", req, &result)
-	return &result, err
+	return &result, versionConflict(err, req.WorkflowID, req.ExpectedVersion)
+}
+
+// GetNode gets a single node by ID. The server has no single-node endpoint,
+// so the node is looked up in the workflow state. Returns an error matching
+// ErrNotFound if the graph has no such node.
+func (api *OrchestratorAPI) GetNode(ctx context.Context, nodeID, workflowID string, graphID *string) (*NodeDetail, error) {
+	state, err := api.GetWorkflowState(ctx, workflowID, graphID)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := decodeWorkflowGraph(state)
+	if err != nil {
+		return nil, err
+	}
+	for i := range graph.Nodes {
+		if graph.Nodes[i].ID == nodeID {
+			return &graph.Nodes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("node %s in workflow %s: %w", nodeID, workflowID, ErrNotFound)
 }
 
 // UpdateNode updates node properties
@@ -245,12 +548,39 @@ func (api *OrchestratorAPI) UpdateNode(ctx context.Context, nodeID string, req U
 	return &result, err
 }
 
+// MoveNode updates only the position of a node
+func (api *OrchestratorAPI) MoveNode(ctx context.Context, nodeID, workflowID string, pos Position, graphID *string) (*UpdateNodeResponse, error) {
+	return api.UpdateNode(ctx, nodeID, UpdateNodeRequest{WorkflowID: workflowID, GraphID: graphID, Position: &pos})
+}
+
+// SetNodeProperty updates a single property of a node. The server merges it
+// into the node's existing properties.
+func (api *OrchestratorAPI) SetNodeProperty(ctx context.Context, nodeID, workflowID, key string, value interface{}, graphID *string) (*UpdateNodeResponse, error) {
+	return api.UpdateNode(ctx, nodeID, UpdateNodeRequest{
+		WorkflowID: workflowID,
+		GraphID:    graphID,
+		Properties: map[string]interface{}{key: value},
+	})
+}
+
+// maxNodeNameLength is the longest instance name RenameNode accepts
+const maxNodeNameLength = 255
+
+// ErrInvalidNodeName is returned by RenameNode for empty names and names
+// longer than 255 characters
+var ErrInvalidNodeName = errors.New("node name must be 1 to 255 characters")
+
+// RenameNode updates only the instance name of a node
+func (api *OrchestratorAPI) RenameNode(ctx context.Context, nodeID, workflowID, newName string, graphID *string) (*UpdateNodeResponse, error) {
+	if newName == "" || utf8.RuneCountInString(newName) > maxNodeNameLength {
+		return nil, fmt.Errorf("%w: got %d characters", ErrInvalidNodeName, utf8.RuneCountInString(newName))
+	}
+	return api.UpdateNode(ctx, nodeID, UpdateNodeRequest{WorkflowID: workflowID, GraphID: graphID, InstanceName: &newName})
+}
+
 // DeleteNode deletes a node
 func (api *OrchestratorAPI) DeleteNode(ctx context.Context, nodeID, workflowID string, graphID *string) (*DeleteNodeResponse, error) {
-	gid := "main"
-	if graphID != nil {
-		gid = *graphID
-	}
+	gid := resolveGraphID(graphID)
 	
 	path := fmt.Sprintf("/api/zip/orchestrator/nodes/%s?workflowId=%s&graphId=%s", nodeID, workflowID, gid)
 	var result DeleteNodeResponse
@@ -262,7 +592,64 @@ func (api *OrchestratorAPI) DeleteNode(ctx context.Context, nodeID, workflowID s
 func (api *OrchestratorAPI) ConnectNodes(ctx context.Context, req ConnectNodesRequest) (*ConnectionResponse, error) {
 	var result ConnectionResponse
 	err := api.client.makeRequest(ctx, "POST", "/api/zip/orchestrator/connections", req, &result)
-	return &result, err
+	return &result, versionConflict(err, req.WorkflowID, req.ExpectedVersion)
+}
+
+// BatchConnectNodes creates several connections in one request. Against
+// servers without the batch endpoint (404) it falls back to one ConnectNodes
+// call per request, attempting every connection and reporting failures in
+// the results.
+func (api *OrchestratorAPI) BatchConnectNodes(ctx context.Context, reqs []ConnectNodesRequest) (*BulkConnectionResponse, error) {
+	var result BulkConnectionResponse
+	body := map[string]interface{}{"connections": reqs}
+	err := api.client.makeRequest(ctx, "POST", "/api/zip/orchestrator/connections/batch", body, &result)
+	if !errors.Is(err, ErrNotFound) {
+		return &result, err
+	}
+
+	result = BulkConnectionResponse{Results: make([]BulkConnectionResult, 0, len(reqs))}
+	for _, req := range reqs {
+		connection, err := api.ConnectNodes(ctx, req)
+		if err != nil {
+			message := err.Error()
+			result.Results = append(result.Results, BulkConnectionResult{Error: &message})
+			continue
+		}
+		result.Results = append(result.Results, BulkConnectionResult{ConnectionID: connection.ConnectionID, Success: true})
+	}
+	return &result, nil
+}
+
+// ConnectNodesValidated connects two nodes after validating the connection
+// against the ports' multiplicity and data types and the workflow's existing
+// connections
+func (api *OrchestratorAPI) ConnectNodesValidated(ctx context.Context, req ConnectNodesRequest, sourcePort, targetPort Port) (*ConnectionResponse, error) {
+	state, err := api.GetWorkflowState(ctx, req.WorkflowID, req.GraphID)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := decodeWorkflowGraph(state)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Keep only the endpoint on the node being connected, so a port sharing
+	// an ID on some other node does not count against capacity
+	existing := make([]ConnectionDetail, 0)
+	for _, conn := range graph.Connections {
+		if conn.Source.NodeID == req.Source.NodeID {
+			existing = append(existing, ConnectionDetail{ID: conn.ID, Source: conn.Source})
+		}
+		if conn.Target.NodeID == req.Target.NodeID {
+			existing = append(existing, ConnectionDetail{ID: conn.ID, Target: conn.Target})
+		}
+	}
+	
+	if err := ValidateConnection(sourcePort, targetPort, existing); err != nil {
+		return nil, err
+	}
+	
+	return api.ConnectNodes(ctx, req)
 }
 
 // RemoveConnection removes a connection between nodes
@@ -293,26 +680,153 @@ func (api *OrchestratorAPI) RemoveGroup(ctx context.Context, req RemoveGroupRequ
 	return &result, err
 }
 
+// ListGroups lists the groups in a workflow graph
+func (api *OrchestratorAPI) ListGroups(ctx context.Context, workflowID string, graphID *string) (*ListGroupsResponse, error) {
+	gid := resolveGraphID(graphID)
+	
+	path := fmt.Sprintf("/api/zip/orchestrator/groups?workflowId=%s&graphId=%s", workflowID, gid)
+	var result ListGroupsResponse
+	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
+	return &result, err
+}
+
+// GetGroup gets a single group by ID
+func (api *OrchestratorAPI) GetGroup(ctx context.Context, groupID, workflowID string) (*GroupDetail, error) {
+	path := fmt.Sprintf("/api/zip/orchestrator/groups/%s?workflowId=%s", groupID, workflowID)
+	var result GroupDetail
+	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
+	return &result, err
+}
+
+// GetGroupsForNode returns the groups that contain the given node
+func (api *OrchestratorAPI) GetGroupsForNode(ctx context.Context, nodeID, workflowID string, graphID *string) ([]GroupDetail, error) {
+	list, err := api.ListGroups(ctx, workflowID, graphID)
+	if err != nil {
+		return nil, err
+	}
+	
+	groups := make([]GroupDetail, 0)
+	for _, group := range list.Groups {
+		if group.ContainsNode(nodeID) {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
 // TemplatesAPI handles node template management
 type TemplatesAPI struct {
 	client *Client
 }
 
-// Register registers node templates
+// Register registers node templates. Large payloads are sent gzip-compressed.
 func (api *TemplatesAPI) Register(ctx context.Context, req RegisterTemplatesRequest) (*RegisterTemplatesResponse, error) {
 	var result RegisterTemplatesResponse
-	err := api.client.makeRequest(ctx, "POST", "/api/zip/templates/register", req, &result)
+	err := api.client.makeRequest(ctx, "POST", "/api/zip/templates/register", req, &result, CompressRequest())
 	return &result, err
 }
 
+// ExistsTemplate reports whether a template is registered in namespace. A
+// missing template is not an error.
+func (api *TemplatesAPI) ExistsTemplate(ctx context.Context, namespace, templateID string) (bool, error) {
+	existing, err := api.listTemplateIDs(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+	return existing[templateID], nil
+}
+
+// RegisterIfNotExists registers only the templates not already present in
+// namespace, so startup registration does not redo server-side work. The
+// namespace is listed once to find the missing templates. If all templates
+// exist nothing is sent and an empty successful response is returned.
+func (api *TemplatesAPI) RegisterIfNotExists(ctx context.Context, namespace string, templates []NodeTemplate) (*RegisterTemplatesResponse, error) {
+	existing, err := api.listTemplateIDs(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	var missing []NodeTemplate
+	for _, template := range templates {
+		if !existing[template.ID] {
+			missing = append(missing, template)
+		}
+	}
+	if len(missing) == 0 {
+		return &RegisterTemplatesResponse{Success: true, RegisteredIDs: []string{}, UpdatedIDs: []string{}}, nil
+	}
+	return api.Register(ctx, RegisterTemplatesRequest{Namespace: namespace, Templates: missing})
+}
+
+// listTemplateIDs returns the set of template IDs registered in namespace
+func (api *TemplatesAPI) listTemplateIDs(ctx context.Context, namespace string) (map[string]bool, error) {
+	list, err := api.List(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(list.Templates))
+	for _, template := range list.Templates {
+		ids[template.ID] = true
+	}
+	return ids, nil
+}
+
 // List lists available templates in a namespace
 func (api *TemplatesAPI) List(ctx context.Context, namespace string) (*ListTemplatesResponse, error) {
-	path := fmt.Sprintf("/api/zip/templates/list?namespace=%s", namespace)
+	path := fmt.Sprintf("/api/zip/templates/%s", url.PathEscape(namespace))
 	var result ListTemplatesResponse
 	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
 	return &result, err
 }
 
+// SearchTemplates searches templates in a namespace using the given query.
+// Falls back to List with client-side filtering when the server does not
+// expose the search endpoint.
+func (api *TemplatesAPI) SearchTemplates(ctx context.Context, namespace string, query *TemplateSearchQuery) (*ListTemplatesResponse, error) {
+	if query == nil {
+		query = &TemplateSearchQuery{}
+	}
+
+	params := url.Values{}
+	params.Set("namespace", namespace)
+	if query.CategoryFilter != "" {
+		params.Set("category", query.CategoryFilter)
+	}
+	if query.SubcategoryFilter != nil {
+		params.Set("subcategory", *query.SubcategoryFilter)
+	}
+	if query.TextSearch != "" {
+		params.Set("q", query.TextSearch)
+	}
+	if query.HasGPU != nil {
+		params.Set("gpu", fmt.Sprintf("%t", *query.HasGPU))
+	}
+	for _, tag := range query.Tags {
+		params.Add("tag", tag)
+	}
+	if query.MaxResults > 0 {
+		params.Set("limit", fmt.Sprintf("%d", query.MaxResults))
+	}
+
+	var result ListTemplatesResponse
+	err := api.client.makeRequest(ctx, "GET", "/api/zip/templates/search?"+params.Encode(), nil, &result)
+	if err == nil {
+		return &result, nil
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		return &result, err
+	}
+
+	// Search endpoint not available, filter the full listing locally
+	all, err := api.List(ctx, namespace)
+	if err != nil {
+		return all, err
+	}
+	filtered := FilterTemplates(all.Templates, query)
+	return &ListTemplatesResponse{Templates: filtered, Total: len(filtered)}, nil
+}
+
 // Update updates a template
 func (api *TemplatesAPI) Update(ctx context.Context, namespace, templateID string, template NodeTemplate) (*UpdateTemplateResponse, error) {
 	path := fmt.Sprintf("/api/zip/templates/update?namespace=%s&templateId=%s", namespace, templateID)
@@ -352,10 +866,39 @@ func (api *TemplatesAPI) Delete(ctx context.Context, namespace, templateID strin
 	return &result, err
 }
 
+// GetTemplateUsage lists the workflows that use a template
+func (api *TemplatesAPI) GetTemplateUsage(ctx context.Context, namespace, templateID string) (*TemplateUsageResponse, error) {
+	path := fmt.Sprintf("/api/zip/templates/usage?namespace=%s&templateId=%s", namespace, templateID)
+	var result TemplateUsageResponse
+	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
+	return &result, err
+}
+
+// SafeDelete deletes a template only if no workflow uses it, returning
+// ErrTemplateInUse otherwise. force skips the usage check.
+func (api *TemplatesAPI) SafeDelete(ctx context.Context, namespace, templateID string, force bool) (*DeleteTemplateResponse, error) {
+	if !force {
+		usage, err := api.GetTemplateUsage(ctx, namespace, templateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check template usage: %w", err)
+		}
+		if usage.TotalWorkflows > 0 {
+			return nil, fmt.Errorf("%w: %s is used by %d workflows", ErrTemplateInUse, templateID, usage.TotalWorkflows)
+		}
+	}
+	return api.Delete(ctx, namespace, templateID)
+}
+
 // TracesAPI handles execution tracing
 type TracesAPI struct {
 	client    *Client
 	sessionID *string
+	// MaxPreviewBytes limits the JSON size of previews sent by TraceNodeExecution; 0 disables the limit
+	MaxPreviewBytes int
+
+	sampler         TraceSampler
+	batchSubmitters map[string][]*BatchTraceSubmitter
+	batchMu         sync.Mutex
 }
 
 // CreateSession creates a new trace session
@@ -380,13 +923,69 @@ func (api *TracesAPI) SubmitEvents(ctx context.Context, sessionID string, events
 	return &result, err
 }
 
-// SubmitEvent submits a single trace event
+// SubmitEvent submits a single trace event, unless the configured sampler drops it
 func (api *TracesAPI) SubmitEvent(ctx context.Context, sessionID string, event TraceEvent) (*SubmitEventsResponse, error) {
-	return api.SubmitEvents(ctx, sessionID, []TraceEvent{event})
+	if !api.sampled(event) {
+		return &SubmitEventsResponse{Success: true}, nil
+	}
+
+	pooled := traceEventPool.Get()
+	defer traceEventPool.Put(pooled)
+	*pooled = event
+
+	return api.SubmitEvents(ctx, sessionID, []TraceEvent{*pooled})
+}
+
+// GetSessionEvents retrieves stored events of a trace session
+func (api *TracesAPI) GetSessionEvents(ctx context.Context, sessionID string, opts *TraceQueryOptions) (*TraceEventsResponse, error) {
+	path := fmt.Sprintf("/api/zip/traces/%s/events", sessionID)
+	if opts != nil {
+		params := url.Values{}
+		if opts.NodeID != nil {
+			params.Set("nodeId", *opts.NodeID)
+		}
+		if opts.EventType != nil {
+			params.Set("eventType", *opts.EventType)
+		}
+		if opts.From != nil {
+			params.Set("from", fmt.Sprintf("%d", opts.From.UnixMilli()))
+		}
+		if opts.To != nil {
+			params.Set("to", fmt.Sprintf("%d", opts.To.UnixMilli()))
+		}
+		if opts.Limit != nil {
+			params.Set("limit", fmt.Sprintf("%d", *opts.Limit))
+		}
+		if opts.Offset != nil {
+			params.Set("offset", fmt.Sprintf("%d", *opts.Offset))
+		}
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
+	}
+
+	var result TraceEventsResponse
+	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
+	return &result, err
 }
 
 // CompleteSession completes a trace session
 func (api *TracesAPI) CompleteSession(ctx context.Context, sessionID string, req CompleteSessionRequest) (*CompleteSessionResponse, error) {
+	if !req.Status.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSessionStatus, req.Status)
+	}
+
+	// The server has no timeout status, so timed out sessions are sent as
+	// failed with an error describing the timeout
+	if req.Status == SessionStatusTimedOut {
+		req.Status = SessionStatusFailed
+		if req.Error == nil {
+			req.Error = &SessionError{Message: "session timed out"}
+		}
+	}
+
+	api.closeBatchSubmitters(ctx, sessionID)
+
 	path := fmt.Sprintf("/api/zip/traces/%s/complete", sessionID)
 	var result CompleteSessionResponse
 	err := api.client.makeRequest(ctx, "POST", path, req, &result)
@@ -396,6 +995,16 @@ func (api *TracesAPI) CompleteSession(ctx context.Context, sessionID string, req
 	return &result, err
 }
 
+// CompleteSessionWithAutoSummary completes a trace session with a summary
+// computed from the given events
+func (api *TracesAPI) CompleteSessionWithAutoSummary(ctx context.Context, sessionID string, status SessionStatus, events []TraceEvent) (*CompleteSessionResponse, error) {
+	summary := ComputeSessionSummary(events)
+	return api.CompleteSession(ctx, sessionID, CompleteSessionRequest{
+		Status:  status,
+		Summary: &summary,
+	})
+}
+
 // CurrentSessionID returns the current session ID
 func (api *TracesAPI) CurrentSessionID() *string {
 	return api.sessionID
@@ -410,10 +1019,11 @@ func (api *TracesAPI) TraceNodeExecution(ctx context.Context, sessionID, nodeID,
 
 	traceData := TraceData{
 		Size:     len(dataJSON),
-		DataType: "application/json",
+		DataType: InferTraceDataType(data).MIMEType(),
 		Preview:  data,
 		FullData: data,
 	}
+	traceData = TruncateTraceData(traceData, api.MaxPreviewBytes)
 
 	event := TraceEvent{
 		Timestamp: time.Now().UnixMilli(),
@@ -422,10 +1032,20 @@ func (api *TracesAPI) TraceNodeExecution(ctx context.Context, sessionID, nodeID,
 		Data:      traceData,
 	}
 
+	var durationMs int64
 	if duration != nil {
-		durationMs := duration.Milliseconds()
+		durationMs = duration.Milliseconds()
 		event.Duration = &durationMs
 	}
+	// The server keeps only cpuUsage, memoryUsage and custom metadata
+	event.Metadata = map[string]interface{}{
+		"custom": map[string]interface{}{
+			"metrics": map[string]float64{
+				"duration_ms":     float64(durationMs),
+				"data_size_bytes": float64(traceData.Size),
+			},
+		},
+	}
 
 	_, err = api.SubmitEvent(ctx, sessionID, event)
 	return err
@@ -436,10 +1056,10 @@ type WebhooksAPI struct {
 	client *Client
 }
 
-// Create creates a new webhook subscription
+// Create creates a new webhook subscription, filling in the default retry policy
 func (api *WebhooksAPI) Create(ctx context.Context, req CreateWebhookRequest) (*CreateWebhookResponse, error) {
 	var result CreateWebhookResponse
-	err := api.client.makeRequest(ctx, "POST", "/api/zip/webhooks", req, &result)
+	err := api.client.makeRequest(ctx, "POST", "/api/zip/webhooks", req.WithDefaults(), &result)
 	return &result, err
 }
 
@@ -450,6 +1070,51 @@ func (api *WebhooksAPI) List(ctx context.Context) (*ListWebhooksResponse, error)
 	return &result, err
 }
 
+// ListByNamespace lists the webhook subscriptions in namespace
+func (api *WebhooksAPI) ListByNamespace(ctx context.Context, namespace string) (*ListWebhooksResponse, error) {
+	path := "/api/zip/webhooks?namespace=" + url.QueryEscape(namespace)
+	var result ListWebhooksResponse
+	err := api.client.makeRequest(ctx, "GET", path, nil, &result)
+	return &result, err
+}
+
+// GetByURL returns the webhook subscription registered for url, or
+// ErrNotFound when there is none
+func (api *WebhooksAPI) GetByURL(ctx context.Context, url string) (*WebhookSubscription, error) {
+	list, err := api.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if sub, ok := list.FindByURL(url); ok {
+		return sub, nil
+	}
+	return nil, fmt.Errorf("%w: webhook for %s", ErrNotFound, url)
+}
+
+// RenewExpiring reactivates every subscription that expires within threshold
+// and returns the renewed subscriptions
+func (api *WebhooksAPI) RenewExpiring(ctx context.Context, threshold time.Duration) ([]WebhookSubscription, error) {
+	list, err := api.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active := true
+	var renewed []WebhookSubscription
+	for i := range list.Subscriptions {
+		if !list.Subscriptions[i].IsExpiring(threshold) {
+			continue
+		}
+		id := list.Subscriptions[i].ID
+		resp, err := api.Update(ctx, id, UpdateWebhookRequest{IsActive: &active})
+		if err != nil {
+			return renewed, fmt.Errorf("failed to renew webhook %s: %w", id, err)
+		}
+		renewed = append(renewed, resp.Subscription)
+	}
+	return renewed, nil
+}
+
 // Update updates a webhook subscription
 func (api *WebhooksAPI) Update(ctx context.Context, webhookID string, req UpdateWebhookRequest) (*UpdateWebhookResponse, error) {
 	path := fmt.Sprintf("/api/zip/webhooks/%s", webhookID)
@@ -470,6 +1135,50 @@ func (api *WebhooksAPI) Delete(ctx context.Context, webhookID string) (*DeleteWe
 func (api *WebhooksAPI) Test(ctx context.Context, webhookID string) (*TestWebhookResponse, error) {
 	path := fmt.Sprintf("/api/zip/webhooks/%s/test", webhookID)
 	var result TestWebhookResponse
-	err := api.client.makeRequest(ctx, "POST", path, nil, &result)
-	return &result, err
-}
\ No newline at end of file
+	if err := api.client.makeRequest(ctx, "POST", path, nil, &result); err != nil {
+		return &result, err
+	}
+
+	if result.Error == nil && result.StatusCode >= 400 {
+		statusCode := result.StatusCode
+		result.Error = &WebhookTestError{
+			Type:       WebhookTestErrorHTTPError,
+			Message:    fmt.Sprintf("endpoint responded with HTTP %d", statusCode),
+			StatusCode: &statusCode,
+		}
+	}
+	if result.Error != nil && result.Error.LatencyMs == nil && result.ResponseTimeMs > 0 {
+		latency := result.ResponseTimeMs
+		result.Error.LatencyMs = &latency
+	}
+	return &result, nil
+}
+
+// TestWithRetry sends attempts test deliveries separated by interval and
+// returns every response. It stops early with the responses collected so far
+// if a request fails or ctx is done.
+func (api *WebhooksAPI) TestWithRetry(ctx context.Context, webhookID string, attempts int, interval time.Duration) ([]*TestWebhookResponse, error) {
+	if attempts <= 0 {
+		return nil, fmt.Errorf("attempts must be positive, got %d", attempts)
+	}
+
+	results := make([]*TestWebhookResponse, 0, attempts)
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return results, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		result, err := api.Test(ctx, webhookID)
+		if err != nil {
+			return results, fmt.Errorf("test delivery %d failed: %w", i+1, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
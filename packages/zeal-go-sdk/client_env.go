@@ -0,0 +1,116 @@
+package zeal
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by ClientConfigFromEnv
+const (
+	EnvBaseURL    = "ZEAL_BASE_URL"
+	EnvAuthToken  = "ZEAL_AUTH_TOKEN"
+	EnvTimeout    = "ZEAL_TIMEOUT" // a time.ParseDuration string, e.g. "30s"
+	EnvMaxRetries = "ZEAL_MAX_RETRIES"
+)
+
+// ClientOption modifies a ClientConfig
+type ClientOption func(*ClientConfig)
+
+// WithBaseURL sets ClientConfig.BaseURL
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *ClientConfig) { c.BaseURL = baseURL }
+}
+
+// WithAuthToken sets ClientConfig.AuthToken
+func WithAuthToken(token string) ClientOption {
+	return func(c *ClientConfig) { c.AuthToken = SecretString(token) }
+}
+
+// WithTimeout sets ClientConfig.DefaultTimeout
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *ClientConfig) { c.DefaultTimeout = timeout }
+}
+
+// WithUserAgent appends extra, such as an application name and version, to
+// the SDK and runtime info in ClientConfig.UserAgent
+func WithUserAgent(extra string) ClientOption {
+	return func(c *ClientConfig) { *c = c.WithUserAgent(extra) }
+}
+
+// ClientConfigFromEnv returns DefaultClientConfig overridden by the ZEAL_*
+// environment variables that are set
+func ClientConfigFromEnv() (ClientConfig, error) {
+	config := DefaultClientConfig()
+	if baseURL := os.Getenv(EnvBaseURL); baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	if token := os.Getenv(EnvAuthToken); token != "" {
+		config.AuthToken = SecretString(token)
+	}
+	if value := os.Getenv(EnvTimeout); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid %s: %w", EnvTimeout, err)
+		}
+		config.DefaultTimeout = timeout
+	}
+	if value := os.Getenv(EnvMaxRetries); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid %s: %w", EnvMaxRetries, err)
+		}
+		config.MaxRetries = retries
+	}
+	return config, nil
+}
+
+// ValidateClientConfig checks that config has an absolute http or https base
+// URL and non-negative timeout and retry settings
+func ValidateClientConfig(config ClientConfig) error {
+	if config.BaseURL == "" {
+		return fmt.Errorf("BaseURL cannot be empty")
+	}
+	u, err := url.Parse(config.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("BaseURL must be an absolute http or https URL: %q", config.BaseURL)
+	}
+	if config.DefaultTimeout < 0 {
+		return fmt.Errorf("DefaultTimeout cannot be negative")
+	}
+	if config.MaxRetries < 0 {
+		return fmt.Errorf("MaxRetries cannot be negative")
+	}
+	if config.RetryBackoffMs < 0 {
+		return fmt.Errorf("RetryBackoffMs cannot be negative")
+	}
+	return nil
+}
+
+// NewClientFromEnv creates a client from ClientConfigFromEnv with opts
+// applied, after validating the result with ValidateClientConfig
+func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
+	config, err := ClientConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if err := ValidateClientConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid client config: %w", err)
+	}
+	return NewClient(config)
+}
+
+// MustNewClientFromEnv is like NewClientFromEnv but panics on error, for
+// service startup where misconfiguration should be fatal
+func MustNewClientFromEnv(opts ...ClientOption) *Client {
+	client, err := NewClientFromEnv(opts...)
+	if err != nil {
+		panic(fmt.Sprintf("zeal: %v", err))
+	}
+	return client
+}
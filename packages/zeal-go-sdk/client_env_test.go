@@ -0,0 +1,75 @@
+package zeal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClientConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvBaseURL, "https://zeal.example.com")
+	t.Setenv(EnvAuthToken, "env-token")
+	t.Setenv(EnvTimeout, "5s")
+	t.Setenv(EnvMaxRetries, "1")
+
+	config, err := ClientConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ClientConfigFromEnv failed: %v", err)
+	}
+	if config.BaseURL != "https://zeal.example.com" || config.AuthToken.Reveal() != "env-token" ||
+		config.DefaultTimeout != 5*time.Second || config.MaxRetries != 1 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	if config.UserAgent != DefaultClientConfig().UserAgent {
+		t.Errorf("Expected unset fields to keep their defaults, got user agent %q", config.UserAgent)
+	}
+
+	t.Setenv(EnvTimeout, "soon")
+	if _, err := ClientConfigFromEnv(); err == nil {
+		t.Error("Expected an error for an invalid timeout")
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(EnvBaseURL, "https://zeal.example.com")
+
+	client, err := NewClientFromEnv(WithAuthToken("override"), WithTimeout(time.Second), WithUserAgent("billing-worker/2.3"))
+	if err != nil {
+		t.Fatalf("NewClientFromEnv failed: %v", err)
+	}
+	if client.config.BaseURL != "https://zeal.example.com" || client.config.AuthToken.Reveal() != "override" ||
+		client.config.DefaultTimeout != time.Second {
+		t.Errorf("Expected options applied over the environment, got %+v", client.config)
+	}
+	if want := DefaultClientConfig().WithUserAgent("billing-worker/2.3").UserAgent; client.config.UserAgent != want {
+		t.Errorf("Expected user agent %q, got %q", want, client.config.UserAgent)
+	}
+
+	if _, err := NewClientFromEnv(WithBaseURL("zeal.example.com")); err == nil {
+		t.Error("Expected an error for a base URL without a scheme")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected MustNewClientFromEnv to panic")
+		}
+	}()
+	MustNewClientFromEnv(WithBaseURL(""))
+}
+
+func TestValidateClientConfig(t *testing.T) {
+	if err := ValidateClientConfig(DefaultClientConfig()); err != nil {
+		t.Errorf("Expected the default config to be valid, got %v", err)
+	}
+
+	invalid := []ClientConfig{
+		{BaseURL: ""},
+		{BaseURL: "ftp://zeal.example.com"},
+		{BaseURL: "http://zeal.example.com", DefaultTimeout: -time.Second},
+		{BaseURL: "http://zeal.example.com", MaxRetries: -1},
+	}
+	for _, config := range invalid {
+		if err := ValidateClientConfig(config); err == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+}
@@ -1,6 +1,20 @@
 package zeal
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -58,8 +72,14 @@ func TestDefaultClientConfig(t *testing.T) {
 		t.Error("Expected VerifyTLS to be true")
 	}
 
-	if config.UserAgent != "zeal-go-sdk/1.0.0" {
-		t.Errorf("Expected UserAgent to be 'zeal-go-sdk/1.0.0', got '%s'", config.UserAgent)
+	expectedUserAgent := "zeal-go-sdk/1.0.0 Go/" + strings.TrimPrefix(runtime.Version(), "go") + " " + runtime.GOOS
+	if config.UserAgent != expectedUserAgent {
+		t.Errorf("Expected UserAgent to be '%s', got '%s'", expectedUserAgent, config.UserAgent)
+	}
+
+	config = config.WithUserAgent("billing-worker/2.3")
+	if config.UserAgent != expectedUserAgent+" billing-worker/2.3" {
+		t.Errorf("Expected extra info to be appended, got '%s'", config.UserAgent)
 	}
 }
 
@@ -94,7 +114,7 @@ func TestEventCreation(t *testing.T) {
 	}
 
 	// Test group created event
-	groupEvent := CreateGroupCreatedEvent(workflowID, data, nil)
+	groupEvent := CreateGroupCreatedEvent(workflowID, GroupEventData{GroupID: "group-1", Title: "Test Group"}, nil)
 	
 	if groupEvent.GetEventType() != "group.created" {
 		t.Errorf("Expected event type 'group.created', got '%s'", groupEvent.GetEventType())
@@ -157,4 +177,1156 @@ func TestEventTypeGuards(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestGetGroupsForNode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/zip/orchestrator/groups" || r.URL.Query().Get("workflowId") != "wf-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"groups":[
+			{"id":"g1","title":"Ingest","nodeIds":["n1","n2"]},
+			{"id":"g2","title":"Transform","nodeIds":["n3"]},
+			{"id":"g3","title":"All","nodeIds":["n1","n3"]}
+		],"total":3}`))
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	client, _ := NewClient(config)
+
+	groups, err := client.Orchestrator().GetGroupsForNode(context.Background(), "n1", "wf-1", nil)
+	if err != nil {
+		t.Fatalf("GetGroupsForNode failed: %v", err)
+	}
+	if len(groups) != 2 || groups[0].ID != "g1" || groups[1].ID != "g3" {
+		t.Errorf("Expected groups g1 and g3, got %+v", groups)
+	}
+}
+
+func TestTraceAndPresenceEventGuards(t *testing.T) {
+	if !IsTraceEvent("trace.event") || IsTraceEvent("node.completed") {
+		t.Error("IsTraceEvent returned wrong result")
+	}
+	if !IsConnectionStateEvent("connection.state") || IsConnectionStateEvent("connection.added") {
+		t.Error("IsConnectionStateEvent returned wrong result")
+	}
+	if !IsPresenceEvent("presence.joined") || IsPresenceEvent("node.added") {
+		t.Error("IsPresenceEvent returned wrong result")
+	}
+}
+
+func TestUpdateWorkflowMetadata(t *testing.T) {
+	var body UpdateWorkflowMetadataRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/api/zip/orchestrator/workflows/wf-1" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"success":true,"updatedAt":"2024-01-02T03:04:05Z"}`))
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	client, _ := NewClient(config)
+
+	name := "Renamed"
+	resp, err := client.Orchestrator().UpdateWorkflowMetadata(context.Background(), "wf-1", UpdateWorkflowMetadataRequest{Name: &name})
+	if err != nil {
+		t.Fatalf("UpdateWorkflowMetadata failed: %v", err)
+	}
+	if !resp.Success || resp.UpdatedAt.Year() != 2024 {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+	if body.Name == nil || *body.Name != "Renamed" || body.Description != nil {
+		t.Errorf("Unexpected request body %+v", body)
+	}
+
+	previous := "Original"
+	event := CreateWorkflowUpdatedEvent("wf-1", WorkflowUpdateData{Name: &name, PreviousName: &previous}, nil)
+	if event.Type != "workflow.updated" {
+		t.Errorf("Unexpected event type %s", event.Type)
+	}
+	data, err := event.DecodeData()
+	if err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	if *data.Name != "Renamed" || *data.PreviousName != "Original" || data.PreviousDescription != nil {
+		t.Errorf("Unexpected event data %+v", data)
+	}
+}
+
+func TestClientStatistics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/zip/webhooks" {
+			w.Write([]byte(`{"subscriptions":[],"total":0}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	client, _ := NewClient(config)
+
+	client.Webhooks().List(context.Background())
+	client.Webhooks().List(context.Background())
+	client.Orchestrator().ListWorkflows(context.Background(), nil)
+
+	stats := client.Statistics()
+	if stats.RequestsTotal != 3 || stats.RequestsSuccess != 2 || stats.RequestsFailure != 1 {
+		t.Errorf("Unexpected statistics: %+v", stats)
+	}
+	if webhooks := client.WebhooksStats(); webhooks.RequestsTotal != 2 {
+		t.Errorf("Expected 2 webhook requests, got %d", webhooks.RequestsTotal)
+	}
+	if orchestrator := client.OrchestratorStats(); orchestrator.RequestsFailure != 1 {
+		t.Errorf("Expected 1 failed orchestrator request, got %d", orchestrator.RequestsFailure)
+	}
+
+	client.ResetStatistics()
+	if stats := client.Statistics(); stats.RequestsTotal != 0 {
+		t.Errorf("Expected statistics to reset, got %+v", stats)
+	}
+}
+
+type recordingTransport struct {
+	method, path string
+}
+
+func (r *recordingTransport) Do(ctx context.Context, method, path string, body, result interface{}) error {
+	r.method, r.path = method, path
+	return json.Unmarshal([]byte(`{"success":true,"webhooks":[]}`), result)
+}
+
+func TestWithTransport(t *testing.T) {
+	client, _ := NewClient(ClientConfig{BaseURL: "http://localhost:3000"})
+	transport := &recordingTransport{}
+	client.WithTransport(transport)
+
+	if _, err := client.Webhooks().List(context.Background()); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if transport.method != "GET" || transport.path != "/api/zip/webhooks" {
+		t.Errorf("Expected GET /api/zip/webhooks, got %s %s", transport.method, transport.path)
+	}
+	if client.Statistics().RequestsTotal != 1 {
+		t.Errorf("Expected requests through a custom transport to be recorded")
+	}
+}
+
+func TestGetSessionEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("nodeId") != "n1" || query.Get("eventType") != "error" || query.Get("from") != "1000" || query.Get("limit") != "10" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"events":[{"timestamp":1500,"nodeId":"n1","eventType":"error","data":{"size":0,"dataType":"json"}}],"total":11,"hasMore":true}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	nodeID, eventType, limit := "n1", "error", 10
+	from := time.UnixMilli(1000)
+	result, err := client.Traces().GetSessionEvents(context.Background(), "session-1", &TraceQueryOptions{
+		NodeID:    &nodeID,
+		EventType: &eventType,
+		From:      &from,
+		Limit:     &limit,
+	})
+	if err != nil {
+		t.Fatalf("GetSessionEvents failed: %v", err)
+	}
+	if len(result.Events) != 1 || result.Total != 11 || !result.HasMore {
+		t.Errorf("Unexpected response: %+v", result)
+	}
+}
+
+func TestFetchSessionTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/zip/traces/session-1/events" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		switch r.URL.Query().Get("offset") {
+		case "0":
+			w.Write([]byte(`{"events":[{"timestamp":1,"nodeId":"n1","eventType":"output","data":{"size":4,"dataType":"json"}}],"total":2,"hasMore":true}`))
+		case "1":
+			w.Write([]byte(`{"events":[{"timestamp":2,"nodeId":"n2","eventType":"output","data":{"size":4,"dataType":"json"}}],"total":2,"hasMore":false}`))
+		default:
+			t.Errorf("Unexpected offset %s", r.URL.Query().Get("offset"))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	trace, err := FetchSessionTrace(context.Background(), client.Traces(), "session-1")
+	if err != nil {
+		t.Fatalf("FetchSessionTrace failed: %v", err)
+	}
+	if trace.SessionID != "session-1" || len(trace.Events) != 2 || trace.Events[1].NodeID != "n2" {
+		t.Errorf("Unexpected trace: %+v", trace)
+	}
+}
+
+func TestRegisterCompressesLargePayloads(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatalf("Invalid gzip body: %v", err)
+			}
+			body = gz
+		}
+		var req map[string]interface{}
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+		w.Write([]byte(`{"registered":1}`))
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	config.CompressionThresholdBytes = 1024
+	client, _ := NewClient(config)
+
+	small := RegisterTemplatesRequest{Namespace: "ns", Templates: []NodeTemplate{{ID: "t1", Title: "Small"}}}
+	large := RegisterTemplatesRequest{Namespace: "ns", Templates: []NodeTemplate{{ID: "t1", Title: strings.Repeat("x", 2048)}}}
+
+	if _, err := client.Templates().Register(context.Background(), small); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := client.Templates().Register(context.Background(), large); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := client.Templates().RegisterCategories(context.Background(), RegisterCategoriesRequest{Categories: []CategoryRegistration{{Name: strings.Repeat("x", 2048)}}}); err != nil {
+		t.Fatalf("RegisterCategories failed: %v", err)
+	}
+
+	expected := []string{"", "gzip", ""}
+	if strings.Join(encodings, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected encodings %q, got %q", expected, encodings)
+	}
+}
+
+func TestStreamListWorkflows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/zip/orchestrator/workflows" || r.URL.RawQuery != "limit=3" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`{"total":3,"workflows":[{"id":"wf-1"},{"id":"wf-2"},{"id":"wf-3"}],"limit":3,"offset":0}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	limit := 3
+	var ids []string
+	err := client.Orchestrator().StreamListWorkflows(context.Background(), &ListWorkflowsParams{Limit: &limit}, func(workflow interface{}) error {
+		ids = append(ids, workflow.(map[string]interface{})["id"].(string))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamListWorkflows failed: %v", err)
+	}
+	if strings.Join(ids, ",") != "wf-1,wf-2,wf-3" {
+		t.Errorf("Expected all workflows in order, got %v", ids)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = client.Orchestrator().StreamListWorkflows(context.Background(), &ListWorkflowsParams{Limit: &limit}, func(workflow interface{}) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected streaming to stop at the first error, got %v after %d calls", err, calls)
+	}
+
+	client.WithTransport(&recordingTransport{})
+	if err := client.Orchestrator().StreamListWorkflows(context.Background(), nil, func(interface{}) error { return nil }); err == nil {
+		t.Error("Expected an error for a transport without streaming support")
+	}
+}
+
+func TestTracedNodeFuncTimeout(t *testing.T) {
+	var eventTypes []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []TraceEvent `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		for _, event := range body.Events {
+			eventType := event.EventType
+			if event.Error != nil && event.Error.Code != nil {
+				eventType += ":" + *event.Error.Code
+			}
+			eventTypes = append(eventTypes, eventType)
+		}
+		mu.Unlock()
+		w.Write([]byte(`{"success":true,"eventsProcessed":1}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	timeout := 1
+	template := NodeTemplate{ID: "tpl_slow", Runtime: &RuntimeRequirements{Timeout: &timeout}}
+
+	slow := client.Traces().TracedNodeFunc("session-1", "n1", func(ctx context.Context, input interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithNodeTemplate(template))
+	if _, err := slow(context.Background(), "in"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	fast := client.Traces().TracedNodeFunc("session-1", "n2", func(ctx context.Context, input interface{}) (interface{}, error) {
+		return "out", nil
+	}, WithNodeTemplate(template))
+	if output, err := fast(context.Background(), "in"); err != nil || output != "out" {
+		t.Errorf("Expected output, got %v (%v)", output, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := "input,error:timeout,input,output"
+	if strings.Join(eventTypes, ",") != expected {
+		t.Errorf("Expected events %s, got %v", expected, eventTypes)
+	}
+}
+
+func TestListWorkflowsTyped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"workflows":[
+			{"workflowId":"wf-1","name":"Ingest","graphId":"main","createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-02-01T00:00:00Z"},
+			{"workflowId":"wf-2","name":"Report","description":"Daily","graphId":"main","createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-01T00:00:00Z"}
+		],"total":2,"limit":20,"offset":0}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	list, err := client.Orchestrator().ListWorkflows(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListWorkflows failed: %v", err)
+	}
+
+	wf, ok := list.FindByName("Report")
+	if !ok || wf.WorkflowID != "wf-2" || wf.Description != "Daily" {
+		t.Errorf("Unexpected workflow %+v", wf)
+	}
+	if list.Workflows[0].UpdatedAt.Month() != 2 {
+		t.Errorf("Expected UpdatedAt to be decoded, got %v", list.Workflows[0].UpdatedAt)
+	}
+	if _, ok := list.FindByName("Missing"); ok {
+		t.Error("Expected missing workflow not to be found")
+	}
+}
+
+func TestAddNodeResponseTyped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"nodeId":"n1","node":{"id":"n1","templateId":"http","position":{"x":5,"y":6},"properties":{"url":"https://example.com"},"createdAt":"2024-01-01T00:00:00Z"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	resp, err := client.Orchestrator().AddNode(context.Background(), AddNodeRequest{WorkflowID: "wf-1", TemplateID: "http"})
+	if err != nil {
+		t.Fatalf("AddNode failed: %v", err)
+	}
+	if resp.Node.TemplateID != "http" || resp.Node.Position.Y != 6 || resp.Node.Properties["url"] != "https://example.com" || resp.Node.CreatedAt.Year() != 2024 {
+		t.Errorf("Unexpected node %+v", resp.Node)
+	}
+}
+
+func TestUpdateNodeResponseNode(t *testing.T) {
+	withNode := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if withNode {
+			w.Write([]byte(`{"success":true,"node":{"id":"n1","position":{"x":1,"y":2}}}`))
+			return
+		}
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	resp, err := client.Orchestrator().UpdateNode(context.Background(), "n1", UpdateNodeRequest{WorkflowID: "wf-1"})
+	if err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if resp.Node == nil || resp.Node.Position.X != 1 {
+		t.Errorf("Expected updated node, got %+v", resp.Node)
+	}
+
+	withNode = false
+	resp, err = client.Orchestrator().UpdateNode(context.Background(), "n1", UpdateNodeRequest{WorkflowID: "wf-1"})
+	if err != nil || !resp.Success || resp.Node != nil {
+		t.Errorf("Expected success without node, got %+v (%v)", resp, err)
+	}
+}
+
+func TestCreateWebhookRequestWithDefaults(t *testing.T) {
+	req := CreateWebhookRequest{URL: "https://example.com"}.WithDefaults()
+	if *req.MaxRetries != 3 || *req.RetryInterval != 60 {
+		t.Errorf("Expected default retry policy, got %d/%d", *req.MaxRetries, *req.RetryInterval)
+	}
+	if req.Namespace != "default" {
+		t.Errorf("Expected default namespace, got %q", req.Namespace)
+	}
+	if req = (CreateWebhookRequest{Namespace: "tenant-a"}).WithDefaults(); req.Namespace != "tenant-a" {
+		t.Errorf("Expected explicit namespace to be kept, got %q", req.Namespace)
+	}
+
+	retries := 0
+	req = CreateWebhookRequest{MaxRetries: &retries}.WithDefaults()
+	if *req.MaxRetries != 0 {
+		t.Errorf("Expected explicit MaxRetries to be kept, got %d", *req.MaxRetries)
+	}
+
+	SetDefaultWebhookRetryPolicy(5, 10)
+	defer SetDefaultWebhookRetryPolicy(3, 60)
+
+	var received CreateWebhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"success":true,"subscription":{"id":"wh_1"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	if _, err := client.Webhooks().Create(context.Background(), CreateWebhookRequest{URL: "https://example.com"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if received.MaxRetries == nil || *received.MaxRetries != 5 || *received.RetryInterval != 10 {
+		t.Errorf("Expected custom defaults to be sent, got %+v", received)
+	}
+}
+
+func TestWebhooksAPITestErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		errorType string
+		succeeded bool
+	}{
+		{"success", `{"success":true,"statusCode":200}`, "", true},
+		{"http error", `{"success":false,"statusCode":502,"responseTimeMs":40}`, WebhookTestErrorHTTPError, false},
+		{"network", `{"success":false,"error":"fetch failed: ECONNREFUSED"}`, WebhookTestErrorNetwork, false},
+		{"timeout", `{"success":false,"error":"The operation was aborted due to timeout"}`, WebhookTestErrorTimeout, false},
+		{"structured", `{"success":false,"error":{"type":"timeout","message":"slow","latencyMs":5000}}`, WebhookTestErrorTimeout, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+			resp, err := client.Webhooks().Test(context.Background(), "wh_1")
+			if err != nil {
+				t.Fatalf("Test failed: %v", err)
+			}
+			if resp.Succeeded() != test.succeeded {
+				t.Errorf("Expected Succeeded() = %v", test.succeeded)
+			}
+			if test.errorType == "" {
+				if resp.Error != nil {
+					t.Errorf("Expected no error, got %+v", resp.Error)
+				}
+				return
+			}
+			if resp.Error == nil || resp.Error.Type != test.errorType {
+				t.Errorf("Expected %s error, got %+v", test.errorType, resp.Error)
+			}
+		})
+	}
+}
+
+func TestWebhooksAPITestWithRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			w.Write([]byte(`{"success":false,"statusCode":500,"responseTimeMs":30}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"statusCode":200,"responseTimeMs":10}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	results, err := client.Webhooks().TestWithRetry(context.Background(), "wh_1", 3, time.Millisecond)
+	if err != nil {
+		t.Fatalf("TestWithRetry failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	summary := TestResultsAnalysis(results)
+	if summary.SuccessCount != 2 || summary.FailureCount != 1 || summary.AllSucceeded {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if summary.AverageResponseTimeMs < 16.6 || summary.AverageResponseTimeMs > 16.7 {
+		t.Errorf("Expected average of ~16.67ms, got %f", summary.AverageResponseTimeMs)
+	}
+
+	if _, err := client.Webhooks().TestWithRetry(context.Background(), "wh_1", 0, 0); err == nil {
+		t.Error("Expected error for zero attempts")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = client.Webhooks().TestWithRetry(ctx, "wh_1", 3, time.Hour)
+	if err == nil || len(results) > 1 {
+		t.Errorf("Expected cancellation to stop retries, got %d results (%v)", len(results), err)
+	}
+}
+
+func TestListWebhooksResponseHelpers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"subscriptions":[
+			{"id":"wh_1","url":"https://a.example/hook","events":["node.*"],"isActive":true},
+			{"id":"wh_2","url":"https://b.example/hook","events":["workflow.created"],"isActive":false},
+			{"id":"wh_3","url":"https://c.example/hook","events":["*"],"isActive":true}
+		],"total":3}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	list, err := client.Webhooks().List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if sub, ok := list.FindByID("wh_2"); !ok || sub.URL != "https://b.example/hook" {
+		t.Errorf("Unexpected subscription %+v", sub)
+	}
+	if _, ok := list.FindByURL("https://missing.example"); ok {
+		t.Error("Expected missing URL not to be found")
+	}
+	if subs := list.FilterByEvent("node.added"); len(subs) != 2 || subs[0].ID != "wh_1" || subs[1].ID != "wh_3" {
+		t.Errorf("Unexpected node.added subscriptions %+v", subs)
+	}
+	if subs := list.ActiveSubscriptions(); len(subs) != 2 {
+		t.Errorf("Expected 2 active subscriptions, got %d", len(subs))
+	}
+
+	sub, err := client.Webhooks().GetByURL(context.Background(), "https://c.example/hook")
+	if err != nil || sub.ID != "wh_3" {
+		t.Errorf("Unexpected GetByURL result %+v, %v", sub, err)
+	}
+	if _, err := client.Webhooks().GetByURL(context.Background(), "https://missing.example"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWebhooksAPIRenewExpiring(t *testing.T) {
+	soon := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	later := time.Now().Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	var renewed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			var req UpdateWebhookRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.IsActive == nil || !*req.IsActive {
+				t.Errorf("Expected isActive true, got %v", req.IsActive)
+			}
+			id := strings.TrimPrefix(r.URL.Path, "/api/zip/webhooks/")
+			renewed = append(renewed, id)
+			w.Write([]byte(`{"success":true,"subscription":{"id":"` + id + `","isActive":true}}`))
+			return
+		}
+		w.Write([]byte(`{"subscriptions":[
+			{"id":"wh_1","url":"https://a.example","expiresAt":"` + soon + `"},
+			{"id":"wh_2","url":"https://b.example","expiresAt":"` + later + `"},
+			{"id":"wh_3","url":"https://c.example"}
+		],"total":3}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	subs, err := client.Webhooks().RenewExpiring(context.Background(), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("RenewExpiring failed: %v", err)
+	}
+	if len(subs) != 1 || subs[0].ID != "wh_1" || !subs[0].IsActive {
+		t.Errorf("Unexpected renewed subscriptions %+v", subs)
+	}
+	if len(renewed) != 1 || renewed[0] != "wh_1" {
+		t.Errorf("Expected only wh_1 to be updated, got %v", renewed)
+	}
+}
+
+func TestWebhooksAPIListByNamespace(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("namespace")
+		w.Write([]byte(`{"subscriptions":[{"id":"wh_1"}],"total":1}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	list, err := client.Webhooks().ListByNamespace(context.Background(), "tenant a")
+	if err != nil {
+		t.Fatalf("ListByNamespace failed: %v", err)
+	}
+	if query != "tenant a" {
+		t.Errorf("Expected namespace query, got %q", query)
+	}
+	if list.Total != 1 {
+		t.Errorf("Expected 1 subscription, got %d", list.Total)
+	}
+}
+
+func TestMakeRequestResendsBodyOnRetry(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"success":true,"workflowId":"wf-1","name":"Retry"}`))
+	}))
+	defer server.Close()
+
+	name := "Retry"
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL, MaxRetries: 2, RetryBackoffMs: 1})
+	if _, err := client.Orchestrator().UpdateWorkflowMetadata(context.Background(), "wf-1", UpdateWorkflowMetadataRequest{Name: &name}); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if !strings.Contains(body, `"name":"Retry"`) {
+			t.Errorf("Attempt %d sent body %q", i+1, body)
+		}
+	}
+}
+
+func TestWebhooksAPICreateSendsBatchConfig(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"subscription":{"id":"wh_1","batchConfig":{"maxBatchSize":50,"maxBatchDelayMs":2000}}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	resp, err := client.Webhooks().Create(context.Background(), CreateWebhookRequest{
+		URL:         "http://example.com/hook",
+		Events:      []string{"*"},
+		BatchConfig: &WebhookBatchConfig{MaxBatchSize: 50, MaxBatchDelayMs: 2000},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	batch, ok := body["batchConfig"].(map[string]interface{})
+	if !ok || batch["maxBatchSize"] != float64(50) || batch["maxBatchDelayMs"] != float64(2000) {
+		t.Errorf("Expected batchConfig in request body, got %v", body["batchConfig"])
+	}
+	if resp.Subscription.BatchConfig == nil || resp.Subscription.BatchConfig.MaxBatchSize != 50 {
+		t.Errorf("Expected batchConfig in response, got %+v", resp.Subscription.BatchConfig)
+	}
+}
+
+func TestSearchWorkflows(t *testing.T) {
+	var searchBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&searchBody)
+		w.Write([]byte(`{"workflows":[{"workflowId":"wf-1","name":"Billing"}],"total":1}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	result, err := client.Orchestrator().SearchWorkflows(context.Background(), &WorkflowSearchQuery{TextQuery: "bill", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchWorkflows failed: %v", err)
+	}
+	if searchBody["textQuery"] != "bill" || searchBody["limit"] != float64(10) {
+		t.Errorf("Unexpected search body %v", searchBody)
+	}
+	if result.Total != 1 || result.Workflows[0].WorkflowID != "wf-1" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestSearchWorkflowsFallsBackToList(t *testing.T) {
+	workflows := []string{
+		`{"workflowId":"wf-1","name":"Billing sync","metadata":{"team":"finance"}}`,
+		`{"workflowId":"wf-2","name":"Billing report","metadata":{"team":"growth"}}`,
+		`{"workflowId":"wf-3","name":"Onboarding","description":"billing setup","metadata":{"team":"finance"}}`,
+		`{"workflowId":"wf-4","name":"Reporting","metadata":{"team":"finance"}}`,
+	}
+	for i := 5; i <= 150; i++ {
+		workflows = append(workflows, fmt.Sprintf(`{"workflowId":"wf-%d","name":"Filler %d"}`, i, i))
+	}
+	workflows = append(workflows, `{"workflowId":"wf-last","name":"Billing audit","metadata":{"team":"finance"}}`)
+
+	var pages, requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/api/zip/orchestrator/workflows/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// Mirror the list route, which defaults to 20 workflows per page
+		limit, offset := 20, 0
+		fmt.Sscan(r.URL.Query().Get("limit"), &limit)
+		fmt.Sscan(r.URL.Query().Get("offset"), &offset)
+		end := offset + limit
+		if end > len(workflows) {
+			end = len(workflows)
+		}
+		pages++
+		fmt.Fprintf(w, `{"workflows":[%s],"total":%d,"limit":%d,"offset":%d}`,
+			strings.Join(workflows[offset:end], ","), len(workflows), limit, offset)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	result, err := client.Orchestrator().SearchWorkflows(context.Background(), &WorkflowSearchQuery{
+		TextQuery:      "BILLING",
+		MetadataFilter: map[string]interface{}{"team": "finance"},
+		Offset:         1,
+	})
+	if err != nil {
+		t.Fatalf("SearchWorkflows failed: %v", err)
+	}
+	if pages != 2 {
+		t.Errorf("Expected the fallback to list 2 pages, got %d", pages)
+	}
+	if result.Total != 3 {
+		t.Errorf("Expected 3 matches, got %d", result.Total)
+	}
+	if len(result.Workflows) != 2 || result.Workflows[0].WorkflowID != "wf-3" || result.Workflows[1].WorkflowID != "wf-last" {
+		t.Errorf("Expected wf-3 and wf-last after offset, got %+v", result.Workflows)
+	}
+
+	active := true
+	if _, err := client.Orchestrator().SearchWorkflows(context.Background(), &WorkflowSearchQuery{IsActive: &active}); !errors.Is(err, ErrUnsupportedSearchFilter) {
+		t.Errorf("Expected ErrUnsupportedSearchFilter for IsActive, got %v", err)
+	}
+
+	requests = 0
+	for _, query := range []*WorkflowSearchQuery{{Offset: -1}, {Limit: -1}} {
+		if _, err := client.Orchestrator().SearchWorkflows(context.Background(), query); !errors.Is(err, ErrInvalidSearchPagination) {
+			t.Errorf("Expected ErrInvalidSearchPagination for %+v, got %v", query, err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests for invalid pagination, got %d", requests)
+	}
+}
+
+func TestGetExecutionHistory(t *testing.T) {
+	var path string
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		query = r.URL.Query()
+		w.Write([]byte(`{"executions":[{"executionId":"ex-1","sessionId":"s-1","status":"completed","startedAt":"2024-01-01T00:00:00Z","duration":1500,"nodesExecuted":4}],"total":7,"limit":1,"offset":2}`))
+	}))
+	defer server.Close()
+
+	status := "completed"
+	from := time.UnixMilli(1700000000000)
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	history, err := client.Orchestrator().GetExecutionHistory(context.Background(), "wf-1", &ExecutionHistoryOptions{
+		Limit:    1,
+		Offset:   2,
+		Status:   &status,
+		From:     &from,
+		SortDesc: true,
+	})
+	if err != nil {
+		t.Fatalf("GetExecutionHistory failed: %v", err)
+	}
+	if path != "/api/zip/orchestrator/workflows/wf-1/executions" {
+		t.Errorf("Unexpected path %s", path)
+	}
+	if query.Get("limit") != "1" || query.Get("offset") != "2" || query.Get("status") != "completed" ||
+		query.Get("from") != "1700000000000" || query.Get("sort") != "desc" || query.Has("to") {
+		t.Errorf("Unexpected query %v", query)
+	}
+	if history.Total != 7 || len(history.Executions) != 1 {
+		t.Fatalf("Unexpected history %+v", history)
+	}
+	if record := history.Executions[0]; record.ExecutionID != "ex-1" || record.Duration != 1500 || record.NodesExecuted != 4 {
+		t.Errorf("Unexpected record %+v", record)
+	}
+}
+
+func TestTemplatesAPISafeDelete(t *testing.T) {
+	usage := `{"workflowRefs":[{"workflowId":"wf-1","workflowName":"Billing","nodeCount":2}],"totalWorkflows":1}`
+	var deleted bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/zip/templates/usage":
+			if r.URL.Query().Get("templateId") != "tpl-1" {
+				t.Errorf("Unexpected usage query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(usage))
+		case "/api/zip/templates/delete":
+			deleted = true
+			w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	templates := client.Templates()
+
+	refs, err := templates.GetTemplateUsage(context.Background(), "ns", "tpl-1")
+	if err != nil {
+		t.Fatalf("GetTemplateUsage failed: %v", err)
+	}
+	if refs.TotalWorkflows != 1 || refs.WorkflowRefs[0].NodeCount != 2 {
+		t.Errorf("Unexpected usage %+v", refs)
+	}
+
+	if _, err := templates.SafeDelete(context.Background(), "ns", "tpl-1", false); !errors.Is(err, ErrTemplateInUse) {
+		t.Errorf("Expected ErrTemplateInUse, got %v", err)
+	}
+	if deleted {
+		t.Fatal("Template in use should not be deleted")
+	}
+
+	if _, err := templates.SafeDelete(context.Background(), "ns", "tpl-1", true); err != nil || !deleted {
+		t.Errorf("Expected forced delete, got %v", err)
+	}
+
+	deleted = false
+	usage = `{"workflowRefs":[],"totalWorkflows":0}`
+	if _, err := templates.SafeDelete(context.Background(), "ns", "tpl-1", false); err != nil || !deleted {
+		t.Errorf("Expected unused template to be deleted, got %v", err)
+	}
+}
+
+func TestTemplatesAPIRegisterIfNotExists(t *testing.T) {
+	var registered []string
+	lists := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/zip/templates/ns":
+			lists++
+			w.Write([]byte(`{"namespace":"ns","templates":[{"id":"tpl-existing"}],"count":1}`))
+		case r.Method == "GET" && r.URL.Path == "/api/zip/templates/broken":
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == "POST" && r.URL.Path == "/api/zip/templates/register":
+			var req RegisterTemplatesRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			for _, template := range req.Templates {
+				registered = append(registered, template.ID)
+			}
+			w.Write([]byte(`{"success":true,"registeredCount":1,"registeredIds":["tpl-new"]}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	templates := client.Templates()
+	ctx := context.Background()
+
+	if exists, err := templates.ExistsTemplate(ctx, "ns", "tpl-existing"); err != nil || !exists {
+		t.Errorf("Expected tpl-existing to exist, got %v, %v", exists, err)
+	}
+	if exists, err := templates.ExistsTemplate(ctx, "ns", "tpl-new"); err != nil || exists {
+		t.Errorf("Expected tpl-new to be missing without error, got %v, %v", exists, err)
+	}
+	if _, err := templates.ExistsTemplate(ctx, "broken", "tpl-existing"); err == nil {
+		t.Error("Expected list errors to be returned")
+	}
+
+	lists = 0
+	result, err := templates.RegisterIfNotExists(ctx, "ns", []NodeTemplate{{ID: "tpl-existing"}, {ID: "tpl-new"}, {ID: "tpl-other"}})
+	if err != nil {
+		t.Fatalf("RegisterIfNotExists failed: %v", err)
+	}
+	if !reflect.DeepEqual(registered, []string{"tpl-new", "tpl-other"}) || result.RegisteredCount != 1 {
+		t.Errorf("Expected only missing templates to be registered, got %v", registered)
+	}
+	if lists != 1 {
+		t.Errorf("Expected namespace to be listed once, got %d", lists)
+	}
+
+	registered = nil
+	result, err = templates.RegisterIfNotExists(ctx, "ns", []NodeTemplate{{ID: "tpl-existing"}})
+	if err != nil || !result.Success || registered != nil {
+		t.Errorf("Expected no registration when all templates exist, got %v, %v", registered, err)
+	}
+	if _, err := templates.RegisterIfNotExists(ctx, "broken", []NodeTemplate{{ID: "tpl-new"}}); err == nil {
+		t.Error("Expected list errors to be returned")
+	}
+}
+
+func TestBatchConnectNodes(t *testing.T) {
+	var batchBody struct {
+		Connections []ConnectNodesRequest `json:"connections"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&batchBody)
+		w.Write([]byte(`{"results":[{"connectionId":"c1","success":true},{"success":false,"error":"port not found"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	reqs := []ConnectNodesRequest{
+		{WorkflowID: "wf-1", Source: NodePort{NodeID: "a", PortID: "out"}, Target: NodePort{NodeID: "b", PortID: "in"}},
+		{WorkflowID: "wf-1", Source: NodePort{NodeID: "b", PortID: "out"}, Target: NodePort{NodeID: "c", PortID: "missing"}},
+	}
+	result, err := client.Orchestrator().BatchConnectNodes(context.Background(), reqs)
+	if err != nil {
+		t.Fatalf("BatchConnectNodes failed: %v", err)
+	}
+	if len(batchBody.Connections) != 2 {
+		t.Errorf("Expected both connections in one request, got %+v", batchBody)
+	}
+	if len(result.Results) != 2 || !result.Results[0].Success || result.Results[1].Success || *result.Results[1].Error != "port not found" {
+		t.Errorf("Unexpected results %+v", result.Results)
+	}
+}
+
+func TestConnectNodesValidatedIgnoresOtherNodesPorts(t *testing.T) {
+	var connected bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/zip/orchestrator/workflows/wf-1/state":
+			w.Write([]byte(`{"workflowId":"wf-1","state":{"nodes":[{"id":"a"},{"id":"b"},{"id":"c"},{"id":"d"}],"connections":[
+				{"id":"c1","source":{"nodeId":"a","portId":"out"},"target":{"nodeId":"d","portId":"in"}},
+				{"id":"c2","source":{"nodeId":"b","portId":"in"},"target":{"nodeId":"c","portId":"in"}}
+			]}}`))
+		case "/api/zip/orchestrator/connections":
+			connected = true
+			w.Write([]byte(`{"connectionId":"c3"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	single := false
+	out := Port{ID: "out", Multiple: &single}
+	in := Port{ID: "in", Multiple: &single}
+	req := ConnectNodesRequest{
+		WorkflowID: "wf-1",
+		Source:     NodePort{NodeID: "b", PortID: "out"},
+		Target:     NodePort{NodeID: "d", PortID: "in2"},
+	}
+
+	// Node a's out port and node c's in port share IDs with the ports being
+	// connected but belong to other nodes
+	if _, err := client.Orchestrator().ConnectNodesValidated(context.Background(), req, out, Port{ID: "in2", Multiple: &single}); err != nil {
+		t.Fatalf("ConnectNodesValidated failed: %v", err)
+	}
+	if !connected {
+		t.Error("Expected the connection to be created")
+	}
+
+	req.Target = NodePort{NodeID: "d", PortID: "in"}
+	if _, err := client.Orchestrator().ConnectNodesValidated(context.Background(), req, out, in); !errors.Is(err, ErrPortAtCapacity) {
+		t.Errorf("Expected ErrPortAtCapacity for d's occupied in port, got %v", err)
+	}
+}
+
+func TestBatchConnectNodesFallback(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/zip/orchestrator/connections/batch" {
+			http.NotFound(w, r)
+			return
+		}
+		calls++
+		var req ConnectNodesRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Target.PortID == "missing" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"port not found"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"connectionId":"conn-%s"}`, req.Source.NodeID)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	reqs := []ConnectNodesRequest{
+		{WorkflowID: "wf-1", Source: NodePort{NodeID: "a", PortID: "out"}, Target: NodePort{NodeID: "b", PortID: "missing"}},
+		{WorkflowID: "wf-1", Source: NodePort{NodeID: "b", PortID: "out"}, Target: NodePort{NodeID: "c", PortID: "in"}},
+	}
+	result, err := client.Orchestrator().BatchConnectNodes(context.Background(), reqs)
+	if err != nil {
+		t.Fatalf("BatchConnectNodes failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected every connection to be attempted, got %d calls", calls)
+	}
+	if len(result.Results) != 2 || result.Results[0].Success || result.Results[0].Error == nil {
+		t.Errorf("Expected the first connection to fail, got %+v", result.Results)
+	}
+	if !result.Results[1].Success || result.Results[1].ConnectionID != "conn-b" {
+		t.Errorf("Expected the second connection to succeed, got %+v", result.Results[1])
+	}
+}
+
+func TestExpectedVersionConflict(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":"version mismatch","currentVersion":7}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	expected := 5
+	_, err := client.Orchestrator().AddNode(context.Background(), AddNodeRequest{WorkflowID: "wf-1", TemplateID: "tpl", ExpectedVersion: &expected})
+	if body["expectedVersion"] != float64(5) {
+		t.Errorf("Expected expectedVersion in the request, got %v", body)
+	}
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected VersionConflictError, got %v", err)
+	}
+	if conflict.WorkflowID != "wf-1" || conflict.ExpectedVersion != 5 || conflict.CurrentVersion != 7 {
+		t.Errorf("Unexpected conflict %+v", conflict)
+	}
+
+	_, err = client.Orchestrator().ConnectNodes(context.Background(), ConnectNodesRequest{WorkflowID: "wf-1", ExpectedVersion: &expected})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ConnectNodes to report a version conflict, got %v", err)
+	}
+
+	_, err = client.Orchestrator().AddNode(context.Background(), AddNodeRequest{WorkflowID: "wf-1", TemplateID: "tpl"})
+	if _, ok := body["expectedVersion"]; ok {
+		t.Errorf("Expected expectedVersion to be omitted, got %v", body)
+	}
+	if errors.Is(err, ErrVersionConflict) {
+		t.Error("Expected a plain APIError without ExpectedVersion")
+	}
+}
+
+func TestGetNodeExecutionHistory(t *testing.T) {
+	var path string
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		query = r.URL.Query()
+		w.Write([]byte(`{"executions":[{"executionId":"ex-1","sessionId":"s-1","status":"failed","startedAt":"2024-01-01T00:00:00Z","errorCode":"TIMEOUT","traceEvents":[{"nodeId":"n1","eventType":"error"}]}],"total":1}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	history, err := client.Orchestrator().GetNodeExecutionHistory(context.Background(), "n1", "wf-1", &NodeHistoryOptions{Limit: 5, IncludeTraceData: true})
+	if err != nil {
+		t.Fatalf("GetNodeExecutionHistory failed: %v", err)
+	}
+	if path != "/api/zip/orchestrator/nodes/n1/executions" {
+		t.Errorf("Unexpected path %s", path)
+	}
+	if query.Get("workflowId") != "wf-1" || query.Get("limit") != "5" || query.Get("includeTraceData") != "true" {
+		t.Errorf("Unexpected query %v", query)
+	}
+	record := history.Executions[0]
+	if record.ErrorCode == nil || *record.ErrorCode != "TIMEOUT" || record.Duration != nil {
+		t.Errorf("Unexpected record %+v", record)
+	}
+	if len(record.TraceEvents) != 1 || record.TraceEvents[0].EventType != "error" {
+		t.Errorf("Expected embedded trace events, got %+v", record.TraceEvents)
+	}
+}
+
+func TestGetWorkflowTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/zip/orchestrator/workflows/wf-1/template":
+			w.Write([]byte(`{"id":"tpl-1","type":"http","title":"HTTP Request"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	template, err := client.Orchestrator().GetWorkflowTemplate(context.Background(), "wf-1")
+	if err != nil {
+		t.Fatalf("GetWorkflowTemplate failed: %v", err)
+	}
+	if template.ID != "tpl-1" || template.Title != "HTTP Request" {
+		t.Errorf("Unexpected template %+v", template)
+	}
+
+	if _, err := client.Orchestrator().GetWorkflowTemplate(context.Background(), "wf-2"); !errors.Is(err, ErrNoTemplateSource) {
+		t.Errorf("Expected ErrNoTemplateSource, got %v", err)
+	}
+}
+
+func TestMoveNodeAndSetNodeProperty(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/api/zip/orchestrator/nodes/n1" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	if _, err := client.Orchestrator().MoveNode(context.Background(), "n1", "wf-1", Position{X: 10, Y: 20}, nil); err != nil {
+		t.Fatalf("MoveNode failed: %v", err)
+	}
+	if _, err := client.Orchestrator().SetNodeProperty(context.Background(), "n1", "wf-1", "url", "https://example.com", nil); err != nil {
+		t.Fatalf("SetNodeProperty failed: %v", err)
+	}
+
+	if _, ok := bodies[0]["properties"]; ok {
+		t.Errorf("Expected MoveNode to omit properties, got %v", bodies[0])
+	}
+	if position, _ := bodies[0]["position"].(map[string]interface{}); position["x"] != float64(10) || position["y"] != float64(20) {
+		t.Errorf("Unexpected position %v", bodies[0]["position"])
+	}
+	if _, ok := bodies[1]["position"]; ok {
+		t.Errorf("Expected SetNodeProperty to omit position, got %v", bodies[1])
+	}
+	if properties, _ := bodies[1]["properties"].(map[string]interface{}); properties["url"] != "https://example.com" || len(properties) != 1 {
+		t.Errorf("Unexpected properties %v", bodies[1]["properties"])
+	}
+
+	if _, err := client.Orchestrator().RenameNode(context.Background(), "n1", "wf-1", "Fetch users", nil); err != nil {
+		t.Fatalf("RenameNode failed: %v", err)
+	}
+	if bodies[2]["instanceName"] != "Fetch users" || len(bodies[2]) != 2 {
+		t.Errorf("Expected only workflowId and instanceName, got %v", bodies[2])
+	}
+	for _, name := range []string{"", strings.Repeat("n", 256)} {
+		if _, err := client.Orchestrator().RenameNode(context.Background(), "n1", "wf-1", name, nil); !errors.Is(err, ErrInvalidNodeName) {
+			t.Errorf("Expected ErrInvalidNodeName for a %d character name, got %v", len(name), err)
+		}
+	}
+	if len(bodies) != 3 {
+		t.Errorf("Expected invalid names not to be sent, got %d requests", len(bodies))
+	}
+}
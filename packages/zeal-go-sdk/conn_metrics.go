@@ -0,0 +1,200 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// QualityLevel grades a connection by its average ping latency
+type QualityLevel int
+
+const (
+	// QualityUnknown means no ping has completed yet
+	QualityUnknown QualityLevel = iota
+	// QualityExcellent is an average ping latency under 50ms
+	QualityExcellent
+	// QualityGood is an average ping latency under 200ms
+	QualityGood
+	// QualityDegraded is an average ping latency under 500ms
+	QualityDegraded
+	// QualityPoor is an average ping latency of 500ms or more
+	QualityPoor
+)
+
+func (q QualityLevel) String() string {
+	switch q {
+	case QualityExcellent:
+		return "excellent"
+	case QualityGood:
+		return "good"
+	case QualityDegraded:
+		return "degraded"
+	case QualityPoor:
+		return "poor"
+	}
+	return "unknown"
+}
+
+// ConnectionMetrics is a snapshot of ReconnectingConn health
+type ConnectionMetrics struct {
+	ConnectedAt          time.Time `json:"connectedAt"`
+	MessagesReceived     uint64    `json:"messagesReceived"`
+	MessagesSent         uint64    `json:"messagesSent"`
+	ReconnectCount       uint64    `json:"reconnectCount"`
+	LastPingLatencyMs    int64     `json:"lastPingLatencyMs"`
+	AveragePingLatencyMs int64     `json:"averagePingLatencyMs"`
+}
+
+// defaultPongTimeout is how long a ping waits for its pong before it is
+// no longer counted towards latency
+const defaultPongTimeout = 30 * time.Second
+
+// connMetrics holds the live counters, guarded by ReconnectingConn.mu
+type connMetrics struct {
+	connectedAt  time.Time
+	received     uint64
+	sent         uint64
+	reconnects   uint64
+	lastPingMs   int64
+	totalPingMs  int64
+	pings        int64
+	pingSeq      uint64
+	pendingPings []pendingPing
+}
+
+// pendingPing is a ping awaiting its pong. Pongs only echo the timestamp, so
+// pings sent within the same millisecond are told apart by seq and matched
+// in the order they were sent.
+type pendingPing struct {
+	seq       uint64
+	timestamp int64
+	sentAt    time.Time
+}
+
+// Metrics returns a snapshot of the connection metrics
+func (r *ReconnectingConn) Metrics() ConnectionMetrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metrics := ConnectionMetrics{
+		ConnectedAt:       r.metrics.connectedAt,
+		MessagesReceived:  r.metrics.received,
+		MessagesSent:      r.metrics.sent,
+		ReconnectCount:    r.metrics.reconnects,
+		LastPingLatencyMs: r.metrics.lastPingMs,
+	}
+	if r.metrics.pings > 0 {
+		metrics.AveragePingLatencyMs = r.metrics.totalPingMs / r.metrics.pings
+	}
+	return metrics
+}
+
+// ConnectionQuality grades the connection by its average ping latency
+func (r *ReconnectingConn) ConnectionQuality() QualityLevel {
+	r.mu.Lock()
+	pings := r.metrics.pings
+	r.mu.Unlock()
+	if pings == 0 {
+		return QualityUnknown
+	}
+
+	switch average := r.Metrics().AveragePingLatencyMs; {
+	case average < 50:
+		return QualityExcellent
+	case average < 200:
+		return QualityGood
+	case average < 500:
+		return QualityDegraded
+	}
+	return QualityPoor
+}
+
+// StartHeartbeat sends a ping every interval until the returned function is
+// called. Latency is measured when Receive returns the matching pong.
+func (r *ReconnectingConn) StartHeartbeat(interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.Send(ctx, CreatePingEvent())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+func (r *ReconnectingConn) recordSent() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics.sent++
+}
+
+// recordPing registers a ping as awaiting its pong, dropping pings whose pong
+// is overdue. It is called before the ping is sent so a fast pong always
+// finds its entry; the returned sequence number identifies it for forgetPing.
+func (r *ReconnectingConn) recordPing(ping *PingEvent) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.evictPingsLocked(now)
+	r.metrics.pingSeq++
+	r.metrics.pendingPings = append(r.metrics.pendingPings, pendingPing{
+		seq:       r.metrics.pingSeq,
+		timestamp: ping.Timestamp,
+		sentAt:    now,
+	})
+	return r.metrics.pingSeq
+}
+
+// forgetPing removes a ping that could not be sent
+func (r *ReconnectingConn) forgetPing(seq uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, pending := range r.metrics.pendingPings {
+		if pending.seq == seq {
+			r.metrics.pendingPings = append(r.metrics.pendingPings[:i], r.metrics.pendingPings[i+1:]...)
+			return
+		}
+	}
+}
+
+// evictPingsLocked drops pings sent more than the pong timeout ago. The
+// caller must hold r.mu.
+func (r *ReconnectingConn) evictPingsLocked(now time.Time) {
+	kept := r.metrics.pendingPings[:0]
+	for _, pending := range r.metrics.pendingPings {
+		if now.Sub(pending.sentAt) < r.pongTimeout {
+			kept = append(kept, pending)
+		}
+	}
+	r.metrics.pendingPings = kept
+}
+
+func (r *ReconnectingConn) recordReceived(frame []byte) {
+	var pong PongEvent
+	isPong := json.Unmarshal(frame, &pong) == nil && pong.Type == "pong"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics.received++
+	if !isPong {
+		return
+	}
+	r.evictPingsLocked(time.Now())
+	for i, pending := range r.metrics.pendingPings {
+		if pending.timestamp != pong.Timestamp {
+			continue
+		}
+		r.metrics.pendingPings = append(r.metrics.pendingPings[:i], r.metrics.pendingPings[i+1:]...)
+		r.metrics.lastPingMs = time.Since(pending.sentAt).Milliseconds()
+		r.metrics.totalPingMs += r.metrics.lastPingMs
+		r.metrics.pings++
+		return
+	}
+}
@@ -0,0 +1,106 @@
+package zeal
+
+import "strings"
+
+// WebhookEventTypes lists the event types ParseZipWebhookEvent understands
+var WebhookEventTypes = []string{
+	"node.executing", "node.completed", "node.failed", "node.warning",
+	"execution.started", "execution.completed", "execution.failed",
+	"workflow.created", "workflow.updated", "workflow.deleted",
+	"workflow.published", "workflow.unpublished",
+	"node.added", "node.updated", "node.deleted",
+	"connection.added", "connection.deleted",
+	"group.created", "group.updated", "group.deleted",
+	"template.registered", "trace.event",
+	"stream.opened", "stream.closed", "stream.error",
+}
+
+// ValidateEventFilterExpressions returns the entries of events that are
+// neither a known event type, "*", nor a "prefix.*" glob matching at least
+// one known event type
+func ValidateEventFilterExpressions(events []string) []string {
+	var invalid []string
+	for _, expr := range events {
+		if expr == "*" || isWebhookEventType(expr) {
+			continue
+		}
+		if prefix, ok := eventGlobPrefix(expr); ok && len(expandEventGlob(prefix)) > 0 {
+			continue
+		}
+		invalid = append(invalid, expr)
+	}
+	return invalid
+}
+
+// NormalizeEventFilter expands "*" and "prefix.*" globs into explicit event
+// types for servers without glob support. "execution.*" expands to every
+// type matched by IsExecutionEvent. Other entries are kept as they are and
+// duplicates are removed.
+func NormalizeEventFilter(events []string) []string {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(events))
+	add := func(eventType string) {
+		if !seen[eventType] {
+			seen[eventType] = true
+			normalized = append(normalized, eventType)
+		}
+	}
+
+	for _, expr := range events {
+		var expanded []string
+		if expr == "*" {
+			expanded = WebhookEventTypes
+		} else if prefix, ok := eventGlobPrefix(expr); ok {
+			expanded = expandEventGlob(prefix)
+		}
+		if len(expanded) == 0 {
+			add(expr)
+			continue
+		}
+		for _, eventType := range expanded {
+			add(eventType)
+		}
+	}
+	return normalized
+}
+
+// eventFilterMatches reports whether the filter expression expr selects
+// eventType
+func eventFilterMatches(expr, eventType string) bool {
+	if expr == "*" || expr == eventType {
+		return true
+	}
+	prefix, ok := eventGlobPrefix(expr)
+	if !ok {
+		return false
+	}
+	return prefix == "execution" && IsExecutionEvent(eventType) || strings.HasPrefix(eventType, prefix+".")
+}
+
+// eventGlobPrefix returns "prefix" for a "prefix.*" expression
+func eventGlobPrefix(expr string) (string, bool) {
+	if !strings.HasSuffix(expr, ".*") || len(expr) <= 2 {
+		return "", false
+	}
+	return strings.TrimSuffix(expr, ".*"), true
+}
+
+// expandEventGlob returns the known event types matched by "prefix.*"
+func expandEventGlob(prefix string) []string {
+	var types []string
+	for _, eventType := range WebhookEventTypes {
+		if prefix == "execution" && IsExecutionEvent(eventType) || strings.HasPrefix(eventType, prefix+".") {
+			types = append(types, eventType)
+		}
+	}
+	return types
+}
+
+func isWebhookEventType(eventType string) bool {
+	for _, known := range WebhookEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
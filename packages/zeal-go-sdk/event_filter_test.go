@@ -0,0 +1,39 @@
+package zeal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateEventFilterExpressions(t *testing.T) {
+	invalid := ValidateEventFilterExpressions([]string{"*", "node.completed", "group.*", "execution.*", "nodes.*", "node.exploded", "*.*"})
+	want := []string{"nodes.*", "node.exploded", "*.*"}
+	if !reflect.DeepEqual(invalid, want) {
+		t.Errorf("Expected %v, got %v", want, invalid)
+	}
+}
+
+func TestNormalizeEventFilter(t *testing.T) {
+	got := NormalizeEventFilter([]string{"execution.*", "node.completed", "group.*", "custom.event"})
+	want := []string{
+		"node.executing", "node.completed", "node.failed", "node.warning",
+		"execution.started", "execution.completed", "execution.failed",
+		"group.created", "group.updated", "group.deleted",
+		"custom.event",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if all := NormalizeEventFilter([]string{"*"}); len(all) != len(WebhookEventTypes) {
+		t.Errorf("Expected * to expand to all %d event types, got %d", len(WebhookEventTypes), len(all))
+	}
+}
+
+func TestWebhookEventTypesAreParseable(t *testing.T) {
+	for _, eventType := range WebhookEventTypes {
+		if _, err := ParseZipWebhookEvent([]byte(`{"type":"` + eventType + `"}`)); err != nil {
+			t.Errorf("ParseZipWebhookEvent(%s) failed: %v", eventType, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package zeal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// eventIDSettings holds the prefix of generated event IDs
+var eventIDSettings = struct {
+	sync.RWMutex
+	prefix string
+}{prefix: "evt_"}
+
+// SetEventIDPrefix sets the prefix of event IDs generated by the Create*Event
+// helpers. It defaults to "evt_"; an empty prefix yields bare UUIDs.
+func SetEventIDPrefix(prefix string) {
+	eventIDSettings.Lock()
+	defer eventIDSettings.Unlock()
+	eventIDSettings.prefix = prefix
+}
+
+// EventIDPrefix returns the prefix of generated event IDs
+func EventIDPrefix() string {
+	eventIDSettings.RLock()
+	defer eventIDSettings.RUnlock()
+	return eventIDSettings.prefix
+}
+
+// generateEventID returns the event ID prefix followed by a UUIDv7, so IDs
+// sort by creation time
+func generateEventID() string {
+	return EventIDPrefix() + newUUIDv7(time.Now())
+}
+
+// newUUIDv7 returns an RFC 9562 version 7 UUID: a 48-bit Unix millisecond
+// timestamp followed by 74 random bits
+func newUUIDv7(t time.Time) string {
+	var u [16]byte
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic(fmt.Sprintf("zeal: failed to read random bytes for event ID: %v", err))
+	}
+
+	ms := uint64(t.UnixMilli())
+	for i := 0; i < 6; i++ {
+		u[i] = byte(ms >> (40 - 8*i))
+	}
+	u[6] = 0x70 | (u[6] & 0x0f) // version 7
+	u[8] = 0x80 | (u[8] & 0x3f) // RFC 9562 variant
+	return formatUUID(u)
+}
+
+// formatUUID returns u in the canonical 8-4-4-4-12 hex form
+func formatUUID(u [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
@@ -0,0 +1,50 @@
+package zeal
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+var uuidV7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestGenerateEventID(t *testing.T) {
+	id := generateEventID()
+	if len(id) != len("evt_")+36 || id[:4] != "evt_" || !uuidV7Pattern.MatchString(id[4:]) {
+		t.Fatalf("Expected evt_ followed by a UUIDv7, got %s", id)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := generateEventID()
+		if seen[id] {
+			t.Fatalf("Duplicate event ID %s", id)
+		}
+		seen[id] = true
+	}
+
+	earlier := newUUIDv7(time.UnixMilli(1700000000000))
+	later := newUUIDv7(time.UnixMilli(1700000000001))
+	if earlier[:13] != "018bcfe5-6800" || earlier >= later {
+		t.Errorf("Expected time-ordered IDs, got %s and %s", earlier, later)
+	}
+
+	SetEventIDPrefix("")
+	defer SetEventIDPrefix("evt_")
+	if id := CreateNodeAddedEvent("wf-1", "n1", nil, nil).ID; !uuidV7Pattern.MatchString(id) {
+		t.Errorf("Expected a bare UUIDv7 without a prefix, got %s", id)
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	uuidV4Pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first, second := newRequestID(), newRequestID()
+	if !uuidV4Pattern.MatchString(first) || first == second {
+		t.Errorf("Expected distinct UUIDv4 request IDs, got %s and %s", first, second)
+	}
+
+	u := [16]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	if got := formatUUID(u); got != "01234567-89ab-cdef-0123-456789abcdef" {
+		t.Errorf("Unexpected UUID formatting %s", got)
+	}
+}
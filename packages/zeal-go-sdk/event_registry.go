@@ -0,0 +1,47 @@
+package zeal
+
+import (
+	"sort"
+	"sync"
+)
+
+var eventRegistry = struct {
+	sync.RWMutex
+	factories map[string]func() ZipWebhookEvent
+}{factories: make(map[string]func() ZipWebhookEvent)}
+
+// RegisterEventType makes ParseZipWebhookEvent decode events of eventType,
+// such as plugin-defined types, into the value returned by factory. factory
+// must return a pointer for the event to be decoded into. Built-in event
+// types take precedence over registered ones.
+func RegisterEventType(eventType string, factory func() ZipWebhookEvent) {
+	eventRegistry.Lock()
+	defer eventRegistry.Unlock()
+	eventRegistry.factories[eventType] = factory
+}
+
+// UnregisterEventType removes a type added with RegisterEventType
+func UnregisterEventType(eventType string) {
+	eventRegistry.Lock()
+	defer eventRegistry.Unlock()
+	delete(eventRegistry.factories, eventType)
+}
+
+// RegisteredEventTypes returns the registered event types in sorted order
+func RegisteredEventTypes() []string {
+	eventRegistry.RLock()
+	defer eventRegistry.RUnlock()
+	types := make([]string, 0, len(eventRegistry.factories))
+	for eventType := range eventRegistry.factories {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func registeredEventFactory(eventType string) (func() ZipWebhookEvent, bool) {
+	eventRegistry.RLock()
+	defer eventRegistry.RUnlock()
+	factory, ok := eventRegistry.factories[eventType]
+	return factory, ok
+}
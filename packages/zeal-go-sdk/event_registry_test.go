@@ -0,0 +1,48 @@
+package zeal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type pluginProcessedEvent struct {
+	ZipEventBase
+	Type  string `json:"type"`
+	Items int    `json:"items"`
+}
+
+func (e *pluginProcessedEvent) GetEventType() string  { return e.Type }
+func (e *pluginProcessedEvent) GetWorkflowID() string { return e.WorkflowID }
+
+func TestRegisterEventType(t *testing.T) {
+	data := []byte(`{"type":"plugin.acme.processed","workflowId":"wf-1","items":3}`)
+
+	RegisterEventType("plugin.acme.processed", func() ZipWebhookEvent { return &pluginProcessedEvent{} })
+	RegisterEventType("plugin.acme.failed", func() ZipWebhookEvent { return &pluginProcessedEvent{} })
+	if types := RegisteredEventTypes(); !reflect.DeepEqual(types, []string{"plugin.acme.failed", "plugin.acme.processed"}) {
+		t.Errorf("Unexpected registered types %v", types)
+	}
+
+	event, err := ParseZipWebhookEvent(data)
+	if err != nil {
+		t.Fatalf("Failed to parse registered event: %v", err)
+	}
+	processed, ok := event.(*pluginProcessedEvent)
+	if !ok {
+		t.Fatalf("Expected *pluginProcessedEvent, got %T", event)
+	}
+	if processed.Items != 3 || processed.GetWorkflowID() != "wf-1" {
+		t.Errorf("Unexpected event %+v", processed)
+	}
+
+	UnregisterEventType("plugin.acme.processed")
+	UnregisterEventType("plugin.acme.failed")
+	if types := RegisteredEventTypes(); len(types) != 0 {
+		t.Errorf("Expected no registered types, got %v", types)
+	}
+	var unknown *UnknownEventTypeError
+	if _, err := ParseZipWebhookEvent(data); !errors.As(err, &unknown) {
+		t.Errorf("Expected UnknownEventTypeError after unregistering, got %v", err)
+	}
+}
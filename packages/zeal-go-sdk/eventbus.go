@@ -0,0 +1,24 @@
+package zeal
+
+// EventBus receives typed events from a WebhookSubscriptionManager
+type EventBus interface {
+	Publish(event ZipWebhookEvent) error
+}
+
+// EventBusFunc adapts a function to the EventBus interface
+type EventBusFunc func(event ZipWebhookEvent) error
+
+// Publish calls f(event)
+func (f EventBusFunc) Publish(event ZipWebhookEvent) error {
+	return f(event)
+}
+
+// WithEventBus publishes each delivered event to bus after parsing it with
+// ParseZipWebhookEvent. Events that fail to parse are reported to the error
+// callbacks.
+func (ws *WebhookSubscriptionManager) WithEventBus(bus EventBus) *WebhookSubscriptionManager {
+	ws.mu.Lock()
+	ws.eventBus = bus
+	ws.mu.Unlock()
+	return ws
+}
@@ -1,19 +1,205 @@
 package zeal
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
 	"time"
 )
 
 // Base event structure for all ZIP events
 type ZipEventBase struct {
-	ID         string                 `json:"id"`
-	Timestamp  string                 `json:"timestamp"`
-	WorkflowID string                 `json:"workflowId"`
-	GraphID    *string                `json:"graphId,omitempty"`
-	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	ID         string          `json:"id"`
+	Timestamp  string          `json:"timestamp"`
+	WorkflowID string          `json:"workflowId"`
+	GraphID    *string         `json:"graphId,omitempty"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"` // decoded lazily, see GetMetadataValue
+}
+
+// ErrMetadataKeyNotFound is returned by GetMetadataValue for a missing key
+var ErrMetadataKeyNotFound = errors.New("metadata key not found")
+
+// eventBaseProvider is implemented by every event embedding ZipEventBase
+type eventBaseProvider interface {
+	eventBase() *ZipEventBase
+}
+
+func (b *ZipEventBase) eventBase() *ZipEventBase { return b }
+
+// ResolvedGraphID returns the event's graph ID, defaulting to "main"
+func (b ZipEventBase) ResolvedGraphID() string {
+	return resolveGraphID(b.GraphID)
+}
+
+// HasGraph reports whether the event belongs to graphID, ignoring case
+func (b ZipEventBase) HasGraph(graphID string) bool {
+	return strings.EqualFold(b.ResolvedGraphID(), graphID)
+}
+
+// resolveGraphID dereferences an optional graph ID, defaulting to "main"
+func resolveGraphID(graphID *string) string {
+	if graphID == nil {
+		return string(MainGraphID)
+	}
+	return *graphID
+}
+
+// GetMetadataValue decodes the metadata value stored under key into dst,
+// leaving the other metadata values undecoded
+func (b ZipEventBase) GetMetadataValue(key string, dst interface{}) error {
+	var fields map[string]json.RawMessage
+	if len(b.Metadata) > 0 {
+		if err := json.Unmarshal(b.Metadata, &fields); err != nil {
+			return fmt.Errorf("failed to decode metadata: %w", err)
+		}
+	}
+
+	value, ok := fields[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrMetadataKeyNotFound, key)
+	}
+	if err := json.Unmarshal(value, dst); err != nil {
+		return fmt.Errorf("failed to decode metadata %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetMetadataValue stores v under key in the event metadata
+func (b *ZipEventBase) SetMetadataValue(key string, v interface{}) error {
+	fields := map[string]json.RawMessage{}
+	if len(b.Metadata) > 0 {
+		if err := json.Unmarshal(b.Metadata, &fields); err != nil {
+			return fmt.Errorf("failed to decode metadata: %w", err)
+		}
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata %s: %w", key, err)
+	}
+	fields[key] = value
+
+	metadata, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	b.Metadata = metadata
+	return nil
+}
+
+// SetMetadata replaces the event metadata with the given map
+func (b *ZipEventBase) SetMetadata(metadata map[string]interface{}) error {
+	if metadata == nil {
+		b.Metadata = nil
+		return nil
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	b.Metadata = encoded
+	return nil
+}
+
+// EnrichOptions configures EnrichEvent
+type EnrichOptions struct {
+	// Overwrite replaces existing metadata values with the new ones
+	Overwrite bool `json:"overwrite"`
+}
+
+// EnrichEvent returns a deep copy of event with metadata merged into its
+// ZipEventBase.Metadata, leaving event unchanged. Existing keys are kept
+// unless Overwrite is set. The copy is made through JSON, so fields that are
+// not serialized are not copied.
+func EnrichEvent[T ZipWebhookEvent](event T, metadata map[string]interface{}, opts ...EnrichOptions) (T, error) {
+	var zero T
+	options := EnrichOptions{}
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return zero, fmt.Errorf("failed to encode event: %w", err)
+	}
+	target := reflect.New(reflect.TypeOf(event))
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return zero, fmt.Errorf("failed to copy event: %w", err)
+	}
+	copied := target.Elem().Interface().(T)
+
+	provider, ok := any(copied).(eventBaseProvider)
+	if !ok || reflect.ValueOf(copied).IsNil() {
+		return zero, fmt.Errorf("cannot enrich %T: event does not embed *ZipEventBase", event)
+	}
+	base := provider.eventBase()
+
+	fields := map[string]json.RawMessage{}
+	if len(base.Metadata) > 0 {
+		if err := json.Unmarshal(base.Metadata, &fields); err != nil {
+			return zero, fmt.Errorf("failed to decode metadata: %w", err)
+		}
+	}
+	for key, value := range metadata {
+		if _, exists := fields[key]; exists && !options.Overwrite {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return zero, fmt.Errorf("failed to encode metadata %s: %w", key, err)
+		}
+		fields[key] = encoded
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return zero, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	base.Metadata = merged
+	return copied, nil
+}
+
+// ParsedTimestamp parses the event's RFC3339 timestamp
+func (b ZipEventBase) ParsedTimestamp() (time.Time, error) {
+	return parseEventTimestamp(b.Timestamp)
+}
+
+// MustParsedTimestamp is like ParsedTimestamp but panics if the timestamp is invalid
+func (b ZipEventBase) MustParsedTimestamp() time.Time {
+	t, err := b.ParsedTimestamp()
+	if err != nil {
+		panic(fmt.Sprintf("zeal: invalid event timestamp %q: %v", b.Timestamp, err))
+	}
+	return t
+}
+
+// Age returns the time elapsed since the event timestamp
+func (b ZipEventBase) Age() (time.Duration, error) {
+	t, err := b.ParsedTimestamp()
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(t), nil
+}
+
+// IsStale reports whether the event is older than maxAge
+func (b ZipEventBase) IsStale(maxAge time.Duration) (bool, error) {
+	age, err := b.Age()
+	if err != nil {
+		return false, err
+	}
+	return age > maxAge, nil
+}
+
+// SetTimestamp sets the event timestamp, preserving sub-second precision
+func (b *ZipEventBase) SetTimestamp(t time.Time) {
+	b.Timestamp = formatEventTimestamp(t)
 }
 
 // Node execution events
@@ -39,6 +225,32 @@ type NodeFailedEvent struct {
 	NodeID            string     `json:"nodeId"`
 	OutputConnections []string   `json:"outputConnections"`
 	Error             *NodeError `json:"error,omitempty"`
+	Duration          *int64     `json:"duration,omitempty"`
+}
+
+// ExecutionDuration returns Duration, in milliseconds, as a time.Duration,
+// or nil if it is not set
+func (e *NodeCompletedEvent) ExecutionDuration() *time.Duration {
+	return millisDuration(e.Duration)
+}
+
+// OutputBytes returns OutputSize, the size of the node output in bytes
+func (e *NodeCompletedEvent) OutputBytes() *int64 {
+	return e.OutputSize
+}
+
+// ExecutionDuration returns Duration, in milliseconds, as a time.Duration,
+// or nil if it is not set
+func (e *NodeFailedEvent) ExecutionDuration() *time.Duration {
+	return millisDuration(e.Duration)
+}
+
+func millisDuration(ms *int64) *time.Duration {
+	if ms == nil {
+		return nil
+	}
+	d := time.Duration(*ms) * time.Millisecond
+	return &d
 }
 
 type NodeWarningEvent struct {
@@ -50,9 +262,68 @@ type NodeWarningEvent struct {
 }
 
 type NodeError struct {
-	Message string  `json:"message"`
-	Code    *string `json:"code,omitempty"`
-	Stack   *string `json:"stack,omitempty"`
+	Message string         `json:"message"`
+	Code    *NodeErrorCode `json:"code,omitempty"`
+	Stack   *string        `json:"stack,omitempty"`
+}
+
+// NodeErrorCode is the shared vocabulary of node failure codes
+type NodeErrorCode string
+
+const (
+	NodeErrorCodeTimeout    NodeErrorCode = "TIMEOUT"
+	NodeErrorCodePermission NodeErrorCode = "PERMISSION_DENIED"
+	NodeErrorCodeValidation NodeErrorCode = "VALIDATION_ERROR"
+	NodeErrorCodeUpstream   NodeErrorCode = "UPSTREAM_ERROR"
+	NodeErrorCodeRateLimit  NodeErrorCode = "RATE_LIMITED"
+	NodeErrorCodeMemory     NodeErrorCode = "OUT_OF_MEMORY"
+	NodeErrorCodeUnknown    NodeErrorCode = "UNKNOWN"
+)
+
+// NewNodeError creates a node error with the given code and optional stack trace
+func NewNodeError(code NodeErrorCode, message string, stack ...string) *NodeError {
+	nodeErr := &NodeError{
+		Message: message,
+		Code:    &code,
+	}
+	if len(stack) > 0 {
+		joined := strings.Join(stack, "\n")
+		nodeErr.Stack = &joined
+	}
+	return nodeErr
+}
+
+// NodeErrorFromErr maps a Go error to a NodeError, deriving the code from
+// known SDK and standard library error types
+func NodeErrorFromErr(err error) *NodeError {
+	if err == nil {
+		return nil
+	}
+
+	code := NodeErrorCodeUnknown
+	var apiErr *APIError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = NodeErrorCodeTimeout
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			code = NodeErrorCodeRateLimit
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			code = NodeErrorCodePermission
+		case apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode == http.StatusGatewayTimeout:
+			code = NodeErrorCodeTimeout
+		case apiErr.StatusCode >= 500:
+			code = NodeErrorCodeUpstream
+		case apiErr.StatusCode >= 400:
+			code = NodeErrorCodeValidation
+		}
+	case errors.As(err, &netErr) && netErr.Timeout():
+		code = NodeErrorCodeTimeout
+	}
+
+	return NewNodeError(code, err.Error())
 }
 
 type NodeWarning struct {
@@ -74,10 +345,15 @@ type ExecutionCompletedEvent struct {
 	Type           string             `json:"type"` // Always "execution.completed"
 	SessionID      string             `json:"sessionId"`
 	Duration       int64              `json:"duration"`
-	NodesExecuted  int                `json:"nodesExecuted"`
+	NodesExecuted  uint32             `json:"nodesExecuted"`
 	Summary        *ExecutionSummary  `json:"summary,omitempty"`
 }
 
+// ExecutionDuration returns Duration, in milliseconds, as a time.Duration
+func (e *ExecutionCompletedEvent) ExecutionDuration() time.Duration {
+	return time.Duration(e.Duration) * time.Millisecond
+}
+
 type ExecutionFailedEvent struct {
 	ZipEventBase
 	Type      string           `json:"type"` // Always "execution.failed"
@@ -86,12 +362,129 @@ type ExecutionFailedEvent struct {
 	Error     *ExecutionError  `json:"error,omitempty"`
 }
 
+// ExecutionTriggerType identifies what started a workflow execution
+type ExecutionTriggerType string
+
+const (
+	ManualTrigger   ExecutionTriggerType = "manual"
+	ScheduleTrigger ExecutionTriggerType = "schedule"
+	WebhookTrigger  ExecutionTriggerType = "webhook"
+	APITrigger      ExecutionTriggerType = "api"
+	ChainedTrigger  ExecutionTriggerType = "chained"
+)
+
 type ExecutionTrigger struct {
-	Type   string  `json:"type"`
-	Source *string `json:"source,omitempty"`
+	Type   ExecutionTriggerType `json:"type"`
+	Source *string              `json:"source,omitempty"`
+}
+
+// ManualExecutionTrigger creates a trigger for a user-initiated execution
+func ManualExecutionTrigger() *ExecutionTrigger {
+	return &ExecutionTrigger{Type: ManualTrigger}
+}
+
+// ScheduleExecutionTrigger creates a trigger for a cron-scheduled execution
+func ScheduleExecutionTrigger(cronExpr string) *ExecutionTrigger {
+	return &ExecutionTrigger{Type: ScheduleTrigger, Source: &cronExpr}
+}
+
+// WebhookExecutionTrigger creates a trigger for a webhook-initiated execution
+func WebhookExecutionTrigger(webhookID string) *ExecutionTrigger {
+	return &ExecutionTrigger{Type: WebhookTrigger, Source: &webhookID}
+}
+
+// WebhookSourceRef is the source of a WebhookTrigger
+type WebhookSourceRef struct {
+	WebhookID string
+}
+
+// ScheduleSourceRef is the source of a ScheduleTrigger
+type ScheduleSourceRef struct {
+	CronExpression string
+}
+
+// ChainedSourceRef is the source of a ChainedTrigger
+type ChainedSourceRef struct {
+	ParentExecutionID string
+}
+
+// UnknownSourceRef is the source of any other trigger type. Raw is empty
+// when the trigger has no source.
+type UnknownSourceRef struct {
+	Type ExecutionTriggerType
+	Raw  string
+}
+
+// ErrMissingTriggerSource is returned by ParseExecutionTriggerSource when a
+// webhook, schedule or chained trigger has no source
+var ErrMissingTriggerSource = errors.New("execution trigger has no source")
+
+// ParseExecutionTriggerSource returns the trigger source as a
+// *WebhookSourceRef, *ScheduleSourceRef or *ChainedSourceRef depending on the
+// trigger type, or as an *UnknownSourceRef for other types
+func ParseExecutionTriggerSource(trigger *ExecutionTrigger) (interface{}, error) {
+	if trigger == nil {
+		return nil, fmt.Errorf("execution trigger is nil")
+	}
+
+	var source string
+	if trigger.Source != nil {
+		source = *trigger.Source
+	}
+
+	switch trigger.Type {
+	case WebhookTrigger, ScheduleTrigger, ChainedTrigger:
+		if source == "" {
+			return nil, fmt.Errorf("%w: %s trigger", ErrMissingTriggerSource, trigger.Type)
+		}
+	}
+
+	switch trigger.Type {
+	case WebhookTrigger:
+		return &WebhookSourceRef{WebhookID: source}, nil
+	case ScheduleTrigger:
+		return &ScheduleSourceRef{CronExpression: source}, nil
+	case ChainedTrigger:
+		return &ChainedSourceRef{ParentExecutionID: source}, nil
+	default:
+		return &UnknownSourceRef{Type: trigger.Type, Raw: source}, nil
+	}
+}
+
+// IsScheduledExecution reports whether the execution was started by a schedule
+func IsScheduledExecution(e *ExecutionStartedEvent) bool {
+	return e != nil && e.Trigger != nil && e.Trigger.Type == ScheduleTrigger
+}
+
+// IsManuallyTriggered reports whether the execution was started manually
+func IsManuallyTriggered(e *ExecutionStartedEvent) bool {
+	return e != nil && e.Trigger != nil && e.Trigger.Type == ManualTrigger
 }
 
 type ExecutionSummary struct {
+	SuccessCount uint32 `json:"successCount"`
+	ErrorCount   uint32 `json:"errorCount"`
+	WarningCount uint32 `json:"warningCount"`
+}
+
+// TotalNodes returns the number of nodes that succeeded or failed
+func (s *ExecutionSummary) TotalNodes() uint32 {
+	return s.SuccessCount + s.ErrorCount
+}
+
+// ToLegacy converts the summary to the int-based shape used before the
+// counts became unsigned
+func (s *ExecutionSummary) ToLegacy() LegacyExecutionSummary {
+	return LegacyExecutionSummary{
+		SuccessCount: int(s.SuccessCount),
+		ErrorCount:   int(s.ErrorCount),
+		WarningCount: int(s.WarningCount),
+	}
+}
+
+// LegacyExecutionSummary is the int-based ExecutionSummary kept for callers
+// migrating to the unsigned counts
+type LegacyExecutionSummary struct {
 	SuccessCount int `json:"successCount"`
 	ErrorCount   int `json:"errorCount"`
 	WarningCount int `json:"warningCount"`
@@ -114,13 +507,38 @@ type WorkflowCreatedEvent struct {
 type WorkflowUpdatedEvent struct {
 	ZipEventBase
 	Type string                 `json:"type"` // Always "workflow.updated"
-	Data map[string]interface{} `json:"data,omitempty"`
+	Data map[string]interface{} `json:"data,omitempty"` // see DecodeData
+}
+
+// WorkflowUpdateData describes the fields changed by a workflow update
+type WorkflowUpdateData struct {
+	Name                *string                `json:"name,omitempty"`
+	Description         *string                `json:"description,omitempty"`
+	Metadata            map[string]interface{} `json:"metadata,omitempty"`
+	PreviousName        *string                `json:"previousName,omitempty"`
+	PreviousDescription *string                `json:"previousDescription,omitempty"`
+}
+
+// DecodeData decodes the event data into a WorkflowUpdateData
+func (e *WorkflowUpdatedEvent) DecodeData() (*WorkflowUpdateData, error) {
+	var data WorkflowUpdateData
+	if len(e.Data) == 0 {
+		return &data, nil
+	}
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode workflow update data: %w", err)
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow update data: %w", err)
+	}
+	return &data, nil
 }
 
 type WorkflowDeletedEvent struct {
 	ZipEventBase
-	Type         string  `json:"type"` // Always "workflow.deleted"
-	WorkflowName *string `json:"workflowName,omitempty"`
+	Type         string `json:"type"` // Always "workflow.deleted"
+	WorkflowName string `json:"workflowName,omitempty"` // empty from servers that omit it
 }
 
 type WorkflowPublishedEvent struct {
@@ -160,6 +578,45 @@ type NodeUpdatedEvent struct {
 	Data   map[string]interface{} `json:"data"`
 }
 
+// NodeEventData holds the common fields of node.added and node.updated event data
+type NodeEventData struct {
+	TemplateID   string                 `json:"templateId"`
+	InstanceName string                 `json:"instanceName"`
+	Position     *Position              `json:"position,omitempty"`
+	Properties   map[string]interface{} `json:"properties,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// DecodeData decodes the event data into a NodeEventData
+func (e *NodeAddedEvent) DecodeData() (*NodeEventData, error) {
+	var data NodeEventData
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// DecodeData decodes the event data into a NodeEventData
+func (e *NodeUpdatedEvent) DecodeData() (*NodeEventData, error) {
+	var data NodeEventData
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// decodeEventData converts untyped event data into dst through JSON
+func decodeEventData(data map[string]interface{}, dst interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("failed to decode event data: %w", err)
+	}
+	return nil
+}
+
 type NodeDeletedEvent struct {
 	ZipEventBase
 	Type   string `json:"type"` // Always "node.deleted"
@@ -178,6 +635,33 @@ type ConnectionDeletedEvent struct {
 	Data map[string]interface{} `json:"data"`
 }
 
+// ConnectionEventData holds the data of connection.added and connection.deleted
+// events. Deleted events usually carry only the connection ID.
+type ConnectionEventData struct {
+	ConnectionID string                 `json:"connectionId"`
+	Source       NodePort               `json:"source"`
+	Target       NodePort               `json:"target"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// DecodeData decodes the event data into a ConnectionEventData
+func (e *ConnectionAddedEvent) DecodeData() (*ConnectionEventData, error) {
+	var data ConnectionEventData
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// DecodeData decodes the event data into a ConnectionEventData
+func (e *ConnectionDeletedEvent) DecodeData() (*ConnectionEventData, error) {
+	var data ConnectionEventData
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
 type GroupCreatedEvent struct {
 	ZipEventBase
 	Type string                 `json:"type"` // Always "group.created"
@@ -196,12 +680,88 @@ type GroupDeletedEvent struct {
 	Data map[string]interface{} `json:"data"`
 }
 
+// GroupEventData holds the data of group events. Deleted events usually carry
+// only the group ID.
+type GroupEventData struct {
+	GroupID     string   `json:"groupId"`
+	Title       string   `json:"title,omitempty"`
+	NodeIDs     []string `json:"nodeIds,omitempty"`
+	Color       *string  `json:"color,omitempty"`
+	Description *string  `json:"description,omitempty"`
+}
+
+// toMap converts the data to the untyped form stored in group events
+func (d GroupEventData) toMap() map[string]interface{} {
+	data := map[string]interface{}{"groupId": d.GroupID}
+	if d.Title != "" {
+		data["title"] = d.Title
+	}
+	if d.NodeIDs != nil {
+		nodeIDs := make([]interface{}, len(d.NodeIDs))
+		for i, id := range d.NodeIDs {
+			nodeIDs[i] = id
+		}
+		data["nodeIds"] = nodeIDs
+	}
+	if d.Color != nil {
+		data["color"] = *d.Color
+	}
+	if d.Description != nil {
+		data["description"] = *d.Description
+	}
+	return data
+}
+
+// DecodeData decodes the event data into a GroupEventData
+func (e *GroupCreatedEvent) DecodeData() (*GroupEventData, error) {
+	var data GroupEventData
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// DecodeData decodes the event data into a GroupEventData
+func (e *GroupUpdatedEvent) DecodeData() (*GroupEventData, error) {
+	var data GroupEventData
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// DecodeData decodes the event data into a GroupEventData
+func (e *GroupDeletedEvent) DecodeData() (*GroupEventData, error) {
+	var data GroupEventData
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
 type TemplateRegisteredEvent struct {
 	ZipEventBase
 	Type string                 `json:"type"` // Always "template.registered"
 	Data map[string]interface{} `json:"data"`
 }
 
+// TemplateEventData holds the data of template.registered events
+type TemplateEventData struct {
+	Namespace       string         `json:"namespace"`
+	Templates       []NodeTemplate `json:"templates"`
+	RegisteredCount int            `json:"registeredCount"`
+	UpdatedCount    int            `json:"updatedCount"`
+}
+
+// DecodeData decodes the event data into a TemplateEventData
+func (e *TemplateRegisteredEvent) DecodeData() (*TemplateEventData, error) {
+	var data TemplateEventData
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
 type TraceEventData struct {
 	ZipEventBase
 	Type      string                 `json:"type"` // Always "trace.event"
@@ -210,6 +770,23 @@ type TraceEventData struct {
 	Data      map[string]interface{} `json:"data"`
 }
 
+// TracePayload holds the data of trace.event events
+type TracePayload struct {
+	InputData  interface{}        `json:"inputData,omitempty"`
+	OutputData interface{}        `json:"outputData,omitempty"`
+	Metrics    map[string]float64 `json:"metrics,omitempty"`
+	Tags       map[string]string  `json:"tags,omitempty"`
+}
+
+// DecodeData decodes the event data into a TracePayload
+func (e *TraceEventData) DecodeData() (*TracePayload, error) {
+	var data TracePayload
+	if err := decodeEventData(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
 // Stream display events (from Reflow binary streaming infrastructure)
 type StreamOpenedEvent struct {
 	ZipEventBase
@@ -284,9 +861,16 @@ func ParseStreamFrame(data []byte) (*StreamFrame, error) {
 
 // WebSocket control events
 type SubscribeEvent struct {
-	Type       string  `json:"type"` // Always "subscribe"
-	WorkflowID string  `json:"workflowId"`
-	GraphID    *string `json:"graphId,omitempty"`
+	Type       string                 `json:"type"` // Always "subscribe"
+	WorkflowID string                 `json:"workflowId"`
+	GraphID    *string                `json:"graphId,omitempty"`
+	Filter     map[string]interface{} `json:"filter,omitempty"`
+}
+
+// SubscribeAllEvent subscribes to events from every workflow in a namespace
+type SubscribeAllEvent struct {
+	Type      string `json:"type"` // Always "subscribe_all"
+	Namespace string `json:"namespace"`
 }
 
 type UnsubscribeEvent struct {
@@ -304,6 +888,15 @@ type PongEvent struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// ErrorControlEvent is an error frame sent by the server in reply to a
+// control event, such as a subscription update it does not support
+type ErrorControlEvent struct {
+	Type       string  `json:"type"` // Always "error"
+	Code       string  `json:"code,omitempty"`
+	Message    string  `json:"message,omitempty"`
+	WorkflowID *string `json:"workflowId,omitempty"`
+}
+
 // Connection state event for real-time visualization
 type ConnectionStateEvent struct {
 	ZipEventBase
@@ -314,6 +907,46 @@ type ConnectionStateEvent struct {
 	TargetNodeID   string `json:"targetNodeId"`
 }
 
+// ValidConnectionStates lists the states a ConnectionStateEvent may report
+var ValidConnectionStates = []string{"idle", "active", "success", "error"}
+
+// ErrInvalidConnectionState is returned when creating a ConnectionStateEvent with an unknown state
+var ErrInvalidConnectionState = errors.New("invalid connection state")
+
+// IsValidConnectionState reports whether s is one of ValidConnectionStates
+func IsValidConnectionState(s string) bool {
+	for _, state := range ValidConnectionStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidConnectionStateTransitions maps each connection state to the states it
+// may change to
+var ValidConnectionStateTransitions = map[string][]string{
+	"idle":    {"active"},
+	"active":  {"success", "error"},
+	"success": {"idle"},
+	"error":   {"idle"},
+}
+
+// ErrInvalidConnectionStateTransition is returned for a connection state
+// change not listed in ValidConnectionStateTransitions
+var ErrInvalidConnectionStateTransition = errors.New("invalid connection state transition")
+
+// ValidateConnectionStateTransition checks that a connection may change from
+// state from to state to
+func ValidateConnectionStateTransition(from, to string) error {
+	for _, next := range ValidConnectionStateTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q -> %q", ErrInvalidConnectionStateTransition, from, to)
+}
+
 // Union types using interfaces
 type ZipExecutionEvent interface {
 	GetEventType() string
@@ -350,6 +983,33 @@ type ZipWebhookEvent interface {
 	GetWorkflowID() string
 }
 
+// FilterStaleEvents splits events into those at most maxAge old and those older.
+// Events whose timestamp cannot be parsed are treated as stale.
+func FilterStaleEvents(events []ZipWebhookEvent, maxAge time.Duration) (fresh []ZipWebhookEvent, stale []ZipWebhookEvent) {
+	for _, event := range events {
+		provider, ok := event.(eventBaseProvider)
+		if !ok {
+			logf("cannot determine age of %s event, treating as stale", event.GetEventType())
+			stale = append(stale, event)
+			continue
+		}
+
+		base := provider.eventBase()
+		isStale, err := base.IsStale(maxAge)
+		if err != nil {
+			logf("invalid timestamp %q on %s event %s, treating as stale", base.Timestamp, event.GetEventType(), base.ID)
+			stale = append(stale, event)
+			continue
+		}
+		if isStale {
+			stale = append(stale, event)
+		} else {
+			fresh = append(fresh, event)
+		}
+	}
+	return fresh, stale
+}
+
 // Implement interfaces for execution events
 func (e *NodeExecutingEvent) GetEventType() string     { return e.Type }
 func (e *NodeExecutingEvent) GetWorkflowID() string    { return e.WorkflowID }
@@ -479,6 +1139,7 @@ func (e *TraceEventData) IsTraceEvent() bool      { return true }
 
 // Implement interfaces for control events
 func (e *SubscribeEvent) GetEventType() string   { return e.Type }
+func (e *SubscribeAllEvent) GetEventType() string { return e.Type }
 func (e *UnsubscribeEvent) GetEventType() string { return e.Type }
 func (e *PingEvent) GetEventType() string        { return e.Type }
 func (e *PongEvent) GetEventType() string        { return e.Type }
@@ -517,7 +1178,7 @@ func IsCRDTEvent(eventType string) bool {
 
 func IsControlEvent(eventType string) bool {
 	switch eventType {
-	case "subscribe", "unsubscribe", "ping", "pong":
+	case "subscribe", "subscribe_all", "unsubscribe", "ping", "pong":
 		return true
 	}
 	return false
@@ -564,25 +1225,31 @@ func IsStreamEvent(eventType string) bool {
 	return false
 }
 
-// Event creation helpers
-func generateEventID() string {
-	timestamp := time.Now().UnixMilli()
-	// Simple UUID-like generation for demo purposes
-	return fmt.Sprintf("evt_%d_%s", timestamp, generateRandomString(11))
+func IsTraceEvent(eventType string) bool {
+	return eventType == "trace.event"
 }
 
-func generateRandomString(length int) string {
-	// Simple random string generation
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
-	result := make([]byte, length)
-	for i := range result {
-		result[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(result)
+func IsConnectionStateEvent(eventType string) bool {
+	return eventType == "connection.state"
+}
+
+// IsPresenceEvent matches the "presence." event namespace reserved for
+// collaborator presence events, which the server does not emit yet
+func IsPresenceEvent(eventType string) bool {
+	return strings.HasPrefix(eventType, "presence.")
 }
 
+// Event creation helpers
 func currentTimestamp() string {
-	return time.Now().UTC().Format(time.RFC3339)
+	return formatEventTimestamp(time.Now())
+}
+
+func formatEventTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseEventTimestamp(timestamp string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, timestamp)
 }
 
 // CRDT Event creation helpers
@@ -614,6 +1281,52 @@ func CreateNodeUpdatedEvent(workflowID, nodeID string, data map[string]interface
 	}
 }
 
+// CreateWorkflowDeletedEvent creates a workflow.deleted event
+func CreateWorkflowDeletedEvent(workflowID, workflowName string, graphID *string) *WorkflowDeletedEvent {
+	return &WorkflowDeletedEvent{
+		ZipEventBase: ZipEventBase{
+			ID:         generateEventID(),
+			Timestamp:  currentTimestamp(),
+			WorkflowID: workflowID,
+			GraphID:    graphID,
+		},
+		Type:         "workflow.deleted",
+		WorkflowName: workflowName,
+	}
+}
+
+// CreateWorkflowUpdatedEvent creates a workflow.updated event carrying the
+// set fields of data
+func CreateWorkflowUpdatedEvent(workflowID string, data WorkflowUpdateData, graphID *string) *WorkflowUpdatedEvent {
+	eventData := map[string]interface{}{}
+	if data.Name != nil {
+		eventData["name"] = *data.Name
+	}
+	if data.Description != nil {
+		eventData["description"] = *data.Description
+	}
+	if data.Metadata != nil {
+		eventData["metadata"] = data.Metadata
+	}
+	if data.PreviousName != nil {
+		eventData["previousName"] = *data.PreviousName
+	}
+	if data.PreviousDescription != nil {
+		eventData["previousDescription"] = *data.PreviousDescription
+	}
+
+	return &WorkflowUpdatedEvent{
+		ZipEventBase: ZipEventBase{
+			ID:         generateEventID(),
+			Timestamp:  currentTimestamp(),
+			WorkflowID: workflowID,
+			GraphID:    graphID,
+		},
+		Type: "workflow.updated",
+		Data: eventData,
+	}
+}
+
 func CreateNodeDeletedEvent(workflowID, nodeID string, graphID *string) *NodeDeletedEvent {
 	return &NodeDeletedEvent{
 		ZipEventBase: ZipEventBase{
@@ -627,7 +1340,7 @@ func CreateNodeDeletedEvent(workflowID, nodeID string, graphID *string) *NodeDel
 	}
 }
 
-func CreateGroupCreatedEvent(workflowID string, data map[string]interface{}, graphID *string) *GroupCreatedEvent {
+func CreateGroupCreatedEvent(workflowID string, data GroupEventData, graphID *string) *GroupCreatedEvent {
 	return &GroupCreatedEvent{
 		ZipEventBase: ZipEventBase{
 			ID:         generateEventID(),
@@ -636,11 +1349,11 @@ func CreateGroupCreatedEvent(workflowID string, data map[string]interface{}, gra
 			GraphID:    graphID,
 		},
 		Type: "group.created",
-		Data: data,
+		Data: data.toMap(),
 	}
 }
 
-func CreateGroupUpdatedEvent(workflowID string, data map[string]interface{}, graphID *string) *GroupUpdatedEvent {
+func CreateGroupUpdatedEvent(workflowID string, data GroupEventData, graphID *string) *GroupUpdatedEvent {
 	return &GroupUpdatedEvent{
 		ZipEventBase: ZipEventBase{
 			ID:         generateEventID(),
@@ -649,11 +1362,11 @@ func CreateGroupUpdatedEvent(workflowID string, data map[string]interface{}, gra
 			GraphID:    graphID,
 		},
 		Type: "group.updated",
-		Data: data,
+		Data: data.toMap(),
 	}
 }
 
-func CreateGroupDeletedEvent(workflowID string, data map[string]interface{}, graphID *string) *GroupDeletedEvent {
+func CreateGroupDeletedEvent(workflowID string, data GroupEventData, graphID *string) *GroupDeletedEvent {
 	return &GroupDeletedEvent{
 		ZipEventBase: ZipEventBase{
 			ID:         generateEventID(),
@@ -662,7 +1375,57 @@ func CreateGroupDeletedEvent(workflowID string, data map[string]interface{}, gra
 			GraphID:    graphID,
 		},
 		Type: "group.deleted",
-		Data: data,
+		Data: data.toMap(),
+	}
+}
+
+// CreateTemplateRegisteredEvent creates a template.registered event counting
+// every template as newly registered
+func CreateTemplateRegisteredEvent(workflowID, namespace string, templates []NodeTemplate, graphID *string) *TemplateRegisteredEvent {
+	return &TemplateRegisteredEvent{
+		ZipEventBase: ZipEventBase{
+			ID:         generateEventID(),
+			Timestamp:  currentTimestamp(),
+			WorkflowID: workflowID,
+			GraphID:    graphID,
+		},
+		Type: "template.registered",
+		Data: map[string]interface{}{
+			"namespace":       namespace,
+			"templates":       templates,
+			"registeredCount": len(templates),
+			"updatedCount":    0,
+		},
+	}
+}
+
+// CreateTraceEventData creates a trace.event event carrying payload
+func CreateTraceEventData(workflowID, sessionID, nodeID string, payload TracePayload, graphID *string) *TraceEventData {
+	data := map[string]interface{}{}
+	if payload.InputData != nil {
+		data["inputData"] = payload.InputData
+	}
+	if payload.OutputData != nil {
+		data["outputData"] = payload.OutputData
+	}
+	if payload.Metrics != nil {
+		data["metrics"] = payload.Metrics
+	}
+	if payload.Tags != nil {
+		data["tags"] = payload.Tags
+	}
+
+	return &TraceEventData{
+		ZipEventBase: ZipEventBase{
+			ID:         generateEventID(),
+			Timestamp:  currentTimestamp(),
+			WorkflowID: workflowID,
+			GraphID:    graphID,
+		},
+		Type:      "trace.event",
+		SessionID: sessionID,
+		NodeID:    nodeID,
+		Data:      data,
 	}
 }
 
@@ -692,6 +1455,56 @@ func CreateConnectionDeletedEvent(workflowID string, data map[string]interface{}
 	}
 }
 
+// CreateConnectionStateEvent creates a connection state event, validating the
+// state. If a non-empty previousState is given, the transition from it is
+// validated too.
+func CreateConnectionStateEvent(workflowID, connectionID, state, sourceNodeID, targetNodeID string, graphID *string, previousState ...string) (*ConnectionStateEvent, error) {
+	if !IsValidConnectionState(state) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidConnectionState, state)
+	}
+	if len(previousState) > 0 && previousState[0] != "" {
+		if err := ValidateConnectionStateTransition(previousState[0], state); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ConnectionStateEvent{
+		ZipEventBase: ZipEventBase{
+			ID:         generateEventID(),
+			Timestamp:  currentTimestamp(),
+			WorkflowID: workflowID,
+			GraphID:    graphID,
+		},
+		Type:         "connection.state",
+		ConnectionID: connectionID,
+		State:        state,
+		SourceNodeID: sourceNodeID,
+		TargetNodeID: targetNodeID,
+	}, nil
+}
+
+// CreateSubscribeEvent creates a subscribe control event. Non-empty eventTypes
+// are sent as the "eventTypes" filter.
+func CreateSubscribeEvent(workflowID string, graphID *string, eventTypes []string) *SubscribeEvent {
+	event := &SubscribeEvent{
+		Type:       "subscribe",
+		WorkflowID: workflowID,
+		GraphID:    graphID,
+	}
+	if len(eventTypes) > 0 {
+		event.Filter = map[string]interface{}{"eventTypes": eventTypes}
+	}
+	return event
+}
+
+// CreateSubscribeAllEvent creates a control event subscribing to all workflows in a namespace
+func CreateSubscribeAllEvent(namespace string) *SubscribeAllEvent {
+	return &SubscribeAllEvent{
+		Type:      "subscribe_all",
+		Namespace: namespace,
+	}
+}
+
 // Stream event creation helpers
 func CreateStreamOpenedEvent(workflowID, nodeID, port string, streamID uint64, contentType *string, sizeHint *uint64, graphID *string) *StreamOpenedEvent {
 	return &StreamOpenedEvent{
@@ -740,13 +1553,25 @@ func CreateStreamErrorEvent(workflowID, nodeID string, streamID uint64, errorMsg
 	}
 }
 
+// UnknownEventTypeError is returned by ParseZipWebhookEvent for an event type
+// it does not recognize. RawData holds the original event payload.
+type UnknownEventTypeError struct {
+	EventType string
+	RawData   json.RawMessage
+}
+
+func (e *UnknownEventTypeError) Error() string {
+	return fmt.Sprintf("unknown event type: %s", e.EventType)
+}
+
 // Event parsing from JSON
 func ParseZipWebhookEvent(data []byte) (ZipWebhookEvent, error) {
+	codec := eventJSONCodec()
 	var eventType struct {
 		Type string `json:"type"`
 	}
 	
-	if err := json.Unmarshal(data, &eventType); err != nil {
+	if err := codec.Unmarshal(data, &eventType); err != nil {
 		return nil, fmt.Errorf("failed to parse event type: %w", err)
 	}
 	
@@ -754,108 +1579,113 @@ func ParseZipWebhookEvent(data []byte) (ZipWebhookEvent, error) {
 	// Execution events
 	case "node.executing":
 		var event NodeExecutingEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "node.completed":
 		var event NodeCompletedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "node.failed":
 		var event NodeFailedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "node.warning":
 		var event NodeWarningEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "execution.started":
 		var event ExecutionStartedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "execution.completed":
 		var event ExecutionCompletedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "execution.failed":
 		var event ExecutionFailedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	// Workflow events
 	case "workflow.created":
 		var event WorkflowCreatedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "workflow.updated":
 		var event WorkflowUpdatedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "workflow.deleted":
 		var event WorkflowDeletedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "workflow.published":
 		var event WorkflowPublishedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "workflow.unpublished":
 		var event WorkflowUnpublishedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	// CRDT events
 	case "node.added":
 		var event NodeAddedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "node.updated":
 		var event NodeUpdatedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "node.deleted":
 		var event NodeDeletedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "connection.added":
 		var event ConnectionAddedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "connection.deleted":
 		var event ConnectionDeletedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "group.created":
 		var event GroupCreatedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "group.updated":
 		var event GroupUpdatedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "group.deleted":
 		var event GroupDeletedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "template.registered":
 		var event TemplateRegisteredEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "trace.event":
 		var event TraceEventData
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	// Stream events
 	case "stream.opened":
 		var event StreamOpenedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "stream.closed":
 		var event StreamClosedEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	case "stream.error":
 		var event StreamErrorEvent
-		err := json.Unmarshal(data, &event)
+		err := codec.Unmarshal(data, &event)
 		return &event, err
 	default:
-		return nil, fmt.Errorf("unknown event type: %s", eventType.Type)
+		if factory, ok := registeredEventFactory(eventType.Type); ok {
+			event := factory()
+			err := codec.Unmarshal(data, event)
+			return event, err
+		}
+		return nil, &UnknownEventTypeError{EventType: eventType.Type, RawData: json.RawMessage(data)}
 	}
 }
\ No newline at end of file
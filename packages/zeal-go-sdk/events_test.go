@@ -0,0 +1,595 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNodeErrorFromErr(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected NodeErrorCode
+	}{
+		{"deadline", fmt.Errorf("wrapped: %w", context.DeadlineExceeded), NodeErrorCodeTimeout},
+		{"rate limit", &APIError{StatusCode: 429}, NodeErrorCodeRateLimit},
+		{"forbidden", &APIError{StatusCode: 403}, NodeErrorCodePermission},
+		{"bad request", &APIError{StatusCode: 400}, NodeErrorCodeValidation},
+		{"server error", fmt.Errorf("call failed: %w", &APIError{StatusCode: 502}), NodeErrorCodeUpstream},
+		{"unknown", errors.New("boom"), NodeErrorCodeUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodeErr := NodeErrorFromErr(test.err)
+			if nodeErr.Code == nil || *nodeErr.Code != test.expected {
+				t.Errorf("Expected code %s, got %v", test.expected, nodeErr.Code)
+			}
+			if nodeErr.Message != test.err.Error() {
+				t.Errorf("Expected message '%s', got '%s'", test.err.Error(), nodeErr.Message)
+			}
+		})
+	}
+
+	if NodeErrorFromErr(nil) != nil {
+		t.Error("Expected nil NodeError for nil error")
+	}
+}
+
+func TestExecutionSummaryAccumulator(t *testing.T) {
+	acc := NewExecutionSummaryAccumulator()
+	events := []ZipExecutionEvent{
+		&NodeExecutingEvent{Type: "node.executing"},
+		&NodeCompletedEvent{Type: "node.completed"},
+		&NodeCompletedEvent{Type: "node.completed"},
+		&NodeFailedEvent{Type: "node.failed"},
+		&NodeWarningEvent{Type: "node.warning"},
+	}
+
+	var wg sync.WaitGroup
+	for _, event := range events {
+		wg.Add(1)
+		go func(e ZipExecutionEvent) {
+			defer wg.Done()
+			acc.Record(e)
+		}(event)
+	}
+	wg.Wait()
+
+	summary := acc.Summary()
+	if summary.SuccessCount != 2 || summary.ErrorCount != 1 || summary.WarningCount != 1 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+}
+
+func TestEventTimestampRoundTrip(t *testing.T) {
+	now := time.Now()
+	event := CreateNodeAddedEvent("wf-1", "n1", nil, nil)
+	event.SetTimestamp(now)
+
+	parsed, err := event.ParsedTimestamp()
+	if err != nil {
+		t.Fatalf("ParsedTimestamp failed: %v", err)
+	}
+	if !parsed.Equal(now) {
+		t.Errorf("Expected %v after round trip, got %v", now, parsed)
+	}
+
+	created, err := CreateNodeAddedEvent("wf-1", "n1", nil, nil).ParsedTimestamp()
+	if err != nil {
+		t.Fatalf("Failed to parse generated timestamp: %v", err)
+	}
+	if created.Before(now) {
+		t.Errorf("Expected generated timestamp %v to keep sub-second precision after %v", created, now)
+	}
+}
+
+func TestMustParsedTimestampPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for invalid timestamp")
+		}
+	}()
+	ZipEventBase{Timestamp: "yesterday"}.MustParsedTimestamp()
+}
+
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+// useLogger installs l as the SDK logger and restores the previous logger
+// when the test finishes
+func useLogger(t *testing.T, l Logger) {
+	loggerMu.Lock()
+	previous := logger
+	logger = l
+	loggerMu.Unlock()
+	t.Cleanup(func() { SetLogger(previous) })
+}
+
+func TestFilterStaleEvents(t *testing.T) {
+	log := &recordingLogger{}
+	useLogger(t, log)
+
+	fresh := CreateNodeAddedEvent("wf-1", "fresh", nil, nil)
+	old := CreateNodeAddedEvent("wf-1", "old", nil, nil)
+	old.SetTimestamp(time.Now().Add(-time.Hour))
+	invalid := CreateNodeAddedEvent("wf-1", "invalid", nil, nil)
+	invalid.Timestamp = "not-a-time"
+
+	if age, err := old.Age(); err != nil || age < time.Hour {
+		t.Errorf("Expected age of at least an hour, got %v (%v)", age, err)
+	}
+	if _, err := invalid.IsStale(time.Minute); err == nil {
+		t.Error("Expected error for invalid timestamp")
+	}
+
+	freshEvents, staleEvents := FilterStaleEvents([]ZipWebhookEvent{fresh, old, invalid}, time.Minute)
+	if len(freshEvents) != 1 || freshEvents[0] != fresh {
+		t.Errorf("Expected only the fresh event, got %v", freshEvents)
+	}
+	if len(staleEvents) != 2 {
+		t.Errorf("Expected old and invalid events to be stale, got %v", staleEvents)
+	}
+	if len(log.messages) != 1 {
+		t.Errorf("Expected one warning for the invalid timestamp, got %v", log.messages)
+	}
+}
+
+func TestCreateConnectionStateEvent(t *testing.T) {
+	event, err := CreateConnectionStateEvent("wf-1", "conn-1", "active", "a", "b", nil)
+	if err != nil {
+		t.Fatalf("CreateConnectionStateEvent failed: %v", err)
+	}
+	if event.Type != "connection.state" || event.State != "active" || event.SourceNodeID != "a" || event.TargetNodeID != "b" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	if _, err := CreateConnectionStateEvent("wf-1", "conn-1", "pending", "a", "b", nil); !errors.Is(err, ErrInvalidConnectionState) {
+		t.Errorf("Expected ErrInvalidConnectionState, got %v", err)
+	}
+	if IsValidConnectionState("") {
+		t.Error("Expected empty state to be invalid")
+	}
+}
+
+func TestConnectionStateTransitions(t *testing.T) {
+	tests := []struct {
+		from, to string
+		valid    bool
+	}{
+		{"idle", "active", true},
+		{"active", "success", true},
+		{"active", "error", true},
+		{"success", "idle", true},
+		{"error", "idle", true},
+		{"idle", "success", false},
+		{"success", "active", false},
+		{"active", "active", false},
+		{"unknown", "idle", false},
+	}
+	for _, test := range tests {
+		err := ValidateConnectionStateTransition(test.from, test.to)
+		if test.valid && err != nil {
+			t.Errorf("Expected %s -> %s to be valid, got %v", test.from, test.to, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalidConnectionStateTransition) {
+			t.Errorf("Expected %s -> %s to be invalid, got %v", test.from, test.to, err)
+		}
+	}
+
+	if _, err := CreateConnectionStateEvent("wf-1", "conn-1", "success", "a", "b", nil, "active"); err != nil {
+		t.Errorf("Expected valid transition, got %v", err)
+	}
+	if _, err := CreateConnectionStateEvent("wf-1", "conn-1", "success", "a", "b", nil, "idle"); !errors.Is(err, ErrInvalidConnectionStateTransition) {
+		t.Errorf("Expected ErrInvalidConnectionStateTransition, got %v", err)
+	}
+	if _, err := CreateConnectionStateEvent("wf-1", "conn-1", "success", "a", "b", nil, ""); err != nil {
+		t.Errorf("Expected empty previous state to skip validation, got %v", err)
+	}
+}
+
+func TestEventMetadataValues(t *testing.T) {
+	event := CreateNodeAddedEvent("wf-1", "n1", nil, nil)
+	if err := event.SetMetadata(map[string]interface{}{"source": "editor", "attempt": 2}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	if err := event.SetMetadataValue("tags", []string{"a", "b"}); err != nil {
+		t.Fatalf("SetMetadataValue failed: %v", err)
+	}
+
+	var source string
+	var attempt int
+	var tags []string
+	if err := event.GetMetadataValue("source", &source); err != nil || source != "editor" {
+		t.Errorf("Expected source editor, got %q (%v)", source, err)
+	}
+	if err := event.GetMetadataValue("attempt", &attempt); err != nil || attempt != 2 {
+		t.Errorf("Expected attempt 2, got %d (%v)", attempt, err)
+	}
+	if err := event.GetMetadataValue("tags", &tags); err != nil || len(tags) != 2 {
+		t.Errorf("Expected 2 tags, got %v (%v)", tags, err)
+	}
+	if err := event.GetMetadataValue("missing", &source); !errors.Is(err, ErrMetadataKeyNotFound) {
+		t.Errorf("Expected ErrMetadataKeyNotFound, got %v", err)
+	}
+
+	data, _ := json.Marshal(event)
+	parsed, err := ParseZipWebhookEvent(data)
+	if err != nil {
+		t.Fatalf("Failed to parse event: %v", err)
+	}
+	if err := parsed.(*NodeAddedEvent).GetMetadataValue("source", &source); err != nil || source != "editor" {
+		t.Errorf("Expected metadata to survive a round trip, got %q (%v)", source, err)
+	}
+}
+
+func TestWorkflowUpdatedEventDecodeData(t *testing.T) {
+	parsed, err := ParseZipWebhookEvent([]byte(`{"type":"workflow.updated","id":"e1","timestamp":"2024-01-01T00:00:00Z","workflowId":"wf-1","data":{"name":"New","previousName":"Old","metadata":{"owner":"ops"}}}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	data, err := parsed.(*WorkflowUpdatedEvent).DecodeData()
+	if err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	if *data.Name != "New" || *data.PreviousName != "Old" || data.Metadata["owner"] != "ops" {
+		t.Errorf("Unexpected data %+v", data)
+	}
+
+	event := CreateWorkflowUpdatedEvent("wf-1", WorkflowUpdateData{Metadata: map[string]interface{}{"bad": make(chan int)}}, nil)
+	if _, err := event.DecodeData(); err == nil {
+		t.Error("Expected unencodable metadata to be reported")
+	}
+}
+
+func TestNodeEventDecodeData(t *testing.T) {
+	added := CreateNodeAddedEvent("wf-1", "n1", map[string]interface{}{
+		"templateId":   "http-request",
+		"instanceName": "Fetch",
+		"position":     map[string]interface{}{"x": 10.0, "y": 20.0},
+		"properties":   map[string]interface{}{"url": "https://example.com"},
+	}, nil)
+
+	data, err := added.DecodeData()
+	if err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	if data.TemplateID != "http-request" || data.InstanceName != "Fetch" || data.Position == nil || data.Position.Y != 20 {
+		t.Errorf("Unexpected data %+v", data)
+	}
+	if data.Properties["url"] != "https://example.com" {
+		t.Errorf("Unexpected properties %v", data.Properties)
+	}
+
+	updated := CreateNodeUpdatedEvent("wf-1", "n1", map[string]interface{}{"properties": map[string]interface{}{"retries": 3.0}}, nil)
+	data, err = updated.DecodeData()
+	if err != nil || data.Position != nil || data.Properties["retries"] != 3.0 {
+		t.Errorf("Unexpected update data %+v (%v)", data, err)
+	}
+
+	bad := CreateNodeUpdatedEvent("wf-1", "n1", map[string]interface{}{"position": "left"}, nil)
+	if _, err := bad.DecodeData(); err == nil {
+		t.Error("Expected error for malformed position")
+	}
+}
+
+func TestConnectionEventDecodeData(t *testing.T) {
+	added := CreateConnectionAddedEvent("wf-1", map[string]interface{}{
+		"connectionId": "c1",
+		"source":       map[string]interface{}{"nodeId": "n1", "portId": "out"},
+		"target":       map[string]interface{}{"nodeId": "n2", "portId": "in"},
+		"metadata":     map[string]interface{}{"label": "main"},
+	}, nil)
+
+	raw, err := json.Marshal(added)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	parsed, err := ParseZipWebhookEvent(raw)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	data, err := parsed.(*ConnectionAddedEvent).DecodeData()
+	if err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	want := ConnectionEventData{
+		ConnectionID: "c1",
+		Source:       NodePort{NodeID: "n1", PortID: "out"},
+		Target:       NodePort{NodeID: "n2", PortID: "in"},
+	}
+	if data.ConnectionID != want.ConnectionID || data.Source != want.Source || data.Target != want.Target || data.Metadata["label"] != "main" {
+		t.Errorf("Expected %+v, got %+v", want, data)
+	}
+
+	deleted := CreateConnectionDeletedEvent("wf-1", map[string]interface{}{"connectionId": "c1"}, nil)
+	if data, err := deleted.DecodeData(); err != nil || data.ConnectionID != "c1" {
+		t.Errorf("Unexpected deleted data %+v (%v)", data, err)
+	}
+}
+
+func TestGroupEventDecodeData(t *testing.T) {
+	color := "#ff0000"
+	created := CreateGroupCreatedEvent("wf-1", GroupEventData{
+		GroupID: "g1",
+		Title:   "Ingest",
+		NodeIDs: []string{"n1", "n2"},
+		Color:   &color,
+	}, nil)
+
+	raw, _ := json.Marshal(created)
+	if !strings.Contains(string(raw), `"nodeIds":["n1","n2"]`) {
+		t.Errorf("Expected serialized node IDs, got %s", raw)
+	}
+
+	data, err := created.DecodeData()
+	if err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	if data.GroupID != "g1" || data.Title != "Ingest" || len(data.NodeIDs) != 2 || *data.Color != color || data.Description != nil {
+		t.Errorf("Unexpected data %+v", data)
+	}
+
+	deleted := CreateGroupDeletedEvent("wf-1", GroupEventData{GroupID: "g1"}, nil)
+	if len(deleted.Data) != 1 {
+		t.Errorf("Expected deleted event to carry only the group ID, got %v", deleted.Data)
+	}
+	if data, err := deleted.DecodeData(); err != nil || data.GroupID != "g1" {
+		t.Errorf("Unexpected deleted data %+v (%v)", data, err)
+	}
+
+	updated := CreateGroupUpdatedEvent("wf-1", GroupEventData{GroupID: "g1", Title: "Renamed"}, nil)
+	if data, err := updated.DecodeData(); err != nil || data.Title != "Renamed" {
+		t.Errorf("Unexpected updated data %+v (%v)", data, err)
+	}
+}
+
+func TestTemplateRegisteredEventDecodeData(t *testing.T) {
+	templates := []NodeTemplate{
+		{ID: "http", Title: "HTTP", Ports: []Port{{ID: "out", Type: "output"}}},
+		{ID: "log", Title: "Log"},
+	}
+	event := CreateTemplateRegisteredEvent("wf-1", "acme", templates, nil)
+
+	raw, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	parsed, err := ParseZipWebhookEvent(raw)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	registered, ok := parsed.(*TemplateRegisteredEvent)
+	if !ok {
+		t.Fatalf("Expected *TemplateRegisteredEvent, got %T", parsed)
+	}
+
+	data, err := registered.DecodeData()
+	if err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	if data.Namespace != "acme" || data.RegisteredCount != 2 || data.UpdatedCount != 0 {
+		t.Errorf("Unexpected data %+v", data)
+	}
+	if len(data.Templates) != 2 || data.Templates[0].Ports[0].ID != "out" {
+		t.Errorf("Unexpected templates %+v", data.Templates)
+	}
+}
+
+func TestZipEventBaseResolvedGraphID(t *testing.T) {
+	var base ZipEventBase
+	if base.ResolvedGraphID() != "main" || !base.HasGraph("MAIN") {
+		t.Errorf("Expected nil graph ID to resolve to main, got %s", base.ResolvedGraphID())
+	}
+
+	graphID := "Sub-1"
+	base.GraphID = &graphID
+	if base.ResolvedGraphID() != "Sub-1" || !base.HasGraph("sub-1") || base.HasGraph("main") {
+		t.Errorf("Unexpected graph resolution for %s", base.ResolvedGraphID())
+	}
+}
+
+func TestParseZipWebhookEventUnknownType(t *testing.T) {
+	data := []byte(`{"type":"node.teleported","nodeId":"n1"}`)
+	_, err := ParseZipWebhookEvent(data)
+
+	var unknown *UnknownEventTypeError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected UnknownEventTypeError, got %v", err)
+	}
+	if unknown.EventType != "node.teleported" {
+		t.Errorf("Expected event type node.teleported, got %s", unknown.EventType)
+	}
+	if string(unknown.RawData) != string(data) {
+		t.Errorf("Expected raw data to be preserved, got %s", unknown.RawData)
+	}
+	if err.Error() != "unknown event type: node.teleported" {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+}
+
+func TestEnrichEvent(t *testing.T) {
+	original := CreateNodeAddedEvent("wf-1", "n1", nil, nil)
+	if err := original.SetMetadata(map[string]interface{}{"region": "eu-west-1"}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	originalMetadata := string(original.Metadata)
+
+	enriched, err := EnrichEvent(original, map[string]interface{}{"environment": "prod", "region": "us-east-1"})
+	if err != nil {
+		t.Fatalf("EnrichEvent failed: %v", err)
+	}
+	if enriched == original || enriched.NodeID != "n1" || enriched.Type != original.Type {
+		t.Errorf("Expected a copy of the event, got %+v", enriched)
+	}
+	if string(original.Metadata) != originalMetadata {
+		t.Errorf("Original metadata was modified: %s", original.Metadata)
+	}
+
+	var environment, region string
+	enriched.GetMetadataValue("environment", &environment)
+	enriched.GetMetadataValue("region", &region)
+	if environment != "prod" || region != "eu-west-1" {
+		t.Errorf("Expected merged metadata keeping existing keys, got %s/%s", environment, region)
+	}
+
+	overwritten, err := EnrichEvent(original, map[string]interface{}{"region": "us-east-1"}, EnrichOptions{Overwrite: true})
+	if err != nil {
+		t.Fatalf("EnrichEvent failed: %v", err)
+	}
+	overwritten.GetMetadataValue("region", &region)
+	if region != "us-east-1" {
+		t.Errorf("Expected region to be overwritten, got %s", region)
+	}
+
+	var generic ZipWebhookEvent = original
+	if _, err := EnrichEvent(generic, map[string]interface{}{"k": "v"}); err != nil {
+		t.Errorf("EnrichEvent through the interface failed: %v", err)
+	}
+}
+
+func TestParseExecutionTriggerSource(t *testing.T) {
+	parent := "ex-parent"
+	tests := []struct {
+		trigger *ExecutionTrigger
+		want    interface{}
+	}{
+		{WebhookExecutionTrigger("wh-1"), &WebhookSourceRef{WebhookID: "wh-1"}},
+		{ScheduleExecutionTrigger("0 * * * *"), &ScheduleSourceRef{CronExpression: "0 * * * *"}},
+		{&ExecutionTrigger{Type: ChainedTrigger, Source: &parent}, &ChainedSourceRef{ParentExecutionID: "ex-parent"}},
+		{ManualExecutionTrigger(), &UnknownSourceRef{Type: ManualTrigger}},
+		{&ExecutionTrigger{Type: "queue", Source: &parent}, &UnknownSourceRef{Type: "queue", Raw: "ex-parent"}},
+	}
+	for _, test := range tests {
+		got, err := ParseExecutionTriggerSource(test.trigger)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.trigger.Type, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %+v, got %+v", test.trigger.Type, test.want, got)
+		}
+	}
+
+	if _, err := ParseExecutionTriggerSource(&ExecutionTrigger{Type: WebhookTrigger}); !errors.Is(err, ErrMissingTriggerSource) {
+		t.Errorf("Expected ErrMissingTriggerSource, got %v", err)
+	}
+	if _, err := ParseExecutionTriggerSource(nil); err == nil {
+		t.Error("Expected error for nil trigger")
+	}
+}
+
+func TestExecutionTriggerPredicates(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     *ExecutionStartedEvent
+		scheduled bool
+		manual    bool
+	}{
+		{"manual", &ExecutionStartedEvent{Trigger: ManualExecutionTrigger()}, false, true},
+		{"schedule", &ExecutionStartedEvent{Trigger: ScheduleExecutionTrigger("0 * * * *")}, true, false},
+		{"webhook", &ExecutionStartedEvent{Trigger: WebhookExecutionTrigger("wh-1")}, false, false},
+		{"api", &ExecutionStartedEvent{Trigger: &ExecutionTrigger{Type: APITrigger}}, false, false},
+		{"chained", &ExecutionStartedEvent{Trigger: &ExecutionTrigger{Type: ChainedTrigger}}, false, false},
+		{"nil trigger", &ExecutionStartedEvent{}, false, false},
+		{"nil event", nil, false, false},
+	}
+	for _, test := range tests {
+		if got := IsScheduledExecution(test.event); got != test.scheduled {
+			t.Errorf("%s: expected IsScheduledExecution %v, got %v", test.name, test.scheduled, got)
+		}
+		if got := IsManuallyTriggered(test.event); got != test.manual {
+			t.Errorf("%s: expected IsManuallyTriggered %v, got %v", test.name, test.manual, got)
+		}
+	}
+
+	if trigger := ScheduleExecutionTrigger("0 * * * *"); trigger.Type != ScheduleTrigger || trigger.Source == nil || *trigger.Source != "0 * * * *" {
+		t.Errorf("Expected schedule trigger with cron source, got %+v", trigger)
+	}
+	if trigger := WebhookExecutionTrigger("wh-1"); trigger.Type != WebhookTrigger || trigger.Source == nil || *trigger.Source != "wh-1" {
+		t.Errorf("Expected webhook trigger with webhook source, got %+v", trigger)
+	}
+	if trigger := ManualExecutionTrigger(); trigger.Type != ManualTrigger || trigger.Source != nil {
+		t.Errorf("Expected manual trigger without source, got %+v", trigger)
+	}
+}
+
+func TestEventExecutionDurations(t *testing.T) {
+	duration, size := int64(1500), int64(2048)
+	completed := &NodeCompletedEvent{Duration: &duration, OutputSize: &size}
+	if d := completed.ExecutionDuration(); d == nil || *d != 1500*time.Millisecond {
+		t.Errorf("Expected 1.5s, got %v", d)
+	}
+	if b := completed.OutputBytes(); b == nil || *b != 2048 {
+		t.Errorf("Expected 2048 output bytes, got %v", b)
+	}
+	if d := (&NodeCompletedEvent{}).ExecutionDuration(); d != nil {
+		t.Errorf("Expected nil duration, got %v", *d)
+	}
+
+	var failed NodeFailedEvent
+	if err := json.Unmarshal([]byte(`{"type":"node.failed","nodeId":"n1","duration":250}`), &failed); err != nil {
+		t.Fatalf("Failed to decode event: %v", err)
+	}
+	if d := failed.ExecutionDuration(); d == nil || *d != 250*time.Millisecond {
+		t.Errorf("Expected 250ms, got %v", d)
+	}
+
+	if d := (&ExecutionCompletedEvent{Duration: 3000}).ExecutionDuration(); d != 3*time.Second {
+		t.Errorf("Expected 3s, got %v", d)
+	}
+}
+
+func TestExecutionSummaryCounts(t *testing.T) {
+	summary := &ExecutionSummary{SuccessCount: 5, ErrorCount: 2, WarningCount: 1}
+	if total := summary.TotalNodes(); total != 7 {
+		t.Errorf("Expected 7 nodes, got %d", total)
+	}
+	if legacy := summary.ToLegacy(); legacy != (LegacyExecutionSummary{SuccessCount: 5, ErrorCount: 2, WarningCount: 1}) {
+		t.Errorf("Unexpected legacy summary %+v", legacy)
+	}
+
+	var event ExecutionCompletedEvent
+	if err := json.Unmarshal([]byte(`{"type":"execution.completed","nodesExecuted":-1}`), &event); err == nil {
+		t.Error("Expected negative nodesExecuted to be rejected")
+	}
+	if err := json.Unmarshal([]byte(`{"summary":{"successCount":-2}}`), &event); err == nil {
+		t.Error("Expected negative summary counts to be rejected")
+	}
+}
+
+func TestWorkflowDeletedEvent(t *testing.T) {
+	created := CreateWorkflowDeletedEvent("wf-1", "Billing", nil)
+	data, _ := json.Marshal(created)
+	event, err := ParseZipWebhookEvent(data)
+	if err != nil {
+		t.Fatalf("Failed to parse event: %v", err)
+	}
+	if deleted, ok := event.(*WorkflowDeletedEvent); !ok || deleted.WorkflowName != "Billing" || deleted.GetWorkflowID() != "wf-1" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	event, err = ParseZipWebhookEvent([]byte(`{"type":"workflow.deleted","workflowId":"wf-1"}`))
+	if err != nil {
+		t.Fatalf("Expected event without a name to parse, got %v", err)
+	}
+	if deleted := event.(*WorkflowDeletedEvent); deleted.WorkflowName != "" {
+		t.Errorf("Expected empty name, got %q", deleted.WorkflowName)
+	}
+}
@@ -0,0 +1,180 @@
+package zeal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoFailedNode is returned by FetchFailedNode when the failure is not
+// attributed to a node
+var ErrNoFailedNode = errors.New("execution failure has no node ID")
+
+// FetchFailedNode fetches the node that caused the execution to fail
+func (e *ExecutionFailedEvent) FetchFailedNode(ctx context.Context, api *OrchestratorAPI) (*NodeDetail, error) {
+	if e.Error == nil || e.Error.NodeID == nil {
+		return nil, ErrNoFailedNode
+	}
+	return api.GetNode(ctx, *e.Error.NodeID, e.WorkflowID, e.GraphID)
+}
+
+// FetchNode fetches the node that failed
+func (e *NodeFailedEvent) FetchNode(ctx context.Context, api *OrchestratorAPI) (*NodeDetail, error) {
+	return api.GetNode(ctx, e.NodeID, e.WorkflowID, e.GraphID)
+}
+
+// ToTraceSessionRequest builds the trace session request for this execution.
+// The event's SessionID becomes the session's ExecutionID.
+func (e *ExecutionStartedEvent) ToTraceSessionRequest() CreateTraceSessionRequest {
+	metadata := map[string]interface{}{}
+	if e.WorkflowName != "" {
+		metadata["workflowName"] = e.WorkflowName
+	}
+	if e.GraphID != nil {
+		metadata["graphId"] = *e.GraphID
+	}
+	if e.Trigger != nil {
+		metadata["trigger"] = *e.Trigger
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+
+	return CreateTraceSessionRequest{
+		WorkflowID:  e.WorkflowID,
+		ExecutionID: e.SessionID,
+		Metadata:    metadata,
+	}
+}
+
+// CreateSessionForExecution creates the trace session for a started execution
+func (api *TracesAPI) CreateSessionForExecution(ctx context.Context, event *ExecutionStartedEvent) (*CreateTraceSessionResponse, error) {
+	if event == nil {
+		return nil, errors.New("execution started event is required")
+	}
+	if event.WorkflowID == "" {
+		return nil, errors.New("execution started event has no workflow ID")
+	}
+	return api.CreateSession(ctx, event.ToTraceSessionRequest())
+}
+
+// ExecutionSummaryAccumulator builds an ExecutionSummary incrementally from
+// node execution events. It is safe for concurrent use.
+type ExecutionSummaryAccumulator struct {
+	summary ExecutionSummary
+	mu      sync.Mutex
+}
+
+// NewExecutionSummaryAccumulator creates an empty summary accumulator
+func NewExecutionSummaryAccumulator() *ExecutionSummaryAccumulator {
+	return &ExecutionSummaryAccumulator{}
+}
+
+// Record updates the summary counters from a node execution event.
+// Events that do not affect the summary are ignored.
+func (a *ExecutionSummaryAccumulator) Record(event ZipExecutionEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch event.(type) {
+	case *NodeCompletedEvent:
+		a.summary.SuccessCount++
+	case *NodeFailedEvent:
+		a.summary.ErrorCount++
+	case *NodeWarningEvent:
+		a.summary.WarningCount++
+	}
+}
+
+// Summary returns a snapshot of the current summary
+func (a *ExecutionSummaryAccumulator) Summary() ExecutionSummary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.summary
+}
+
+// ExecutionReplayHandler receives events during an execution replay
+type ExecutionReplayHandler interface {
+	OnNodeExecuting(*NodeExecutingEvent) error
+	OnNodeCompleted(*NodeCompletedEvent) error
+	OnNodeFailed(*NodeFailedEvent) error
+	OnExecutionCompleted(*ExecutionCompletedEvent) error
+}
+
+// ReplayOptions configures ReplayExecution
+type ReplayOptions struct {
+	// PlaybackSpeed scales the original gaps between events. 1 replays in
+	// real time, 2 replays twice as fast, and 0 replays without delay.
+	PlaybackSpeed float64 `json:"playbackSpeed"`
+}
+
+// ReplayExecution replays a recorded execution event stream to the handler in
+// timestamp order. Event types the handler does not cover are skipped.
+func ReplayExecution(ctx context.Context, events []ZipExecutionEvent, handler ExecutionReplayHandler, opts ...ReplayOptions) error {
+	options := ReplayOptions{}
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.PlaybackSpeed < 0 {
+		return fmt.Errorf("playback speed cannot be negative: %v", options.PlaybackSpeed)
+	}
+
+	type timedEvent struct {
+		at    time.Time
+		event ZipExecutionEvent
+	}
+
+	ordered := make([]timedEvent, 0, len(events))
+	for _, event := range events {
+		provider, ok := event.(eventBaseProvider)
+		if !ok {
+			return fmt.Errorf("event %s has no timestamp", event.GetEventType())
+		}
+		at, err := parseEventTimestamp(provider.eventBase().Timestamp)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp on %s event: %w", event.GetEventType(), err)
+		}
+		ordered = append(ordered, timedEvent{at: at, event: event})
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].at.Before(ordered[j].at)
+	})
+
+	for i, item := range ordered {
+		if i > 0 && options.PlaybackSpeed > 0 {
+			gap := time.Duration(float64(item.at.Sub(ordered[i-1].at)) / options.PlaybackSpeed)
+			if gap > 0 {
+				timer := time.NewTimer(gap)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		switch e := item.event.(type) {
+		case *NodeExecutingEvent:
+			err = handler.OnNodeExecuting(e)
+		case *NodeCompletedEvent:
+			err = handler.OnNodeCompleted(e)
+		case *NodeFailedEvent:
+			err = handler.OnNodeFailed(e)
+		case *ExecutionCompletedEvent:
+			err = handler.OnExecutionCompleted(e)
+		}
+		if err != nil {
+			return fmt.Errorf("replay handler failed on %s event: %w", item.event.GetEventType(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordingReplayHandler struct {
+	order []string
+}
+
+func (h *recordingReplayHandler) OnNodeExecuting(e *NodeExecutingEvent) error {
+	h.order = append(h.order, "executing:"+e.NodeID)
+	return nil
+}
+
+func (h *recordingReplayHandler) OnNodeCompleted(e *NodeCompletedEvent) error {
+	h.order = append(h.order, "completed:"+e.NodeID)
+	return nil
+}
+
+func (h *recordingReplayHandler) OnNodeFailed(e *NodeFailedEvent) error {
+	h.order = append(h.order, "failed:"+e.NodeID)
+	return nil
+}
+
+func (h *recordingReplayHandler) OnExecutionCompleted(e *ExecutionCompletedEvent) error {
+	h.order = append(h.order, "execution.completed")
+	return nil
+}
+
+func TestReplayExecutionOrdering(t *testing.T) {
+	events := []ZipExecutionEvent{
+		&ExecutionCompletedEvent{ZipEventBase: ZipEventBase{Timestamp: "2024-01-01T00:00:03Z"}, Type: "execution.completed"},
+		&NodeCompletedEvent{ZipEventBase: ZipEventBase{Timestamp: "2024-01-01T00:00:02Z"}, Type: "node.completed", NodeID: "a"},
+		&NodeExecutingEvent{ZipEventBase: ZipEventBase{Timestamp: "2024-01-01T00:00:01Z"}, Type: "node.executing", NodeID: "a"},
+		&ExecutionStartedEvent{ZipEventBase: ZipEventBase{Timestamp: "2024-01-01T00:00:00Z"}, Type: "execution.started"},
+	}
+
+	handler := &recordingReplayHandler{}
+	if err := ReplayExecution(context.Background(), events, handler); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	expected := []string{"executing:a", "completed:a", "execution.completed"}
+	if len(handler.order) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, handler.order)
+	}
+	for i := range expected {
+		if handler.order[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, handler.order)
+			break
+		}
+	}
+}
+
+func TestReplayExecutionCancelled(t *testing.T) {
+	events := []ZipExecutionEvent{
+		&NodeExecutingEvent{ZipEventBase: ZipEventBase{Timestamp: "2024-01-01T00:00:00Z"}, Type: "node.executing"},
+		&NodeCompletedEvent{ZipEventBase: ZipEventBase{Timestamp: "2024-01-01T01:00:00Z"}, Type: "node.completed"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := &recordingReplayHandler{}
+	go cancel()
+
+	err := ReplayExecution(ctx, events, handler, ReplayOptions{PlaybackSpeed: 1})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFetchFailedNode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/zip/orchestrator/workflows/wf-1/state" || r.URL.Query().Get("graphId") != "main" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"workflowId":"wf-1","state":{"nodes":[{"id":"n1"},{"id":"n2","position":{"x":10,"y":20}}]}}`))
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	client, _ := NewClient(config)
+	api := client.Orchestrator()
+
+	nodeID := "n2"
+	failed := &ExecutionFailedEvent{
+		ZipEventBase: ZipEventBase{WorkflowID: "wf-1"},
+		Type:         "execution.failed",
+		Error:        &ExecutionError{Message: "boom", NodeID: &nodeID},
+	}
+	node, err := failed.FetchFailedNode(context.Background(), api)
+	if err != nil {
+		t.Fatalf("FetchFailedNode failed: %v", err)
+	}
+	if node.ID != "n2" || node.Position.X != 10 {
+		t.Errorf("Unexpected node %+v", node)
+	}
+
+	failed.Error.NodeID = nil
+	if _, err := failed.FetchFailedNode(context.Background(), api); !errors.Is(err, ErrNoFailedNode) {
+		t.Errorf("Expected ErrNoFailedNode, got %v", err)
+	}
+
+	nodeFailed := &NodeFailedEvent{ZipEventBase: ZipEventBase{WorkflowID: "wf-1"}, NodeID: "n2"}
+	if node, err := nodeFailed.FetchNode(context.Background(), api); err != nil || node.ID != "n2" {
+		t.Errorf("FetchNode returned %+v (%v)", node, err)
+	}
+
+	nodeFailed.NodeID = "missing"
+	if _, err := nodeFailed.FetchNode(context.Background(), api); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown node, got %v", err)
+	}
+}
+
+func TestCreateSessionForExecution(t *testing.T) {
+	var received CreateTraceSessionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"sessionId":"trace-1","workflowId":"wf-1","executionId":"exec-1"}`))
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	client, _ := NewClient(config)
+
+	event := &ExecutionStartedEvent{
+		ZipEventBase: ZipEventBase{WorkflowID: "wf-1"},
+		Type:         "execution.started",
+		SessionID:    "exec-1",
+		WorkflowName: "Pipeline",
+		Trigger:      &ExecutionTrigger{Type: ManualTrigger},
+	}
+
+	req := event.ToTraceSessionRequest()
+	if req.WorkflowID != "wf-1" || req.ExecutionID != "exec-1" || req.Metadata["workflowName"] != "Pipeline" {
+		t.Errorf("Unexpected request %+v", req)
+	}
+
+	resp, err := client.Traces().CreateSessionForExecution(context.Background(), event)
+	if err != nil {
+		t.Fatalf("CreateSessionForExecution failed: %v", err)
+	}
+	if resp.SessionID != "trace-1" || received.ExecutionID != "exec-1" {
+		t.Errorf("Unexpected response %+v for request %+v", resp, received)
+	}
+
+	if _, err := client.Traces().CreateSessionForExecution(context.Background(), &ExecutionStartedEvent{SessionID: "exec-2"}); err == nil {
+		t.Error("Expected error for event without workflow ID")
+	}
+}
@@ -0,0 +1,58 @@
+package zeal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrEmptyGroup is returned when computing the bounding box of a group with no nodes
+var ErrEmptyGroup = errors.New("group has no nodes")
+
+// BoundingBox is an axis-aligned rectangle in canvas coordinates
+type BoundingBox struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ComputeGroupBoundingBox returns the smallest box containing the positions
+// of nodeIDs, grown by padding on every side
+func ComputeGroupBoundingBox(nodePositions map[string]Position, nodeIDs []string, padding float64) (*BoundingBox, error) {
+	if len(nodeIDs) == 0 {
+		return nil, ErrEmptyGroup
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, id := range nodeIDs {
+		pos, ok := nodePositions[id]
+		if !ok {
+			return nil, fmt.Errorf("no position for node %s", id)
+		}
+		minX = math.Min(minX, pos.X)
+		minY = math.Min(minY, pos.Y)
+		maxX = math.Max(maxX, pos.X)
+		maxY = math.Max(maxY, pos.Y)
+	}
+
+	return &BoundingBox{
+		X:      minX - padding,
+		Y:      minY - padding,
+		Width:  maxX - minX + 2*padding,
+		Height: maxY - minY + 2*padding,
+	}, nil
+}
+
+// Contains reports whether pos lies inside the box, edges included
+func (b BoundingBox) Contains(pos Position) bool {
+	return pos.X >= b.X && pos.X <= b.X+b.Width &&
+		pos.Y >= b.Y && pos.Y <= b.Y+b.Height
+}
+
+// Intersects reports whether the two boxes overlap, edges included
+func (b BoundingBox) Intersects(other BoundingBox) bool {
+	return b.X <= other.X+other.Width && other.X <= b.X+b.Width &&
+		b.Y <= other.Y+other.Height && other.Y <= b.Y+b.Height
+}
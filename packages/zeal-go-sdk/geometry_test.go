@@ -0,0 +1,48 @@
+package zeal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestComputeGroupBoundingBox(t *testing.T) {
+	positions := map[string]Position{
+		"n1": {X: 10, Y: 20},
+		"n2": {X: 110, Y: 60},
+		"n3": {X: 500, Y: 500},
+	}
+
+	box, err := ComputeGroupBoundingBox(positions, []string{"n1", "n2"}, 5)
+	if err != nil {
+		t.Fatalf("ComputeGroupBoundingBox failed: %v", err)
+	}
+	want := BoundingBox{X: 5, Y: 15, Width: 110, Height: 50}
+	if *box != want {
+		t.Errorf("Expected %+v, got %+v", want, *box)
+	}
+
+	if _, err := ComputeGroupBoundingBox(positions, nil, 5); !errors.Is(err, ErrEmptyGroup) {
+		t.Errorf("Expected ErrEmptyGroup, got %v", err)
+	}
+	if _, err := ComputeGroupBoundingBox(positions, []string{"missing"}, 0); err == nil {
+		t.Error("Expected error for node without position")
+	}
+}
+
+func TestBoundingBoxHitTesting(t *testing.T) {
+	box := BoundingBox{X: 0, Y: 0, Width: 100, Height: 50}
+
+	if !box.Contains(Position{X: 100, Y: 50}) || !box.Contains(Position{X: 40, Y: 10}) {
+		t.Error("Expected points inside box to be contained")
+	}
+	if box.Contains(Position{X: 101, Y: 10}) {
+		t.Error("Expected point outside box not to be contained")
+	}
+
+	if !box.Intersects(BoundingBox{X: 90, Y: 40, Width: 20, Height: 20}) {
+		t.Error("Expected overlapping boxes to intersect")
+	}
+	if box.Intersects(BoundingBox{X: 200, Y: 0, Width: 10, Height: 10}) {
+		t.Error("Expected disjoint boxes not to intersect")
+	}
+}
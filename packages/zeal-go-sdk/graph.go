@@ -0,0 +1,306 @@
+package zeal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrPortAtCapacity is returned when connecting a port that only allows one connection
+	ErrPortAtCapacity = errors.New("port already has a connection")
+	// ErrPortNotCompatible is returned when connecting ports with incompatible data types
+	ErrPortNotCompatible = errors.New("port data types are not compatible")
+	// ErrCycleDetected is returned when a workflow graph is not a DAG
+	ErrCycleDetected = errors.New("cycle detected in workflow graph")
+)
+
+// CycleError reports the node IDs forming a cycle in a workflow graph.
+// It matches ErrCycleDetected with errors.Is.
+type CycleError struct {
+	NodeIDs []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCycleDetected.Error(), strings.Join(e.NodeIDs, " -> "))
+}
+
+func (e *CycleError) Unwrap() error {
+	return ErrCycleDetected
+}
+
+// DecodeState decodes State into dst. The JSON encoding of State is cached,
+// so State must not be modified after the first call. Not safe for
+// concurrent use.
+func (ws *WorkflowState) DecodeState(dst interface{}) error {
+	if ws.stateJSON == nil {
+		data, err := json.Marshal(ws.State)
+		if err != nil {
+			return fmt.Errorf("failed to encode workflow state: %w", err)
+		}
+		ws.stateJSON = data
+	}
+	if err := json.Unmarshal(ws.stateJSON, dst); err != nil {
+		return fmt.Errorf("failed to decode workflow state: %w", err)
+	}
+	return nil
+}
+
+// DecodeGraphData decodes State into its nodes, connections and groups
+func (ws *WorkflowState) DecodeGraphData() (*WorkflowStateData, error) {
+	var data WorkflowStateData
+	if err := ws.DecodeState(&data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// decodeWorkflowGraph decodes the untyped WorkflowState.State into a
+// WorkflowGraph. It does not use the DecodeState cache, so the read-only
+// graph helpers never modify the caller's state and always see its current
+// contents.
+func decodeWorkflowGraph(state *WorkflowState) (*WorkflowGraph, error) {
+	if state == nil || state.State == nil {
+		return &WorkflowGraph{}, nil
+	}
+	data, err := json.Marshal(state.State)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode workflow state: %w", err)
+	}
+	var graph WorkflowGraph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow state: %w", err)
+	}
+	return &graph, nil
+}
+
+// graphOrEmpty decodes state like decodeWorkflowGraph, treating a state that
+// cannot be decoded as an empty graph
+func graphOrEmpty(state *WorkflowState) *WorkflowGraph {
+	graph, err := decodeWorkflowGraph(state)
+	if err != nil {
+		return &WorkflowGraph{}
+	}
+	return graph
+}
+
+// FindConnectedComponents groups node IDs into connected components, treating
+// connections as undirected. Components and their members follow node order.
+// A state that cannot be decoded is treated as an empty graph; use
+// DecodeGraphData to detect malformed state.
+func FindConnectedComponents(state *WorkflowState) [][]string {
+	graph := graphOrEmpty(state)
+
+	neighbors := make(map[string][]string, len(graph.Nodes))
+	for _, conn := range graph.Connections {
+		neighbors[conn.Source.NodeID] = append(neighbors[conn.Source.NodeID], conn.Target.NodeID)
+		neighbors[conn.Target.NodeID] = append(neighbors[conn.Target.NodeID], conn.Source.NodeID)
+	}
+
+	index := make(map[string]int, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		index[node.ID] = i
+	}
+
+	visited := make(map[string]bool, len(graph.Nodes))
+	components := make([][]string, 0)
+	for _, node := range graph.Nodes {
+		if visited[node.ID] {
+			continue
+		}
+
+		members := make([]bool, len(graph.Nodes))
+		stack := []string{node.ID}
+		visited[node.ID] = true
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if i, ok := index[id]; ok {
+				members[i] = true
+			}
+			for _, next := range neighbors[id] {
+				if !visited[next] {
+					visited[next] = true
+					stack = append(stack, next)
+				}
+			}
+		}
+
+		component := make([]string, 0)
+		for i, member := range members {
+			if member {
+				component = append(component, graph.Nodes[i].ID)
+			}
+		}
+		components = append(components, component)
+	}
+
+	return components
+}
+
+// FindRootNodes returns the nodes with no incoming connections, which are the
+// execution start points of the graph. A state that cannot be decoded is
+// treated as an empty graph.
+func FindRootNodes(state *WorkflowState) []string {
+	graph := graphOrEmpty(state)
+
+	hasIncoming := make(map[string]bool)
+	for _, conn := range graph.Connections {
+		hasIncoming[conn.Target.NodeID] = true
+	}
+
+	roots := make([]string, 0)
+	for _, node := range graph.Nodes {
+		if !hasIncoming[node.ID] {
+			roots = append(roots, node.ID)
+		}
+	}
+	return roots
+}
+
+// ValidateConnection checks that a connection from source to target respects
+// port multiplicity and data type compatibility. Ports are matched by ID
+// only, so existingConnections should hold just the connections leaving the
+// source node and those entering the target node.
+func ValidateConnection(source, target Port, existingConnections []ConnectionDetail) error {
+	for _, conn := range existingConnections {
+		if source.Multiple != nil && !*source.Multiple && conn.Source.PortID == source.ID {
+			return fmt.Errorf("%w: source port %s", ErrPortAtCapacity, source.ID)
+		}
+		if target.Multiple != nil && !*target.Multiple && conn.Target.PortID == target.ID {
+			return fmt.Errorf("%w: target port %s", ErrPortAtCapacity, target.ID)
+		}
+	}
+
+	if !dataTypesCompatible(source.DataType, target.DataType) {
+		return fmt.Errorf("%w: %s -> %s", ErrPortNotCompatible, *source.DataType, *target.DataType)
+	}
+
+	return nil
+}
+
+// dataTypesCompatible treats unset, empty and "any" data types as wildcards
+func dataTypesCompatible(source, target *string) bool {
+	isWildcard := func(dataType *string) bool {
+		return dataType == nil || *dataType == "" || *dataType == "any" || *dataType == "*"
+	}
+	if isWildcard(source) || isWildcard(target) {
+		return true
+	}
+	return *source == *target
+}
+
+// TopologicalSort returns node IDs in an order where every node comes after
+// the nodes feeding into it. Returns a *CycleError if the graph has a cycle.
+func TopologicalSort(state *WorkflowState) ([]string, error) {
+	layers, err := ParallelExecutionGroups(state)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]string, 0)
+	for _, layer := range layers {
+		order = append(order, layer...)
+	}
+	return order, nil
+}
+
+// ParallelExecutionGroups groups nodes into topological layers using Kahn's
+// algorithm. Nodes in the same layer have no dependencies on each other and
+// can run concurrently; each layer depends only on earlier layers.
+func ParallelExecutionGroups(state *WorkflowState) ([][]string, error) {
+	graph, err := decodeWorkflowGraph(state)
+	if err != nil {
+		return nil, err
+	}
+
+	inDegree := make(map[string]int, len(graph.Nodes))
+	successors := make(map[string][]string, len(graph.Nodes))
+	predecessors := make(map[string][]string, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		inDegree[node.ID] = 0
+	}
+	for _, conn := range graph.Connections {
+		from, to := conn.Source.NodeID, conn.Target.NodeID
+		if _, ok := inDegree[from]; !ok {
+			continue
+		}
+		if _, ok := inDegree[to]; !ok {
+			continue
+		}
+		successors[from] = append(successors[from], to)
+		predecessors[to] = append(predecessors[to], from)
+		inDegree[to]++
+	}
+
+	layers := make([][]string, 0)
+	current := make([]string, 0)
+	for _, node := range graph.Nodes {
+		if inDegree[node.ID] == 0 {
+			current = append(current, node.ID)
+		}
+	}
+
+	visited := 0
+	for len(current) > 0 {
+		layers = append(layers, current)
+		visited += len(current)
+
+		ready := make(map[string]bool)
+		for _, id := range current {
+			for _, next := range successors[id] {
+				inDegree[next]--
+				if inDegree[next] == 0 {
+					ready[next] = true
+				}
+			}
+		}
+
+		// Keep node order within each layer
+		next := make([]string, 0, len(ready))
+		for _, node := range graph.Nodes {
+			if ready[node.ID] {
+				next = append(next, node.ID)
+			}
+		}
+		current = next
+	}
+
+	if visited < len(graph.Nodes) {
+		return nil, &CycleError{NodeIDs: findCycle(graph.Nodes, inDegree, predecessors)}
+	}
+	return layers, nil
+}
+
+// findCycle walks predecessors among the nodes Kahn's algorithm could not
+// schedule until a node repeats, then returns that cycle in edge order
+func findCycle(nodes []NodeDetail, inDegree map[string]int, predecessors map[string][]string) []string {
+	var start string
+	for _, node := range nodes {
+		if inDegree[node.ID] > 0 {
+			start = node.ID
+			break
+		}
+	}
+
+	position := make(map[string]int)
+	path := make([]string, 0)
+	for id := start; ; {
+		if i, seen := position[id]; seen {
+			cycle := path[i:]
+			for l, r := 0, len(cycle)-1; l < r; l, r = l+1, r-1 {
+				cycle[l], cycle[r] = cycle[r], cycle[l]
+			}
+			return cycle
+		}
+		position[id] = len(path)
+		path = append(path, id)
+
+		for _, prev := range predecessors[id] {
+			if inDegree[prev] > 0 {
+				id = prev
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package zeal
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testWorkflowState(nodes []string, edges [][2]string) *WorkflowState {
+	nodeList := make([]interface{}, 0, len(nodes))
+	for _, id := range nodes {
+		nodeList = append(nodeList, map[string]interface{}{"id": id})
+	}
+	connections := make([]interface{}, 0, len(edges))
+	for i, edge := range edges {
+		connections = append(connections, map[string]interface{}{
+			"id":     "conn-" + string(rune('a'+i)),
+			"source": map[string]interface{}{"nodeId": edge[0], "portId": "out"},
+			"target": map[string]interface{}{"nodeId": edge[1], "portId": "in"},
+		})
+	}
+	return &WorkflowState{
+		WorkflowID: "wf-1",
+		State: map[string]interface{}{
+			"nodes":       nodeList,
+			"connections": connections,
+		},
+	}
+}
+
+func TestFindConnectedComponents(t *testing.T) {
+	state := testWorkflowState(
+		[]string{"a", "b", "c", "d", "e"},
+		[][2]string{{"a", "b"}, {"c", "b"}, {"d", "e"}},
+	)
+
+	components := FindConnectedComponents(state)
+	expected := [][]string{{"a", "b", "c"}, {"d", "e"}}
+	if !reflect.DeepEqual(components, expected) {
+		t.Errorf("Expected %v, got %v", expected, components)
+	}
+}
+
+func TestFindRootNodes(t *testing.T) {
+	state := testWorkflowState(
+		[]string{"a", "b", "c", "d"},
+		[][2]string{{"a", "b"}, {"c", "b"}, {"b", "d"}},
+	)
+
+	roots := FindRootNodes(state)
+	expected := []string{"a", "c"}
+	if !reflect.DeepEqual(roots, expected) {
+		t.Errorf("Expected %v, got %v", expected, roots)
+	}
+}
+
+func TestGraphHelpersTreatUndecodableStateAsEmpty(t *testing.T) {
+	state := &WorkflowState{State: map[string]interface{}{"nodes": "not-a-list"}}
+
+	if components := FindConnectedComponents(state); len(components) != 0 {
+		t.Errorf("Expected no components, got %v", components)
+	}
+	if roots := FindRootNodes(state); len(roots) != 0 {
+		t.Errorf("Expected no roots, got %v", roots)
+	}
+	if _, err := state.DecodeGraphData(); err == nil {
+		t.Error("Expected DecodeGraphData to report the decode error")
+	}
+}
+
+func TestValidateConnection(t *testing.T) {
+	single := false
+	jsonType, textType := "json", "text"
+	source := Port{ID: "out", Multiple: &single, DataType: &jsonType}
+	target := Port{ID: "in", DataType: &jsonType}
+
+	if err := ValidateConnection(source, target, nil); err != nil {
+		t.Errorf("Expected valid connection, got %v", err)
+	}
+
+	existing := []ConnectionDetail{{ID: "c1", Source: NodePort{NodeID: "a", PortID: "out"}}}
+	if err := ValidateConnection(source, target, existing); !errors.Is(err, ErrPortAtCapacity) {
+		t.Errorf("Expected ErrPortAtCapacity, got %v", err)
+	}
+
+	target.DataType = &textType
+	if err := ValidateConnection(source, target, nil); !errors.Is(err, ErrPortNotCompatible) {
+		t.Errorf("Expected ErrPortNotCompatible, got %v", err)
+	}
+}
+
+func TestParallelExecutionGroups(t *testing.T) {
+	state := testWorkflowState(
+		[]string{"a", "b", "c", "d"},
+		[][2]string{{"a", "c"}, {"b", "c"}, {"c", "d"}, {"a", "d"}},
+	)
+
+	groups, err := ParallelExecutionGroups(state)
+	if err != nil {
+		t.Fatalf("ParallelExecutionGroups failed: %v", err)
+	}
+	expected := [][]string{{"a", "b"}, {"c"}, {"d"}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected %v, got %v", expected, groups)
+	}
+
+	order, err := TopologicalSort(state)
+	if err != nil {
+		t.Fatalf("TopologicalSort failed: %v", err)
+	}
+	if !reflect.DeepEqual(order, []string{"a", "b", "c", "d"}) {
+		t.Errorf("Unexpected order %v", order)
+	}
+}
+
+func TestTopologicalSortCycle(t *testing.T) {
+	state := testWorkflowState(
+		[]string{"start", "x", "y", "z", "end"},
+		[][2]string{{"start", "x"}, {"x", "y"}, {"y", "z"}, {"z", "x"}, {"z", "end"}},
+	)
+
+	_, err := TopologicalSort(state)
+	if !errors.Is(err, ErrCycleDetected) {
+		t.Fatalf("Expected ErrCycleDetected, got %v", err)
+	}
+
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("Expected *CycleError, got %T", err)
+	}
+	if len(cycleErr.NodeIDs) != 3 {
+		t.Errorf("Expected 3 nodes in cycle, got %v", cycleErr.NodeIDs)
+	}
+	for _, id := range cycleErr.NodeIDs {
+		if id != "x" && id != "y" && id != "z" {
+			t.Errorf("Unexpected node %s in cycle %v", id, cycleErr.NodeIDs)
+		}
+	}
+}
+
+func TestWorkflowStateDecodeGraphData(t *testing.T) {
+	state := testWorkflowState([]string{"a", "b"}, [][2]string{{"a", "b"}})
+
+	data, err := state.DecodeGraphData()
+	if err != nil {
+		t.Fatalf("DecodeGraphData failed: %v", err)
+	}
+	if len(data.Nodes) != 2 || len(data.Connections) != 1 || data.Nodes[1]["id"] != "b" {
+		t.Errorf("Unexpected graph data: %+v", data)
+	}
+}
+
+func TestGraphHelpersDoNotCacheState(t *testing.T) {
+	state := testWorkflowState([]string{"a", "b"}, [][2]string{{"a", "b"}})
+	if roots := FindRootNodes(state); !reflect.DeepEqual(roots, []string{"a"}) {
+		t.Fatalf("Expected root a, got %v", roots)
+	}
+	if state.stateJSON != nil {
+		t.Error("Expected graph helpers to leave the state unmodified")
+	}
+
+	state.State = testWorkflowState([]string{"c"}, nil).State
+	if roots := FindRootNodes(state); !reflect.DeepEqual(roots, []string{"c"}) {
+		t.Errorf("Expected graph helpers to see the updated state, got %v", roots)
+	}
+}
+
+func TestGraphIDHelpers(t *testing.T) {
+	if ParseGraphID("  ") != MainGraphID {
+		t.Error("Expected blank graph ID to parse as main")
+	}
+	if ParseGraphID(" sub-1 ") != "sub-1" {
+		t.Error("Expected graph ID to be trimmed")
+	}
+	if !GraphID("").IsMain() || !MainGraphID.IsMain() || GraphID("sub-1").IsMain() {
+		t.Error("IsMain returned wrong result")
+	}
+	if p := GraphID("").Ptr(); p == nil || *p != "main" {
+		t.Errorf("Expected zero value Ptr to be main, got %v", p)
+	}
+	if p := GraphID("sub-1").Ptr(); *p != "sub-1" {
+		t.Errorf("Expected sub-1, got %s", *p)
+	}
+
+	var state WorkflowState
+	if err := json.Unmarshal([]byte(`{"graphId":"sub-1"}`), &state); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if state.GraphID != "sub-1" {
+		t.Errorf("Expected graph ID sub-1, got %s", state.GraphID)
+	}
+}
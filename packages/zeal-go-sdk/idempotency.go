@@ -0,0 +1,94 @@
+package zeal
+
+import (
+	"container/list"
+	"sync"
+)
+
+// IdempotencyStore records processed webhook delivery IDs so redelivered
+// payloads can be skipped
+type IdempotencyStore interface {
+	Has(deliveryID string) bool
+	Mark(deliveryID string)
+}
+
+// memoryIdempotencyStore is a bounded LRU set of delivery IDs
+type memoryIdempotencyStore struct {
+	maxSize int
+	order   *list.List
+	entries map[string]*list.Element
+	mu      sync.Mutex
+}
+
+// InMemoryIdempotencyStore returns an IdempotencyStore that remembers the
+// maxSize most recently seen delivery IDs. It has no TTL: an ID is forgotten
+// only once maxSize newer IDs have been marked, so maxSize should cover the
+// server's redelivery window at peak delivery rate. The store is local to the
+// process; use a shared store when several instances receive webhooks.
+func InMemoryIdempotencyStore(maxSize int) IdempotencyStore {
+	if maxSize <= 0 {
+		maxSize = 10000
+	}
+	return &memoryIdempotencyStore{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (s *memoryIdempotencyStore) Has(deliveryID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[deliveryID]
+	if ok {
+		s.order.MoveToFront(elem)
+	}
+	return ok
+}
+
+func (s *memoryIdempotencyStore) Mark(deliveryID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[deliveryID]; ok {
+		s.order.MoveToFront(elem)
+		return
+	}
+	s.entries[deliveryID] = s.order.PushFront(deliveryID)
+	for s.order.Len() > s.maxSize {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(string))
+	}
+}
+
+// WithIdempotencyStore skips deliveries whose Metadata.DeliveryID has already
+// been marked in store. A delivery is marked before it is processed, so a
+// delivery whose callbacks fail is not retried on redelivery. Deliveries
+// without an ID are always processed.
+func (ws *WebhookSubscriptionManager) WithIdempotencyStore(store IdempotencyStore) *WebhookSubscriptionManager {
+	ws.mu.Lock()
+	ws.idempotencyStore = store
+	ws.mu.Unlock()
+	return ws
+}
+
+// isDuplicateDelivery reports whether deliveryID was already seen, marking it
+// as seen otherwise
+func (ws *WebhookSubscriptionManager) isDuplicateDelivery(deliveryID string) bool {
+	ws.mu.RLock()
+	store := ws.idempotencyStore
+	ws.mu.RUnlock()
+	if store == nil || deliveryID == "" {
+		return false
+	}
+
+	ws.idempotencyMu.Lock()
+	defer ws.idempotencyMu.Unlock()
+	if store.Has(deliveryID) {
+		return true
+	}
+	store.Mark(deliveryID)
+	return false
+}
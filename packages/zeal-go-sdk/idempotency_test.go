@@ -0,0 +1,51 @@
+package zeal
+
+import (
+	"context"
+	"testing"
+)
+
+func TestInMemoryIdempotencyStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := InMemoryIdempotencyStore(2)
+	store.Mark("d1")
+	store.Mark("d2")
+	store.Has("d1")
+	store.Mark("d3")
+
+	if !store.Has("d1") || !store.Has("d3") {
+		t.Error("Expected recently used IDs to be kept")
+	}
+	if store.Has("d2") {
+		t.Error("Expected least recently used ID to be evicted")
+	}
+}
+
+func TestWebhookSubscriptionSkipsDuplicateDeliveries(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+	subscription.WithIdempotencyStore(InMemoryIdempotencyStore(10))
+
+	count := 0
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		count++
+		return nil
+	})
+
+	delivery := WebhookDelivery{
+		Events:   []map[string]interface{}{{"type": "node.completed"}},
+		Metadata: WebhookMetadata{DeliveryID: "d1"},
+	}
+	subscription.DispatchDelivery(context.Background(), delivery)
+	subscription.DispatchDelivery(context.Background(), delivery)
+	if count != 1 {
+		t.Errorf("Expected duplicate delivery to be skipped, got %d events", count)
+	}
+
+	delivery.Metadata.DeliveryID = ""
+	subscription.DispatchDelivery(context.Background(), delivery)
+	subscription.DispatchDelivery(context.Background(), delivery)
+	if count != 3 {
+		t.Errorf("Expected deliveries without an ID to be processed, got %d events", count)
+	}
+}
@@ -0,0 +1,51 @@
+package zeal
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// JSONCodec encodes and decodes JSON. Implementations wrapping faster
+// libraries such as jsoniter or sonic can replace DefaultJSONCodec.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// DefaultJSONCodec is the JSONCodec backed by encoding/json
+type DefaultJSONCodec struct{}
+
+// Marshal implements JSONCodec
+func (DefaultJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements JSONCodec
+func (DefaultJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// jsonCodecSettings holds the codec used to decode webhook events
+var jsonCodecSettings = struct {
+	sync.RWMutex
+	codec JSONCodec
+}{codec: DefaultJSONCodec{}}
+
+// SetJSONCodec sets the codec ParseZipWebhookEvent and webhook subscriptions
+// use to decode events. A nil codec restores DefaultJSONCodec. API requests
+// use ClientConfig.JSONCodec instead.
+func SetJSONCodec(codec JSONCodec) {
+	if codec == nil {
+		codec = DefaultJSONCodec{}
+	}
+	jsonCodecSettings.Lock()
+	defer jsonCodecSettings.Unlock()
+	jsonCodecSettings.codec = codec
+}
+
+// eventJSONCodec returns the codec set with SetJSONCodec
+func eventJSONCodec() JSONCodec {
+	jsonCodecSettings.RLock()
+	defer jsonCodecSettings.RUnlock()
+	return jsonCodecSettings.codec
+}
@@ -0,0 +1,68 @@
+package zeal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// countingCodec wraps DefaultJSONCodec and counts calls
+type countingCodec struct {
+	DefaultJSONCodec
+	marshals   int32
+	unmarshals int32
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt32(&c.marshals, 1)
+	return c.DefaultJSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt32(&c.unmarshals, 1)
+	return c.DefaultJSONCodec.Unmarshal(data, v)
+}
+
+func TestClientUsesConfiguredJSONCodec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"workflowId":"wf-1","graphId":"main","version":1}`))
+	}))
+	defer server.Close()
+
+	codec := &countingCodec{}
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL, JSONCodec: codec})
+	resp, err := client.Orchestrator().CreateWorkflow(context.Background(), CreateWorkflowRequest{Name: "codec"})
+	if err != nil {
+		t.Fatalf("CreateWorkflow failed: %v", err)
+	}
+	if resp.WorkflowID != "wf-1" {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+	if codec.marshals != 1 || codec.unmarshals != 1 {
+		t.Errorf("Expected one marshal and one unmarshal, got %d and %d", codec.marshals, codec.unmarshals)
+	}
+}
+
+func TestParseZipWebhookEventUsesJSONCodec(t *testing.T) {
+	codec := &countingCodec{}
+	SetJSONCodec(codec)
+	defer SetJSONCodec(nil)
+
+	event, err := ParseZipWebhookEvent([]byte(`{"type":"node.added","workflowId":"wf-1"}`))
+	if err != nil {
+		t.Fatalf("ParseZipWebhookEvent failed: %v", err)
+	}
+	if event.GetWorkflowID() != "wf-1" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if codec.unmarshals != 2 {
+		t.Errorf("Expected the type and the event to be decoded with the codec, got %d calls", codec.unmarshals)
+	}
+
+	SetJSONCodec(nil)
+	if _, ok := eventJSONCodec().(DefaultJSONCodec); !ok {
+		t.Error("Expected SetJSONCodec(nil) to restore DefaultJSONCodec")
+	}
+}
@@ -0,0 +1,38 @@
+package zeal
+
+import (
+	"log"
+	"os"
+	"sync"
+)
+
+// Logger receives warnings from the SDK. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger = log.New(os.Stderr, "zeal: ", log.LstdFlags)
+)
+
+// SetLogger replaces the SDK logger. A nil logger discards all output.
+func SetLogger(l Logger) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	if l == nil {
+		l = discardLogger{}
+	}
+	logger = l
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Printf(string, ...interface{}) {}
+
+func logf(format string, v ...interface{}) {
+	loggerMu.RLock()
+	l := logger
+	loggerMu.RUnlock()
+	l.Printf(format, v...)
+}
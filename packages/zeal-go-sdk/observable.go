@@ -0,0 +1,246 @@
+package zeal
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// derive creates an empty observable sharing this observable's subscription
+func (wo *WebhookObservable) derive() *WebhookObservable {
+	return &WebhookObservable{
+		eventChan:    make(chan map[string]interface{}, wo.subscription.options.BufferSize),
+		errorChan:    make(chan error, 10),
+		completeChan: make(chan struct{}),
+		subscription: wo.subscription,
+	}
+}
+
+// Timeout creates an observable that errors with context.DeadlineExceeded and
+// completes if no event arrives within d
+func (wo *WebhookObservable) Timeout(d time.Duration) *WebhookObservable {
+	timed := wo.derive()
+
+	go func() {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		for {
+			select {
+			case event := <-wo.eventChan:
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(d)
+				timed.eventChan <- event
+			case err := <-wo.errorChan:
+				timed.errorChan <- err
+			case <-timer.C:
+				timed.errorChan <- fmt.Errorf("no events received within %s: %w", d, context.DeadlineExceeded)
+				close(timed.completeChan)
+				return
+			case <-wo.completeChan:
+				close(timed.completeChan)
+				return
+			}
+		}
+	}()
+
+	return timed
+}
+
+// Take creates an observable that delivers the first n events and then completes
+func (wo *WebhookObservable) Take(n int) *WebhookObservable {
+	taken := wo.derive()
+
+	go func() {
+		for count := 0; count < n; {
+			select {
+			case event := <-wo.eventChan:
+				taken.eventChan <- event
+				count++
+			case err := <-wo.errorChan:
+				taken.errorChan <- err
+			case <-wo.completeChan:
+				close(taken.completeChan)
+				return
+			}
+		}
+		close(taken.completeChan)
+	}()
+
+	return taken
+}
+
+// Skip creates an observable that discards the first n events
+func (wo *WebhookObservable) Skip(n int) *WebhookObservable {
+	count := 0
+	return wo.SkipWhile(func(map[string]interface{}) bool {
+		count++
+		return count <= n
+	})
+}
+
+// TakeWhile creates an observable that delivers events while fn returns true
+// and completes at the first event for which it returns false
+func (wo *WebhookObservable) TakeWhile(fn func(map[string]interface{}) bool) *WebhookObservable {
+	taken := wo.derive()
+
+	go func() {
+		for {
+			select {
+			case event := <-wo.eventChan:
+				if !fn(event) {
+					close(taken.completeChan)
+					return
+				}
+				taken.eventChan <- event
+			case err := <-wo.errorChan:
+				taken.errorChan <- err
+			case <-wo.completeChan:
+				close(taken.completeChan)
+				return
+			}
+		}
+	}()
+
+	return taken
+}
+
+// SkipWhile creates an observable that discards events while fn returns true
+// and delivers every event from the first one for which it returns false
+func (wo *WebhookObservable) SkipWhile(fn func(map[string]interface{}) bool) *WebhookObservable {
+	skipped := wo.derive()
+
+	go func() {
+		skipping := true
+		for {
+			select {
+			case event := <-wo.eventChan:
+				if skipping && fn(event) {
+					continue
+				}
+				skipping = false
+				skipped.eventChan <- event
+			case err := <-wo.errorChan:
+				skipped.errorChan <- err
+			case <-wo.completeChan:
+				close(skipped.completeChan)
+				return
+			}
+		}
+	}()
+
+	return skipped
+}
+
+// Distinct creates an observable that drops events whose key, computed by
+// keyFn, matches one of the last windowSize keys delivered. A windowSize of
+// zero or less is treated as 1.
+func (wo *WebhookObservable) Distinct(keyFn func(map[string]interface{}) string, windowSize int) *WebhookObservable {
+	distinct := wo.derive()
+	window := newKeyWindow(windowSize)
+
+	go func() {
+		for {
+			select {
+			case event := <-wo.eventChan:
+				if !window.add(keyFn(event)) {
+					continue
+				}
+				distinct.eventChan <- event
+			case err := <-wo.errorChan:
+				distinct.errorChan <- err
+			case <-wo.completeChan:
+				close(distinct.completeChan)
+				return
+			}
+		}
+	}()
+
+	return distinct
+}
+
+// keyWindow remembers the most recent keys in a circular buffer
+type keyWindow struct {
+	keys []string
+	seen map[string]bool
+	next int
+	full bool
+}
+
+func newKeyWindow(size int) *keyWindow {
+	if size <= 0 {
+		size = 1
+	}
+	return &keyWindow{keys: make([]string, size), seen: make(map[string]bool, size)}
+}
+
+// add records key and reports whether it was not already in the window
+func (w *keyWindow) add(key string) bool {
+	if w.seen[key] {
+		return false
+	}
+	if w.full {
+		delete(w.seen, w.keys[w.next])
+	}
+	w.keys[w.next] = key
+	w.seen[key] = true
+	w.next = (w.next + 1) % len(w.keys)
+	w.full = w.full || w.next == 0
+	return true
+}
+
+// Do creates an observable that calls fn with each event as a side effect,
+// for logging or metrics, and passes the event on unchanged. An error from
+// fn is sent downstream as an error; the event is still delivered.
+func (wo *WebhookObservable) Do(fn func(map[string]interface{}) error) *WebhookObservable {
+	tapped := wo.derive()
+
+	go func() {
+		for {
+			select {
+			case event := <-wo.eventChan:
+				if err := fn(event); err != nil {
+					tapped.errorChan <- err
+				}
+				tapped.eventChan <- event
+			case err := <-wo.errorChan:
+				tapped.errorChan <- err
+			case <-wo.completeChan:
+				close(tapped.completeChan)
+				return
+			}
+		}
+	}()
+
+	return tapped
+}
+
+// DoOnEvent is an alias for Do
+func (wo *WebhookObservable) DoOnEvent(fn func(map[string]interface{}) error) *WebhookObservable {
+	return wo.Do(fn)
+}
+
+// DoOnError creates an observable that calls fn with each error as a side
+// effect and passes the error on unchanged
+func (wo *WebhookObservable) DoOnError(fn func(error)) *WebhookObservable {
+	tapped := wo.derive()
+
+	go func() {
+		for {
+			select {
+			case event := <-wo.eventChan:
+				tapped.eventChan <- event
+			case err := <-wo.errorChan:
+				fn(err)
+				tapped.errorChan <- err
+			case <-wo.completeChan:
+				close(tapped.completeChan)
+				return
+			}
+		}
+	}()
+
+	return tapped
+}
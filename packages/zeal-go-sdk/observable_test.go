@@ -0,0 +1,172 @@
+package zeal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestObservable() *WebhookObservable {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	return NewWebhookSubscription(mockWebhooksAPI, nil).AsObservable()
+}
+
+func TestObservableTimeout(t *testing.T) {
+	observable := newTestObservable()
+	timed := observable.Timeout(20 * time.Millisecond)
+
+	observable.eventChan <- map[string]interface{}{"type": "node.completed"}
+
+	select {
+	case <-timed.eventChan:
+	case <-time.After(time.Second):
+		t.Fatal("Expected event to be forwarded")
+	}
+
+	select {
+	case err := <-timed.errorChan:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected DeadlineExceeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected timeout error")
+	}
+
+	select {
+	case <-timed.completeChan:
+	case <-time.After(time.Second):
+		t.Fatal("Expected observable to complete after timeout")
+	}
+}
+
+func TestObservableTakeAndSkip(t *testing.T) {
+	observable := newTestObservable()
+	window := observable.Skip(2).Take(2)
+
+	for i := 0; i < 6; i++ {
+		observable.eventChan <- map[string]interface{}{"seq": i}
+	}
+
+	var received []int
+	for done := false; !done; {
+		select {
+		case event := <-window.eventChan:
+			received = append(received, event["seq"].(int))
+		case <-window.completeChan:
+			// Drain events buffered before completion
+			for len(window.eventChan) > 0 {
+				received = append(received, (<-window.eventChan)["seq"].(int))
+			}
+			done = true
+		case <-time.After(time.Second):
+			t.Fatal("Expected Take to complete")
+		}
+	}
+
+	if len(received) != 2 || received[0] != 2 || received[1] != 3 {
+		t.Errorf("Expected events [2 3], got %v", received)
+	}
+}
+
+func TestObservableTakeWhilePropagatesCompletion(t *testing.T) {
+	observable := newTestObservable()
+	taken := observable.TakeWhile(func(map[string]interface{}) bool { return true })
+
+	close(observable.completeChan)
+
+	select {
+	case <-taken.completeChan:
+	case <-time.After(time.Second):
+		t.Fatal("Expected upstream completion to propagate")
+	}
+}
+
+func TestObservableSubscribeContextCancel(t *testing.T) {
+	observable := newTestObservable()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	completed := make(chan struct{})
+	observable.Subscribe(ctx, func(context.Context, map[string]interface{}) error { return nil }, nil, func() {
+		close(completed)
+	})
+
+	cancel()
+
+	select {
+	case <-completed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected context cancellation to complete the subscription")
+	}
+}
+
+func TestObservableDistinct(t *testing.T) {
+	observable := newTestObservable()
+	nodeKey := func(event map[string]interface{}) string {
+		return fmt.Sprint(event["workflowId"], "/", event["nodeId"])
+	}
+	distinct := observable.Distinct(nodeKey, 2)
+
+	for _, node := range []string{"a", "a", "b", "a", "c", "a", "b"} {
+		observable.eventChan <- map[string]interface{}{"workflowId": "wf-1", "nodeId": node}
+	}
+
+	var got []string
+	for i := 0; i < 5; i++ {
+		select {
+		case event := <-distinct.eventChan:
+			got = append(got, event["nodeId"].(string))
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 5 distinct events, got %v", got)
+		}
+	}
+	// Repeats are dropped only while the key is among the last 2 delivered
+	if want := "a,b,c,a,b"; strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %v", want, got)
+	}
+	select {
+	case event := <-distinct.eventChan:
+		t.Errorf("Unexpected extra event %v", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestObservableDo(t *testing.T) {
+	observable := newTestObservable()
+	var seen, errorsSeen int
+	tapped := observable.Do(func(event map[string]interface{}) error {
+		seen++
+		if event["type"] == "node.failed" {
+			return errors.New("metrics backend down")
+		}
+		return nil
+	}).DoOnError(func(error) { errorsSeen++ })
+
+	observable.eventChan <- map[string]interface{}{"type": "node.completed"}
+	observable.eventChan <- map[string]interface{}{"type": "node.failed"}
+
+	for _, want := range []string{"node.completed", "node.failed"} {
+		select {
+		case event := <-tapped.eventChan:
+			if event["type"] != want {
+				t.Errorf("Expected %s, got %v", want, event["type"])
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %s to be passed on", want)
+		}
+	}
+	select {
+	case err := <-tapped.errorChan:
+		if err.Error() != "metrics backend down" {
+			t.Errorf("Unexpected error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected error from fn to be sent downstream")
+	}
+	if seen != 2 || errorsSeen != 1 {
+		t.Errorf("Expected 2 events and 1 error seen, got %d and %d", seen, errorsSeen)
+	}
+}
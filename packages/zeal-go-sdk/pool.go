@@ -0,0 +1,52 @@
+package zeal
+
+import "sync"
+
+// TraceEventPool recycles TraceEvent values to reduce allocations under high
+// trace throughput. Events are cleared when returned.
+type TraceEventPool struct {
+	pool sync.Pool
+}
+
+// NewTraceEventPool creates an empty trace event pool
+func NewTraceEventPool() *TraceEventPool {
+	return &TraceEventPool{pool: sync.Pool{New: func() interface{} { return new(TraceEvent) }}}
+}
+
+// Get returns a zeroed TraceEvent from the pool
+func (p *TraceEventPool) Get() *TraceEvent {
+	return p.pool.Get().(*TraceEvent)
+}
+
+// Put clears the event and returns it to the pool. The event must not be used afterwards.
+func (p *TraceEventPool) Put(event *TraceEvent) {
+	*event = TraceEvent{}
+	p.pool.Put(event)
+}
+
+// DeliveryPool recycles WebhookDelivery values decoded by the webhook server.
+// Deliveries are cleared when returned.
+type DeliveryPool struct {
+	pool sync.Pool
+}
+
+// NewDeliveryPool creates an empty delivery pool
+func NewDeliveryPool() *DeliveryPool {
+	return &DeliveryPool{pool: sync.Pool{New: func() interface{} { return new(WebhookDelivery) }}}
+}
+
+// Get returns a zeroed WebhookDelivery from the pool
+func (p *DeliveryPool) Get() *WebhookDelivery {
+	return p.pool.Get().(*WebhookDelivery)
+}
+
+// Put clears the delivery and returns it to the pool. The delivery must not be used afterwards.
+func (p *DeliveryPool) Put(delivery *WebhookDelivery) {
+	*delivery = WebhookDelivery{}
+	p.pool.Put(delivery)
+}
+
+var (
+	traceEventPool = NewTraceEventPool()
+	deliveryPool   = NewDeliveryPool()
+)
@@ -0,0 +1,54 @@
+package zeal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDeliveryPoolClearsDeliveries(t *testing.T) {
+	pool := NewDeliveryPool()
+	delivery := pool.Get()
+	delivery.WebhookID = "wh_1"
+	delivery.Events = []map[string]interface{}{{"type": "node.completed"}}
+	pool.Put(delivery)
+
+	reused := pool.Get()
+	if reused.WebhookID != "" || reused.Events != nil {
+		t.Errorf("Expected a cleared delivery, got %+v", reused)
+	}
+}
+
+func TestTraceEventPoolClearsEvents(t *testing.T) {
+	pool := NewTraceEventPool()
+	event := pool.Get()
+	event.NodeID = "n1"
+	pool.Put(event)
+
+	if reused := pool.Get(); reused.NodeID != "" {
+		t.Errorf("Expected a cleared event, got %+v", reused)
+	}
+}
+
+var benchmarkDelivery = []byte(`{"webhook_id":"wh_1","events":[{"type":"node.completed","nodeId":"n1"}],"metadata":{"namespace":"default","delivery_id":"d1","timestamp":"2024-01-01T00:00:00Z"}}`)
+
+func BenchmarkDeliveryDecode(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		delivery := new(WebhookDelivery)
+		if err := json.Unmarshal(benchmarkDelivery, delivery); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDeliveryDecodePooled(b *testing.B) {
+	pool := NewDeliveryPool()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		delivery := pool.Get()
+		if err := json.Unmarshal(benchmarkDelivery, delivery); err != nil {
+			b.Fatal(err)
+		}
+		pool.Put(delivery)
+	}
+}
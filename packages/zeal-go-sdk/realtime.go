@@ -0,0 +1,63 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventConn is a bidirectional ZIP event connection, such as a WebSocket.
+// Send encodes a control event as JSON; Receive returns the next raw frame.
+// Implementations that hold resources should also implement io.Closer so
+// ReconnectingConn can release connections it discards.
+type EventConn interface {
+	Send(ctx context.Context, event interface{}) error
+	Receive(ctx context.Context) ([]byte, error)
+}
+
+// CreatePingEvent creates a ping control event stamped with the current time
+func CreatePingEvent() *PingEvent {
+	return &PingEvent{
+		Type:      "ping",
+		Timestamp: time.Now().UnixMilli(),
+	}
+}
+
+// CalculateLatency returns the time between a ping and this pong
+func (e *PongEvent) CalculateLatency(sent *PingEvent) (time.Duration, error) {
+	if sent == nil {
+		return 0, errors.New("ping event is required")
+	}
+	if e.Timestamp < sent.Timestamp {
+		return 0, fmt.Errorf("pong timestamp %d precedes ping timestamp %d", e.Timestamp, sent.Timestamp)
+	}
+	return time.Duration(e.Timestamp-sent.Timestamp) * time.Millisecond, nil
+}
+
+// PingPongLatency sends a ping over conn and waits for the pong echoing its
+// timestamp, returning the round-trip time. Other frames received while
+// waiting are discarded.
+func PingPongLatency(ctx context.Context, conn EventConn) (time.Duration, error) {
+	ping := CreatePingEvent()
+	sentAt := time.Now()
+	if err := conn.Send(ctx, ping); err != nil {
+		return 0, fmt.Errorf("failed to send ping: %w", err)
+	}
+
+	for {
+		frame, err := conn.Receive(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to receive pong: %w", err)
+		}
+
+		var pong PongEvent
+		if err := json.Unmarshal(frame, &pong); err != nil {
+			continue
+		}
+		if pong.Type == "pong" && pong.Timestamp == ping.Timestamp {
+			return time.Since(sentAt), nil
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// echoConn answers pings with a pong echoing the ping timestamp
+type echoConn struct {
+	frames chan []byte
+	sent   []interface{}
+	silent bool
+}
+
+func newEchoConn() *echoConn {
+	return &echoConn{frames: make(chan []byte, 10)}
+}
+
+func (c *echoConn) Send(ctx context.Context, event interface{}) error {
+	c.sent = append(c.sent, event)
+	if ping, ok := event.(*PingEvent); ok && !c.silent {
+		c.frames <- []byte(`{"type":"node.updated"}`)
+		pong, _ := json.Marshal(PongEvent{Type: "pong", Timestamp: ping.Timestamp})
+		c.frames <- pong
+	}
+	return nil
+}
+
+func (c *echoConn) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case frame := <-c.frames:
+		return frame, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestPingPongLatency(t *testing.T) {
+	conn := newEchoConn()
+	latency, err := PingPongLatency(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("PingPongLatency failed: %v", err)
+	}
+	if latency < 0 || latency > time.Second {
+		t.Errorf("Unexpected latency %v", latency)
+	}
+}
+
+func TestPingPongLatencyTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	conn := &echoConn{frames: make(chan []byte), silent: true}
+	if _, err := PingPongLatency(ctx, conn); err == nil {
+		t.Error("Expected timeout error")
+	}
+}
+
+func TestPongCalculateLatency(t *testing.T) {
+	pong := &PongEvent{Type: "pong", Timestamp: 1250}
+	latency, err := pong.CalculateLatency(&PingEvent{Type: "ping", Timestamp: 1000})
+	if err != nil || latency != 250*time.Millisecond {
+		t.Errorf("Expected 250ms, got %v (%v)", latency, err)
+	}
+
+	if _, err := pong.CalculateLatency(&PingEvent{Timestamp: 2000}); err == nil {
+		t.Error("Expected error for pong before ping")
+	}
+	if _, err := pong.CalculateLatency(nil); err == nil {
+		t.Error("Expected error for nil ping")
+	}
+}
@@ -0,0 +1,390 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ReconnectPolicy controls the backoff between reconnection attempts
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of attempts per disconnect; 0 retries forever
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// JitterFactor randomizes each delay by up to ±JitterFactor of its value
+	JitterFactor float64
+}
+
+// DefaultReconnectPolicy returns the default reconnect policy
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		MaxAttempts:  10,
+		BaseDelay:    500 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+		JitterFactor: 0.2,
+	}
+}
+
+// maxBackoffExponent bounds the doubling in Delay so that retrying forever
+// without a MaxDelay never overflows
+const maxBackoffExponent = 62
+
+// Delay returns the wait before the given zero-based attempt:
+// min(BaseDelay * 2^attempt, MaxDelay), scaled by a random jitter. Without a
+// MaxDelay the wait is capped at the longest time.Duration.
+func (p ReconnectPolicy) Delay(attempt int) time.Duration {
+	exponent := math.Min(float64(attempt), maxBackoffExponent)
+	delay := float64(p.BaseDelay) * math.Pow(2, exponent)
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.JitterFactor > 0 {
+		delay *= 1 + p.JitterFactor*(2*rand.Float64()-1)
+	}
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// DialFunc opens a new event connection
+type DialFunc func(ctx context.Context) (EventConn, error)
+
+// ReconnectingConn wraps connections opened by a DialFunc, redialing with
+// backoff whenever a send or receive fails. Subscribe and subscribe_all
+// events sent through it are replayed on every new connection, so callers
+// keep receiving the same events across disconnects. Connections that also
+// implement io.Closer are closed once they are replaced or fail to replay.
+type ReconnectingConn struct {
+	dial   DialFunc
+	policy ReconnectPolicy
+
+	conn          EventConn
+	subscriptions []interface{}
+	updating      map[string]bool // workflows whose update may still be rejected
+	onReconnect   []func(attempt int)
+	metrics       connMetrics
+	pongTimeout   time.Duration
+	mu            sync.Mutex
+	reconnectMu   sync.Mutex
+}
+
+// NewReconnectingConn creates a reconnecting connection. Call Connect before use.
+func NewReconnectingConn(dial DialFunc, policy ReconnectPolicy) *ReconnectingConn {
+	return &ReconnectingConn{
+		dial:        dial,
+		policy:      policy,
+		pongTimeout: defaultPongTimeout,
+	}
+}
+
+// OnReconnect registers a hook called after each successful reconnection with
+// the attempt number that succeeded
+func (r *ReconnectingConn) OnReconnect(fn func(attempt int)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onReconnect = append(r.onReconnect, fn)
+}
+
+// Connect opens the initial connection, retrying according to the policy
+func (r *ReconnectingConn) Connect(ctx context.Context) error {
+	conn, err := r.dial(ctx)
+	if err == nil {
+		r.mu.Lock()
+		r.conn = conn
+		r.metrics.connectedAt = time.Now()
+		r.mu.Unlock()
+		return nil
+	}
+	return r.reconnect(ctx, nil)
+}
+
+// Send sends an event, reconnecting and retrying once if the connection fails
+func (r *ReconnectingConn) Send(ctx context.Context, event interface{}) (err error) {
+	conn := r.current()
+	if conn == nil {
+		return errors.New("not connected")
+	}
+
+	if ping, ok := event.(*PingEvent); ok {
+		seq := r.recordPing(ping)
+		defer func() {
+			if err != nil {
+				r.forgetPing(seq)
+			}
+		}()
+	}
+
+	if err = conn.Send(ctx, event); err != nil {
+		if err = r.reconnect(ctx, conn); err != nil {
+			return err
+		}
+		if err = r.current().Send(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	r.track(event)
+	r.recordSent()
+	return nil
+}
+
+// Receive returns the next frame, reconnecting transparently on failure
+func (r *ReconnectingConn) Receive(ctx context.Context) ([]byte, error) {
+	for {
+		conn := r.current()
+		if conn == nil {
+			return nil, errors.New("not connected")
+		}
+
+		frame, err := conn.Receive(ctx)
+		if err == nil {
+			r.recordReceived(frame)
+			r.handleRejectedUpdates(ctx, frame)
+			return frame, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err := r.reconnect(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *ReconnectingConn) current() EventConn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.conn
+}
+
+// track records subscriptions to replay, keeping one per workflow graph and
+// one subscribe_all per namespace. Unsubscribing a workflow forgets its
+// subscriptions; unsubscribing without a workflow ID forgets all of them,
+// including subscribe_all.
+func (r *ReconnectingConn) track(event interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch e := event.(type) {
+	case *SubscribeEvent:
+		for i, sub := range r.subscriptions {
+			if s, ok := sub.(*SubscribeEvent); ok && sameSubscription(s, e) {
+				r.subscriptions[i] = e
+				return
+			}
+		}
+		r.subscriptions = append(r.subscriptions, event)
+	case *SubscribeAllEvent:
+		for i, sub := range r.subscriptions {
+			if s, ok := sub.(*SubscribeAllEvent); ok && s.Namespace == e.Namespace {
+				r.subscriptions[i] = e
+				return
+			}
+		}
+		r.subscriptions = append(r.subscriptions, event)
+	case *UnsubscribeEvent:
+		if e.WorkflowID == nil {
+			r.subscriptions = nil
+			r.updating = nil
+			return
+		}
+		delete(r.updating, *e.WorkflowID)
+		kept := r.subscriptions[:0]
+		for _, sub := range r.subscriptions {
+			if s, ok := sub.(*SubscribeEvent); ok && s.WorkflowID == *e.WorkflowID {
+				continue
+			}
+			kept = append(kept, sub)
+		}
+		r.subscriptions = kept
+	}
+}
+
+func sameSubscription(a, b *SubscribeEvent) bool {
+	if a.WorkflowID != b.WorkflowID || (a.GraphID == nil) != (b.GraphID == nil) {
+		return false
+	}
+	return a.GraphID == nil || *a.GraphID == *b.GraphID
+}
+
+// UpdateSubscription changes the event filter of an existing subscription by
+// resending it on the open connection. Servers without hot updates reply with
+// an error frame, which arrives through Receive since the connection has no
+// request/response correlation. When Receive sees an error frame for the
+// workflow, or one without a workflow ID, while the update is pending, it
+// falls back to unsubscribing the workflow and subscribing to each of its
+// graphs again with the latest filters. The error frame is still returned.
+func (r *ReconnectingConn) UpdateSubscription(ctx context.Context, workflowID string, graphID *string, eventTypes []string) error {
+	if err := r.Send(ctx, CreateSubscribeEvent(workflowID, graphID, eventTypes)); err != nil {
+		return fmt.Errorf("failed to update subscription to %s: %w", workflowID, err)
+	}
+
+	r.mu.Lock()
+	if r.updating == nil {
+		r.updating = make(map[string]bool)
+	}
+	r.updating[workflowID] = true
+	r.mu.Unlock()
+	return nil
+}
+
+// handleRejectedUpdates runs the UpdateSubscription fallback for the pending
+// updates an error frame rejects
+func (r *ReconnectingConn) handleRejectedUpdates(ctx context.Context, frame []byte) {
+	var event ErrorControlEvent
+	if json.Unmarshal(frame, &event) != nil || event.Type != "error" {
+		return
+	}
+
+	r.mu.Lock()
+	var rejected []string
+	for workflowID := range r.updating {
+		if event.WorkflowID == nil || *event.WorkflowID == workflowID {
+			rejected = append(rejected, workflowID)
+			delete(r.updating, workflowID)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, workflowID := range rejected {
+		if err := r.resubscribe(ctx, workflowID); err != nil {
+			logf("subscription update for %s was rejected and resubscribing failed: %v", workflowID, err)
+		}
+	}
+}
+
+// resubscribe unsubscribes a workflow and sends its tracked subscriptions again
+func (r *ReconnectingConn) resubscribe(ctx context.Context, workflowID string) error {
+	r.mu.Lock()
+	var subscriptions []*SubscribeEvent
+	for _, sub := range r.subscriptions {
+		if s, ok := sub.(*SubscribeEvent); ok && s.WorkflowID == workflowID {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	r.mu.Unlock()
+
+	if err := r.Send(ctx, &UnsubscribeEvent{Type: "unsubscribe", WorkflowID: &workflowID}); err != nil {
+		return fmt.Errorf("failed to unsubscribe %s: %w", workflowID, err)
+	}
+	for _, sub := range subscriptions {
+		if err := r.Send(ctx, sub); err != nil {
+			return fmt.Errorf("failed to resubscribe %s: %w", workflowID, err)
+		}
+	}
+	return nil
+}
+
+// SubscribeGraphs subscribes to each of a workflow's graphs in turn
+func (r *ReconnectingConn) SubscribeGraphs(ctx context.Context, workflowID string, graphIDs []string) error {
+	for _, graphID := range graphIDs {
+		graphID := graphID
+		if err := r.Send(ctx, CreateSubscribeEvent(workflowID, &graphID, nil)); err != nil {
+			return fmt.Errorf("failed to subscribe to graph %s of %s: %w", graphID, workflowID, err)
+		}
+	}
+	return nil
+}
+
+// ActiveSubscriptions returns the subscribed graph IDs by workflow ID. A
+// workflow subscribed without a graph ID maps to an empty slice.
+func (r *ReconnectingConn) ActiveSubscriptions() map[string][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	active := make(map[string][]string)
+	for _, sub := range r.subscriptions {
+		s, ok := sub.(*SubscribeEvent)
+		if !ok {
+			continue
+		}
+		graphs := active[s.WorkflowID]
+		if graphs == nil {
+			graphs = []string{}
+		}
+		if s.GraphID != nil {
+			graphs = append(graphs, *s.GraphID)
+		}
+		active[s.WorkflowID] = graphs
+	}
+	return active
+}
+
+// reconnect replaces failed with a new connection and replays subscriptions.
+// If another caller already replaced failed, it returns immediately.
+func (r *ReconnectingConn) reconnect(ctx context.Context, failed EventConn) error {
+	r.reconnectMu.Lock()
+	defer r.reconnectMu.Unlock()
+
+	if current := r.current(); current != nil && current != failed {
+		return nil
+	}
+
+	var lastErr error
+	for attempt := 0; r.policy.MaxAttempts <= 0 || attempt < r.policy.MaxAttempts; attempt++ {
+		select {
+		case <-time.After(r.policy.Delay(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		conn, err := r.dial(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := r.replay(ctx, conn); err != nil {
+			lastErr = err
+			closeConn(conn)
+			continue
+		}
+
+		r.mu.Lock()
+		r.conn = conn
+		r.metrics.connectedAt = time.Now()
+		if failed != nil {
+			r.metrics.reconnects++
+		}
+		hooks := append([]func(int){}, r.onReconnect...)
+		r.mu.Unlock()
+
+		if failed != nil {
+			closeConn(failed)
+		}
+		for _, hook := range hooks {
+			hook(attempt + 1)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("failed to reconnect after %d attempts: %w", r.policy.MaxAttempts, lastErr)
+}
+
+func (r *ReconnectingConn) replay(ctx context.Context, conn EventConn) error {
+	r.mu.Lock()
+	subscriptions := append([]interface{}{}, r.subscriptions...)
+	r.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		if err := conn.Send(ctx, sub); err != nil {
+			return fmt.Errorf("failed to replay subscription: %w", err)
+		}
+	}
+	return nil
+}
+
+// closeConn closes conn if it implements io.Closer. Errors are ignored since
+// the connection is already being discarded.
+func closeConn(conn EventConn) {
+	if closer, ok := conn.(io.Closer); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,374 @@
+package zeal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedConn returns its frames in order, then fails. Sends fail with
+// sendErr when it is set.
+type scriptedConn struct {
+	mu      sync.Mutex
+	frames  [][]byte
+	sent    []interface{}
+	sendErr error
+	closed  bool
+}
+
+func (c *scriptedConn) Send(ctx context.Context, event interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sendErr != nil {
+		return c.sendErr
+	}
+	c.sent = append(c.sent, event)
+	return nil
+}
+
+func (c *scriptedConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *scriptedConn) Receive(ctx context.Context) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.frames) == 0 {
+		return nil, errors.New("connection closed")
+	}
+	frame := c.frames[0]
+	c.frames = c.frames[1:]
+	return frame, nil
+}
+
+// dialSequence returns a DialFunc that opens first, then second on every
+// later dial
+func dialSequence(first, second EventConn) DialFunc {
+	dials := 0
+	return func(ctx context.Context) (EventConn, error) {
+		dials++
+		if dials == 1 {
+			return first, nil
+		}
+		return second, nil
+	}
+}
+
+func TestReconnectPolicyDelay(t *testing.T) {
+	policy := ReconnectPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for attempt, want := range expected {
+		if got := policy.Delay(attempt); got != want {
+			t.Errorf("Attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+
+	policy.JitterFactor = 0.5
+	for i := 0; i < 100; i++ {
+		if d := policy.Delay(0); d < 50*time.Millisecond || d > 150*time.Millisecond {
+			t.Fatalf("Expected jittered delay within ±50%%, got %v", d)
+		}
+	}
+
+	unbounded := ReconnectPolicy{BaseDelay: time.Second, JitterFactor: 0.5}
+	for _, attempt := range []int{40, 100, 2000} {
+		if d := unbounded.Delay(attempt); d <= 0 {
+			t.Errorf("Attempt %d: expected a positive capped delay, got %v", attempt, d)
+		}
+	}
+}
+
+func TestReconnectingConnReplaysSubscriptions(t *testing.T) {
+	conns := []*scriptedConn{
+		{frames: [][]byte{[]byte("first")}},
+		{frames: [][]byte{[]byte("second")}},
+	}
+	dials := 0
+	dial := func(ctx context.Context) (EventConn, error) {
+		dials++
+		switch dials {
+		case 1:
+			return conns[0], nil
+		case 3:
+			return conns[1], nil
+		}
+		return nil, errors.New("server unavailable")
+	}
+
+	conn := NewReconnectingConn(dial, ReconnectPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	var attempts []int
+	conn.OnReconnect(func(attempt int) { attempts = append(attempts, attempt) })
+
+	ctx := context.Background()
+	if err := conn.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	workflowID := "wf-2"
+	conn.Send(ctx, &SubscribeEvent{Type: "subscribe", WorkflowID: "wf-1"})
+	conn.Send(ctx, &SubscribeEvent{Type: "subscribe", WorkflowID: workflowID})
+	conn.Send(ctx, &UnsubscribeEvent{Type: "unsubscribe", WorkflowID: &workflowID})
+
+	for _, want := range []string{"first", "second"} {
+		frame, err := conn.Receive(ctx)
+		if err != nil || string(frame) != want {
+			t.Fatalf("Expected frame %q, got %q (%v)", want, frame, err)
+		}
+	}
+
+	if len(attempts) != 1 || attempts[0] != 2 {
+		t.Errorf("Expected one reconnect on attempt 2, got %v", attempts)
+	}
+	if count := conn.Metrics().ReconnectCount; count != 1 {
+		t.Errorf("Expected reconnect count 1, got %d", count)
+	}
+	replayed := conns[1].sent
+	if len(replayed) != 1 || replayed[0].(*SubscribeEvent).WorkflowID != "wf-1" {
+		t.Errorf("Expected only the wf-1 subscription to be replayed, got %v", replayed)
+	}
+	if !conns[0].closed {
+		t.Error("Expected the replaced connection to be closed")
+	}
+
+	if _, err := conn.Receive(ctx); err == nil {
+		t.Error("Expected error once reconnect attempts are exhausted")
+	}
+}
+
+func TestReconnectingConnUpdateSubscription(t *testing.T) {
+	first := &scriptedConn{}
+	second := &scriptedConn{frames: [][]byte{[]byte("event")}}
+	conn := NewReconnectingConn(dialSequence(first, second), ReconnectPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond})
+
+	ctx := context.Background()
+	conn.Connect(ctx)
+	conn.Send(ctx, CreateSubscribeEvent("wf-1", nil, nil))
+	if err := conn.UpdateSubscription(ctx, "wf-1", nil, []string{"node.failed"}); err != nil {
+		t.Fatalf("UpdateSubscription failed: %v", err)
+	}
+
+	if len(first.sent) != 2 {
+		t.Fatalf("Expected the update on the existing connection, got %v", first.sent)
+	}
+	if filter := first.sent[1].(*SubscribeEvent).Filter; filter == nil {
+		t.Error("Expected update to carry a filter")
+	}
+
+	// Force a reconnect: only the updated subscription is replayed
+	conn.Receive(ctx)
+	if len(second.sent) != 1 || second.sent[0].(*SubscribeEvent).Filter == nil {
+		t.Errorf("Expected the updated subscription to be replayed, got %v", second.sent)
+	}
+}
+
+func TestReconnectingConnUpdateSubscriptionFallback(t *testing.T) {
+	first := &scriptedConn{frames: [][]byte{
+		[]byte(`{"type":"error","code":"UNSUPPORTED","workflowId":"wf-2"}`),
+		[]byte(`{"type":"error","code":"UNSUPPORTED","message":"hot update not supported","workflowId":"wf-1"}`),
+		[]byte(`{"type":"error","code":"UNSUPPORTED","workflowId":"wf-1"}`),
+	}}
+	conn := NewReconnectingConn(dialSequence(first, first), ReconnectPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond})
+
+	ctx := context.Background()
+	conn.Connect(ctx)
+	graphA, graphB := "a", "b"
+	conn.Send(ctx, CreateSubscribeEvent("wf-1", &graphA, nil))
+	conn.Send(ctx, CreateSubscribeEvent("wf-1", &graphB, nil))
+	if err := conn.UpdateSubscription(ctx, "wf-1", &graphA, []string{"node.failed"}); err != nil {
+		t.Fatalf("UpdateSubscription failed: %v", err)
+	}
+
+	// An error frame about another workflow leaves the update pending
+	conn.Receive(ctx)
+	if len(first.sent) != 3 {
+		t.Fatalf("Expected no fallback for another workflow, got %v", first.sent)
+	}
+
+	frame, err := conn.Receive(ctx)
+	if err != nil || !strings.Contains(string(frame), "hot update not supported") {
+		t.Fatalf("Expected the error frame to be returned, got %q (%v)", frame, err)
+	}
+	fallback := first.sent[3:]
+	if len(fallback) != 3 {
+		t.Fatalf("Expected unsubscribe and two subscribes, got %v", fallback)
+	}
+	if unsubscribe, ok := fallback[0].(*UnsubscribeEvent); !ok || *unsubscribe.WorkflowID != "wf-1" {
+		t.Errorf("Expected wf-1 to be unsubscribed first, got %v", fallback[0])
+	}
+	if sub := fallback[1].(*SubscribeEvent); *sub.GraphID != "a" || sub.Filter == nil {
+		t.Errorf("Expected graph a to be resubscribed with the new filter, got %+v", sub)
+	}
+	if sub := fallback[2].(*SubscribeEvent); *sub.GraphID != "b" || sub.Filter != nil {
+		t.Errorf("Expected graph b to be resubscribed unchanged, got %+v", sub)
+	}
+	if active := conn.ActiveSubscriptions(); len(active["wf-1"]) != 2 {
+		t.Errorf("Expected both graphs to stay subscribed, got %v", active)
+	}
+
+	// The fallback runs once per update
+	conn.Receive(ctx)
+	if len(first.sent) != 6 {
+		t.Errorf("Expected no further fallback, got %v", first.sent)
+	}
+}
+
+func TestReconnectingConnUpdateSubscriptionSendFailure(t *testing.T) {
+	broken := &scriptedConn{sendErr: errors.New("write failed")}
+	conn := NewReconnectingConn(dialSequence(broken, broken), ReconnectPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond})
+
+	ctx := context.Background()
+	conn.Connect(ctx)
+	if err := conn.UpdateSubscription(ctx, "wf-1", nil, []string{"node.failed"}); err == nil {
+		t.Error("Expected an error when the update cannot be sent")
+	}
+}
+
+func TestReconnectingConnMetrics(t *testing.T) {
+	echo := newEchoConn()
+	conn := NewReconnectingConn(func(ctx context.Context) (EventConn, error) {
+		return echo, nil
+	}, DefaultReconnectPolicy())
+
+	ctx := context.Background()
+	conn.Connect(ctx)
+	if conn.ConnectionQuality() != QualityUnknown {
+		t.Errorf("Expected unknown quality before any ping, got %v", conn.ConnectionQuality())
+	}
+
+	conn.Send(ctx, CreatePingEvent())
+	conn.Receive(ctx)
+	conn.Receive(ctx)
+
+	metrics := conn.Metrics()
+	if metrics.MessagesSent != 1 || metrics.MessagesReceived != 2 {
+		t.Errorf("Expected 1 sent and 2 received, got %+v", metrics)
+	}
+	if metrics.ConnectedAt.IsZero() || metrics.ReconnectCount != 0 {
+		t.Errorf("Unexpected connection metrics: %+v", metrics)
+	}
+	if conn.ConnectionQuality() != QualityExcellent {
+		t.Errorf("Expected excellent quality for a local echo, got %v", conn.ConnectionQuality())
+	}
+}
+
+func TestReconnectingConnPendingPings(t *testing.T) {
+	conn := NewReconnectingConn(func(ctx context.Context) (EventConn, error) {
+		return &scriptedConn{}, nil
+	}, DefaultReconnectPolicy())
+	conn.pongTimeout = 50 * time.Millisecond
+
+	ctx := context.Background()
+	conn.Connect(ctx)
+
+	// Two pings in the same millisecond are both tracked and matched in order
+	conn.Send(ctx, &PingEvent{Type: "ping", Timestamp: 1})
+	conn.Send(ctx, &PingEvent{Type: "ping", Timestamp: 1})
+	if pending := len(conn.metrics.pendingPings); pending != 2 {
+		t.Fatalf("Expected 2 pending pings, got %d", pending)
+	}
+	conn.recordReceived([]byte(`{"type":"pong","timestamp":1}`))
+	if pending := len(conn.metrics.pendingPings); pending != 1 || conn.metrics.pendingPings[0].seq != 2 {
+		t.Errorf("Expected the second ping to remain pending, got %+v", conn.metrics.pendingPings)
+	}
+
+	// Pings whose pong never arrives are evicted after the pong timeout
+	time.Sleep(60 * time.Millisecond)
+	conn.Send(ctx, &PingEvent{Type: "ping", Timestamp: 2})
+	if pending := len(conn.metrics.pendingPings); pending != 1 || conn.metrics.pendingPings[0].timestamp != 2 {
+		t.Errorf("Expected only the latest ping to remain pending, got %+v", conn.metrics.pendingPings)
+	}
+}
+
+func TestReconnectingConnSubscribeGraphs(t *testing.T) {
+	first := &scriptedConn{}
+	second := &scriptedConn{frames: [][]byte{[]byte("event")}}
+	conn := NewReconnectingConn(dialSequence(first, second), ReconnectPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond})
+
+	ctx := context.Background()
+	conn.Connect(ctx)
+	if err := conn.SubscribeGraphs(ctx, "wf-1", []string{"main", "sub-1"}); err != nil {
+		t.Fatalf("SubscribeGraphs failed: %v", err)
+	}
+	conn.Send(ctx, CreateSubscribeEvent("wf-2", nil, nil))
+
+	active := conn.ActiveSubscriptions()
+	if len(active["wf-1"]) != 2 || active["wf-1"][1] != "sub-1" {
+		t.Errorf("Expected both graphs of wf-1, got %v", active)
+	}
+	if graphs, ok := active["wf-2"]; !ok || len(graphs) != 0 {
+		t.Errorf("Expected wf-2 without graphs, got %v", active)
+	}
+
+	conn.Receive(ctx)
+	if len(second.sent) != 3 {
+		t.Errorf("Expected all 3 subscriptions replayed after reconnect, got %d", len(second.sent))
+	}
+}
+
+func TestReconnectingConnClosesConnectionsFailingReplay(t *testing.T) {
+	first := &scriptedConn{}
+	broken := &scriptedConn{sendErr: errors.New("write failed")}
+	healthy := &scriptedConn{frames: [][]byte{[]byte("event")}}
+	dials := 0
+	conn := NewReconnectingConn(func(ctx context.Context) (EventConn, error) {
+		dials++
+		switch dials {
+		case 1:
+			return first, nil
+		case 2:
+			return broken, nil
+		}
+		return healthy, nil
+	}, ReconnectPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	ctx := context.Background()
+	conn.Connect(ctx)
+	conn.Send(ctx, CreateSubscribeEvent("wf-1", nil, nil))
+	if frame, err := conn.Receive(ctx); err != nil || string(frame) != "event" {
+		t.Fatalf("Expected frame from the healthy connection, got %q (%v)", frame, err)
+	}
+
+	if !broken.closed {
+		t.Error("Expected the connection that failed replay to be closed")
+	}
+	if !first.closed {
+		t.Error("Expected the replaced connection to be closed")
+	}
+	if healthy.closed {
+		t.Error("Expected the current connection to stay open")
+	}
+}
+
+func TestReconnectingConnSubscribeAllReplayedOnce(t *testing.T) {
+	first := &scriptedConn{}
+	second := &scriptedConn{frames: [][]byte{[]byte("event")}}
+	conn := NewReconnectingConn(dialSequence(first, second), ReconnectPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond})
+
+	ctx := context.Background()
+	conn.Connect(ctx)
+	conn.Send(ctx, &SubscribeAllEvent{Type: "subscribe_all", Namespace: "ns"})
+	conn.Send(ctx, &SubscribeAllEvent{Type: "subscribe_all", Namespace: "ns"})
+	conn.Send(ctx, &SubscribeAllEvent{Type: "subscribe_all", Namespace: "other"})
+
+	conn.Receive(ctx)
+	if len(second.sent) != 2 {
+		t.Fatalf("Expected one subscribe_all per namespace to be replayed, got %v", second.sent)
+	}
+
+	conn.Send(ctx, &UnsubscribeEvent{Type: "unsubscribe"})
+	if active := conn.ActiveSubscriptions(); len(active) != 0 {
+		t.Errorf("Expected no subscriptions after unsubscribing, got %v", active)
+	}
+	conn.mu.Lock()
+	remaining := len(conn.subscriptions)
+	conn.mu.Unlock()
+	if remaining != 0 {
+		t.Errorf("Expected subscribe_all to be forgotten, %d subscriptions remain", remaining)
+	}
+}
@@ -0,0 +1,59 @@
+package zeal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+)
+
+// defaultCompressionThreshold is used when ClientConfig.CompressionThresholdBytes is unset
+const defaultCompressionThreshold = 4096
+
+// RequestOption customizes a single API call
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	compress bool
+}
+
+type requestOptionsKey struct{}
+
+// CompressRequest gzips the request body when it exceeds
+// ClientConfig.CompressionThresholdBytes
+func CompressRequest() RequestOption {
+	return func(o *requestOptions) {
+		o.compress = true
+	}
+}
+
+// withRequestOptions attaches per-call options to ctx for the transport
+func withRequestOptions(ctx context.Context, opts []RequestOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+	options := &requestOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return context.WithValue(ctx, requestOptionsKey{}, options)
+}
+
+func requestOptionsFromContext(ctx context.Context) requestOptions {
+	if options, ok := ctx.Value(requestOptionsKey{}).(*requestOptions); ok {
+		return *options
+	}
+	return requestOptions{}
+}
+
+func gzipBody(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
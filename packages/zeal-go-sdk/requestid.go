@@ -0,0 +1,60 @@
+package zeal
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+type deliveryIDKey struct{}
+
+// requestIDHeaders are checked in priority order for an incoming request ID
+var requestIDHeaders = []string{"X-Request-ID", "X-Correlation-ID", "X-Trace-ID"}
+
+// WithRequestID returns a context whose outgoing API calls carry the given
+// X-Request-ID header
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID set with WithRequestID, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithDeliveryID returns a context carrying the webhook delivery ID
+func WithDeliveryID(ctx context.Context, deliveryID string) context.Context {
+	return context.WithValue(ctx, deliveryIDKey{}, deliveryID)
+}
+
+// DeliveryIDFromContext returns the webhook delivery ID passed to
+// subscription callbacks, if any
+func DeliveryIDFromContext(ctx context.Context) string {
+	deliveryID, _ := ctx.Value(deliveryIDKey{}).(string)
+	return deliveryID
+}
+
+// requestIDFromHeaders returns the first request ID header present, or a new ID
+func requestIDFromHeaders(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return newRequestID()
+}
+
+// newRequestID generates a random (version 4) UUID
+func newRequestID() string {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		panic(fmt.Sprintf("zeal: failed to read random bytes for request ID: %v", err))
+	}
+	u[6] = 0x40 | (u[6] & 0x0f) // version 4
+	u[8] = 0x80 | (u[8] & 0x3f) // RFC 9562 variant
+	return formatUUID(u)
+}
@@ -0,0 +1,30 @@
+package zeal
+
+// redacted replaces secret values in formatted and encoded output
+const redacted = "[REDACTED]"
+
+// SecretString holds a credential that is redacted when printed or encoded
+// as JSON. Use Reveal to read the actual value. Because the JSON encoding is
+// redacted, structs holding a SecretString do not survive a JSON round trip
+// with the credential intact.
+type SecretString string
+
+// Reveal returns the secret value
+func (s SecretString) Reveal() string {
+	return string(s)
+}
+
+// String implements fmt.Stringer
+func (s SecretString) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer
+func (s SecretString) GoString() string {
+	return `"` + redacted + `"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (s SecretString) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
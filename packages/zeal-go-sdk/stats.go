@@ -0,0 +1,166 @@
+package zeal
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// statsReservoirSize is the number of latency samples kept for percentile estimation
+const statsReservoirSize = 1024
+
+// Stats is a snapshot of request-level SDK telemetry
+type Stats struct {
+	RequestsTotal   uint64 `json:"requestsTotal"`
+	RequestsSuccess uint64 `json:"requestsSuccess"`
+	RequestsFailure uint64 `json:"requestsFailure"`
+	TotalDurationMs int64  `json:"totalDurationMs"`
+	P50Ms           int64  `json:"p50Ms"`
+	P95Ms           int64  `json:"p95Ms"`
+	P99Ms           int64  `json:"p99Ms"`
+}
+
+// requestStats accumulates request counters atomically and estimates latency
+// percentiles from a uniform reservoir sample
+type requestStats struct {
+	total           uint64
+	success         uint64
+	failure         uint64
+	totalDurationMs int64
+	seen            uint64
+	samples         []int64
+	mu              sync.Mutex
+}
+
+func newRequestStats() *requestStats {
+	return &requestStats{samples: make([]int64, 0, statsReservoirSize)}
+}
+
+func (s *requestStats) record(duration time.Duration, err error) {
+	durationMs := duration.Milliseconds()
+	atomic.AddUint64(&s.total, 1)
+	if err != nil {
+		atomic.AddUint64(&s.failure, 1)
+	} else {
+		atomic.AddUint64(&s.success, 1)
+	}
+	atomic.AddInt64(&s.totalDurationMs, durationMs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen++
+	if len(s.samples) < statsReservoirSize {
+		s.samples = append(s.samples, durationMs)
+	} else if i := rand.Int63n(int64(s.seen)); i < statsReservoirSize {
+		s.samples[i] = durationMs
+	}
+}
+
+func (s *requestStats) snapshot() Stats {
+	stats := Stats{
+		RequestsTotal:   atomic.LoadUint64(&s.total),
+		RequestsSuccess: atomic.LoadUint64(&s.success),
+		RequestsFailure: atomic.LoadUint64(&s.failure),
+		TotalDurationMs: atomic.LoadInt64(&s.totalDurationMs),
+	}
+
+	s.mu.Lock()
+	sorted := make([]int64, len(s.samples))
+	copy(sorted, s.samples)
+	s.mu.Unlock()
+
+	if len(sorted) > 0 {
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats.P50Ms = percentile(sorted, 0.50)
+		stats.P95Ms = percentile(sorted, 0.95)
+		stats.P99Ms = percentile(sorted, 0.99)
+	}
+	return stats
+}
+
+func (s *requestStats) reset() {
+	atomic.StoreUint64(&s.total, 0)
+	atomic.StoreUint64(&s.success, 0)
+	atomic.StoreUint64(&s.failure, 0)
+	atomic.StoreInt64(&s.totalDurationMs, 0)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen = 0
+	s.samples = s.samples[:0]
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// statsModule maps a request path to the API module it belongs to
+func statsModule(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/api/zip/orchestrator"):
+		return "orchestrator"
+	case strings.HasPrefix(path, "/api/zip/templates"),
+		strings.HasPrefix(path, "/api/zip/categories"),
+		strings.HasPrefix(path, "/api/zip/components"):
+		return "templates"
+	case strings.HasPrefix(path, "/api/zip/traces"):
+		return "traces"
+	case strings.HasPrefix(path, "/api/zip/webhooks"):
+		return "webhooks"
+	}
+	return ""
+}
+
+func (c *Client) recordRequest(path string, duration time.Duration, err error) {
+	if c.stats == nil {
+		return
+	}
+	c.stats.record(duration, err)
+	if module, ok := c.moduleStats[statsModule(path)]; ok {
+		module.record(duration, err)
+	}
+}
+
+// Statistics returns request telemetry across all API modules
+func (c *Client) Statistics() Stats {
+	return c.stats.snapshot()
+}
+
+// OrchestratorStats returns request telemetry for the orchestrator API
+func (c *Client) OrchestratorStats() Stats {
+	return c.moduleStats["orchestrator"].snapshot()
+}
+
+// TemplatesStats returns request telemetry for the templates API
+func (c *Client) TemplatesStats() Stats {
+	return c.moduleStats["templates"].snapshot()
+}
+
+// TracesStats returns request telemetry for the traces API
+func (c *Client) TracesStats() Stats {
+	return c.moduleStats["traces"].snapshot()
+}
+
+// WebhooksStats returns request telemetry for the webhooks API
+func (c *Client) WebhooksStats() Stats {
+	return c.moduleStats["webhooks"].snapshot()
+}
+
+// ResetStatistics clears all request telemetry
+func (c *Client) ResetStatistics() {
+	c.stats.reset()
+	for _, module := range c.moduleStats {
+		module.reset()
+	}
+}
@@ -6,11 +6,17 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -28,9 +34,45 @@ type SubscriptionOptions struct {
 	BufferSize       int               `json:"bufferSize"`
 	Headers          map[string]string `json:"headers"`
 	VerifySignature  bool              `json:"verifySignature"`
-	SecretKey        string            `json:"secretKey"`
+	SecretKey        SecretString      `json:"secretKey"`
+	WorkflowFilter   []string          `json:"workflowFilter,omitempty"` // empty means all workflows
+	DispatchMode     DispatchMode      `json:"dispatchMode"`
+	// ConcurrentDispatchTimeout limits each callback in concurrent mode; 0 means no limit
+	ConcurrentDispatchTimeout time.Duration `json:"concurrentDispatchTimeout,omitempty"`
+	// EncryptionKey, when set, is the AES key used to decrypt AES-GCM encrypted
+	// webhook bodies; see DecryptWebhookPayload
+	EncryptionKey []byte `json:"-"`
+	// SecretGracePeriod is how long UpdateSecretKey keeps accepting
+	// signatures made with the previous secret
+	SecretGracePeriod time.Duration `json:"secretGracePeriod,omitempty"`
+	// StartupTimeout is how long Start waits for the server to accept
+	// connections
+	StartupTimeout time.Duration `json:"startupTimeout,omitempty"`
+	// VerificationHandler, when set, answers GET requests on the webhook path,
+	// for providers that verify the endpoint before enabling delivery
+	VerificationHandler http.HandlerFunc `json:"-"`
+	// MaxBodyBytes is the largest delivery body accepted; larger bodies are
+	// rejected with 413
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty"`
+	// ExternalURL, when set, is the URL registered with Zeal instead of one
+	// built from Host, Port and Path, for servers behind NAT or a load balancer
+	ExternalURL string `json:"externalUrl,omitempty"`
 }
 
+// defaultMaxBodyBytes is the default SubscriptionOptions.MaxBodyBytes
+const defaultMaxBodyBytes = 10 << 20
+
+// DispatchMode controls how event callbacks are invoked for each event
+type DispatchMode int
+
+const (
+	// DispatchSequential calls event callbacks one after another
+	DispatchSequential DispatchMode = iota
+	// DispatchConcurrent calls each event callback in its own goroutine and
+	// waits for all of them before the next event
+	DispatchConcurrent
+)
+
 // DefaultSubscriptionOptions returns default subscription options
 func DefaultSubscriptionOptions() SubscriptionOptions {
 	return SubscriptionOptions{
@@ -43,6 +85,8 @@ func DefaultSubscriptionOptions() SubscriptionOptions {
 		Events:          []string{"*"},
 		BufferSize:      1000,
 		VerifySignature: false,
+		StartupTimeout:  5 * time.Second,
+		MaxBodyBytes:    defaultMaxBodyBytes,
 	}
 }
 
@@ -53,18 +97,80 @@ type WebhookDelivery struct {
 	Metadata  WebhookMetadata          `json:"metadata"`
 }
 
+// ParsedEvents parses each delivered event with ParseZipWebhookEvent. Events
+// that fail to parse are skipped and reported in the returned errors.
+func (d WebhookDelivery) ParsedEvents() ([]ZipWebhookEvent, []error) {
+	events := make([]ZipWebhookEvent, 0, len(d.Events))
+	var errs []error
+	for i, raw := range d.Events {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("event %d: failed to encode event: %w", i, err))
+			continue
+		}
+		event, err := ParseZipWebhookEvent(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("event %d: %w", i, err))
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, errs
+}
+
+// Age returns the time elapsed since the delivery timestamp
+func (d WebhookDelivery) Age() (time.Duration, error) {
+	t, err := d.Metadata.ParsedTimestamp()
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(t), nil
+}
+
+// FilterEventsByType returns the delivered events of the given type
+func (d WebhookDelivery) FilterEventsByType(eventType string) []map[string]interface{} {
+	events := make([]map[string]interface{}, 0)
+	for _, event := range d.Events {
+		if t, _ := event["type"].(string); t == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
 // WebhookMetadata contains webhook delivery metadata
 type WebhookMetadata struct {
 	Namespace  string `json:"namespace"`
 	DeliveryID string `json:"delivery_id"`
 	Timestamp  string `json:"timestamp"`
+	RequestID  string `json:"request_id,omitempty"`
+	// Tag is the PathOptions.Tag of the path the delivery arrived on
+	Tag string `json:"tag,omitempty"`
 }
 
-// WebhookEventCallback is called for each webhook event
-type WebhookEventCallback func(event map[string]interface{}) error
+// ParsedTimestamp parses the delivery timestamp
+func (m WebhookMetadata) ParsedTimestamp() (time.Time, error) {
+	return parseEventTimestamp(m.Timestamp)
+}
+
+// PathOptions configures an additional webhook path added with AddPath
+type PathOptions struct {
+	VerifySignature bool         `json:"verifySignature"`
+	SecretKey       SecretString `json:"secretKey"`
+	// Tag identifies the path; it is set as the delivery's Metadata.Tag and
+	// as "tag" in the metadata of each delivered event
+	Tag string `json:"tag,omitempty"`
+}
 
-// WebhookDeliveryCallback is called for each webhook delivery
-type WebhookDeliveryCallback func(delivery WebhookDelivery) error
+// WebhookEventCallback is called for each webhook event. For deliveries
+// received by the webhook server, ctx carries the values of the incoming
+// request plus its request ID and delivery ID; see RequestIDFromContext and
+// DeliveryIDFromContext.
+type WebhookEventCallback func(ctx context.Context, event map[string]interface{}) error
+
+// WebhookDeliveryCallback is called for each webhook delivery with the same
+// context as WebhookEventCallback
+type WebhookDeliveryCallback func(ctx context.Context, delivery WebhookDelivery) error
 
 // WebhookErrorCallback is called when an error occurs
 type WebhookErrorCallback func(error) error
@@ -74,34 +180,136 @@ type WebhookObservable struct {
 	eventChan    chan map[string]interface{}
 	errorChan    chan error
 	completeChan chan struct{}
+	completeOnce sync.Once
 	subscription *WebhookSubscriptionManager
+
+	handlersMu       sync.Mutex
+	completeHandlers []func()
+	errorHandlers    []func(error)
+}
+
+// complete closes completeChan; later calls have no effect
+func (wo *WebhookObservable) complete() {
+	wo.completeOnce.Do(func() { close(wo.completeChan) })
+}
+
+// OnComplete registers fn to be called by Subscribe when the subscription
+// completes, after its complete callback. It is not called when the
+// subscription is stopped with the function returned by Subscribe.
+func (wo *WebhookObservable) OnComplete(fn func()) {
+	wo.handlersMu.Lock()
+	defer wo.handlersMu.Unlock()
+	wo.completeHandlers = append(wo.completeHandlers, fn)
 }
 
-// Subscribe subscribes to webhook events with callbacks
+// OnError registers fn to be called by Subscribe with each error, after its
+// error handler
+func (wo *WebhookObservable) OnError(fn func(error)) {
+	wo.handlersMu.Lock()
+	defer wo.handlersMu.Unlock()
+	wo.errorHandlers = append(wo.errorHandlers, fn)
+}
+
+// notifyComplete calls complete, if set, and the OnComplete handlers
+func (wo *WebhookObservable) notifyComplete(complete func()) {
+	if complete != nil {
+		complete()
+	}
+	wo.handlersMu.Lock()
+	handlers := append([]func(){}, wo.completeHandlers...)
+	wo.handlersMu.Unlock()
+	for _, handler := range handlers {
+		handler()
+	}
+}
+
+// notifyError calls errorHandler, if set, and the OnError handlers with err
+func (wo *WebhookObservable) notifyError(errorHandler WebhookErrorCallback, err error) {
+	if errorHandler != nil {
+		errorHandler(err)
+	}
+	wo.handlersMu.Lock()
+	handlers := append([]func(error){}, wo.errorHandlers...)
+	wo.handlersMu.Unlock()
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
+
+// Capacity returns the size of the event buffer
+func (wo *WebhookObservable) Capacity() int {
+	return cap(wo.eventChan)
+}
+
+// ChannelDepth returns the number of events and errors waiting to be consumed
+func (wo *WebhookObservable) ChannelDepth() (events, errors int) {
+	return len(wo.eventChan), len(wo.errorChan)
+}
+
+// channelDepthCheckInterval is how often Subscribe samples the event buffer
+var channelDepthCheckInterval = time.Second
+
+// channelDepthWarnRatio is the buffer fill ratio above which a warning is logged
+const channelDepthWarnRatio = 0.8
+
+// monitorDepth logs a warning each time the event buffer fills past
+// channelDepthWarnRatio, until the subscription ends
+func (wo *WebhookObservable) monitorDepth(ctx, subCtx context.Context) {
+	ticker := time.NewTicker(channelDepthCheckInterval)
+	defer ticker.Stop()
+
+	warned := false
+	for {
+		select {
+		case <-ticker.C:
+			events, _ := wo.ChannelDepth()
+			capacity := wo.Capacity()
+			full := float64(events) > channelDepthWarnRatio*float64(capacity)
+			if full && !warned {
+				logf("webhook event buffer is %d/%d full, events will be dropped if the subscriber does not keep up", events, capacity)
+			}
+			warned = full
+		case <-wo.completeChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-subCtx.Done():
+			return
+		}
+	}
+}
+
+// Subscribe subscribes to webhook events with callbacks. Cancelling ctx is
+// treated as completion; the returned function stops the subscription
+// without calling complete.
 func (wo *WebhookObservable) Subscribe(
+	ctx context.Context,
 	next WebhookEventCallback,
 	errorHandler WebhookErrorCallback,
 	complete func(),
 ) func() {
-	ctx, cancel := context.WithCancel(context.Background())
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	subCtx, cancel := context.WithCancel(context.Background())
+	go wo.monitorDepth(ctx, subCtx)
 	
 	go func() {
 		for {
 			select {
 			case event := <-wo.eventChan:
-				if err := next(event); err != nil && errorHandler != nil {
-					errorHandler(err)
+				if err := next(ctx, event); err != nil {
+					wo.notifyError(errorHandler, err)
 				}
 			case err := <-wo.errorChan:
-				if errorHandler != nil {
-					errorHandler(err)
-				}
+				wo.notifyError(errorHandler, err)
 			case <-wo.completeChan:
-				if complete != nil {
-					complete()
-				}
+				wo.notifyComplete(complete)
 				return
 			case <-ctx.Done():
+				wo.notifyComplete(complete)
+				return
+			case <-subCtx.Done():
 				return
 			}
 		}
@@ -123,7 +331,12 @@ func (wo *WebhookObservable) Filter(predicate func(map[string]interface{}) bool)
 		for {
 			select {
 			case event := <-wo.eventChan:
-				if predicate(event) {
+				matched, err := matchSafely(predicate, event)
+				if err != nil {
+					wo.subscription.emitError(err)
+					continue
+				}
+				if matched {
 					filtered.eventChan <- event
 				}
 			case err := <-wo.errorChan:
@@ -138,17 +351,56 @@ func (wo *WebhookObservable) Filter(predicate func(map[string]interface{}) bool)
 	return filtered
 }
 
+// matchSafely calls predicate, turning a panic into an error
+func matchSafely(predicate func(map[string]interface{}) bool, event map[string]interface{}) (matched bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("filter predicate panicked: %v", r)
+		}
+	}()
+	return predicate(event), nil
+}
+
+// callSafely calls a subscription callback, turning a panic into an error so
+// one faulty callback cannot take down the webhook server
+func callSafely(callback func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("callback panicked: %v", r)
+		}
+	}()
+	return callback()
+}
+
 // WebhookSubscriptionManager manages webhook subscriptions
 type WebhookSubscriptionManager struct {
 	webhooksAPI       *WebhooksAPI
 	options           SubscriptionOptions
 	server            *http.Server
-	eventCallbacks    []WebhookEventCallback
-	deliveryCallbacks []WebhookDeliveryCallback
-	errorCallbacks    []WebhookErrorCallback
+	eventCallbacks    map[uint64]WebhookEventCallback
+	deliveryCallbacks map[uint64]WebhookDeliveryCallback
+	errorCallbacks    map[uint64]WebhookErrorCallback
+	nextCallbackID    atomic.Uint64
 	webhookID         string
 	isRunning         bool
+	paused            bool
 	observable        *WebhookObservable
+	priorityQueue     *PriorityEventQueue
+	priorityQueueStop chan struct{}
+	priorityQueueDone chan struct{}
+	metrics           subscriptionMetricsCounters
+	state             subscriptionState
+	inflight          sync.WaitGroup
+	inflightCount     atomic.Int64
+	requestID         string
+	eventBus          EventBus
+	idempotencyStore  IdempotencyStore
+	idempotencyMu     sync.Mutex
+	previousSecret    SecretString
+	previousSecretTTL time.Time
+	secretMu          sync.RWMutex
+	paths             map[string]*PathOptions
+	mux               *http.ServeMux
 	mu                sync.RWMutex
 }
 
@@ -189,11 +441,30 @@ func NewWebhookSubscription(webhooksAPI *WebhooksAPI, options *SubscriptionOptio
 		if options.SecretKey != "" {
 			opts.SecretKey = options.SecretKey
 		}
+		if len(options.WorkflowFilter) > 0 {
+			opts.WorkflowFilter = options.WorkflowFilter
+		}
+		opts.DispatchMode = options.DispatchMode
+		opts.ConcurrentDispatchTimeout = options.ConcurrentDispatchTimeout
+		opts.EncryptionKey = options.EncryptionKey
+		opts.SecretGracePeriod = options.SecretGracePeriod
+		if options.StartupTimeout > 0 {
+			opts.StartupTimeout = options.StartupTimeout
+		}
+		opts.VerificationHandler = options.VerificationHandler
+		if options.MaxBodyBytes > 0 {
+			opts.MaxBodyBytes = options.MaxBodyBytes
+		}
+		opts.ExternalURL = options.ExternalURL
 	}
 	
 	ws := &WebhookSubscriptionManager{
-		webhooksAPI: webhooksAPI,
-		options:     opts,
+		webhooksAPI:       webhooksAPI,
+		options:           opts,
+		requestID:         newRequestID(),
+		eventCallbacks:    make(map[uint64]WebhookEventCallback),
+		deliveryCallbacks: make(map[uint64]WebhookDeliveryCallback),
+		errorCallbacks:    make(map[uint64]WebhookErrorCallback),
 		observable: &WebhookObservable{
 			eventChan:    make(chan map[string]interface{}, opts.BufferSize),
 			errorChan:    make(chan error, 10),
@@ -205,60 +476,122 @@ func NewWebhookSubscription(webhooksAPI *WebhooksAPI, options *SubscriptionOptio
 	return ws
 }
 
+// UnsubscribeFn removes the callback it was returned for. Calling it more
+// than once has no effect.
+type UnsubscribeFn func()
+
 // OnEvent subscribes to webhook events with a callback
-func (ws *WebhookSubscriptionManager) OnEvent(callback WebhookEventCallback) func() {
+func (ws *WebhookSubscriptionManager) OnEvent(callback WebhookEventCallback) UnsubscribeFn {
+	id := ws.nextCallbackID.Add(1)
 	ws.mu.Lock()
-	ws.eventCallbacks = append(ws.eventCallbacks, callback)
-	index := len(ws.eventCallbacks) - 1
+	ws.eventCallbacks[id] = callback
 	ws.mu.Unlock()
+	ws.state.eventCallbacks.Add(1)
 	
 	return func() {
 		ws.mu.Lock()
 		defer ws.mu.Unlock()
-		if index < len(ws.eventCallbacks) {
-			ws.eventCallbacks = append(ws.eventCallbacks[:index], ws.eventCallbacks[index+1:]...)
+		if _, ok := ws.eventCallbacks[id]; ok {
+			delete(ws.eventCallbacks, id)
+			ws.state.eventCallbacks.Add(-1)
 		}
 	}
 }
 
 // OnDelivery subscribes to webhook deliveries with a callback
-func (ws *WebhookSubscriptionManager) OnDelivery(callback WebhookDeliveryCallback) func() {
+func (ws *WebhookSubscriptionManager) OnDelivery(callback WebhookDeliveryCallback) UnsubscribeFn {
+	id := ws.nextCallbackID.Add(1)
 	ws.mu.Lock()
-	ws.deliveryCallbacks = append(ws.deliveryCallbacks, callback)
-	index := len(ws.deliveryCallbacks) - 1
+	ws.deliveryCallbacks[id] = callback
 	ws.mu.Unlock()
+	ws.state.deliveryCallbacks.Add(1)
 	
 	return func() {
 		ws.mu.Lock()
 		defer ws.mu.Unlock()
-		if index < len(ws.deliveryCallbacks) {
-			ws.deliveryCallbacks = append(ws.deliveryCallbacks[:index], ws.deliveryCallbacks[index+1:]...)
+		if _, ok := ws.deliveryCallbacks[id]; ok {
+			delete(ws.deliveryCallbacks, id)
+			ws.state.deliveryCallbacks.Add(-1)
 		}
 	}
 }
 
 // OnError subscribes to errors with a callback
-func (ws *WebhookSubscriptionManager) OnError(callback WebhookErrorCallback) func() {
+func (ws *WebhookSubscriptionManager) OnError(callback WebhookErrorCallback) UnsubscribeFn {
+	id := ws.nextCallbackID.Add(1)
 	ws.mu.Lock()
-	ws.errorCallbacks = append(ws.errorCallbacks, callback)
-	index := len(ws.errorCallbacks) - 1
+	ws.errorCallbacks[id] = callback
 	ws.mu.Unlock()
+	ws.state.errorCallbacks.Add(1)
 	
 	return func() {
 		ws.mu.Lock()
 		defer ws.mu.Unlock()
-		if index < len(ws.errorCallbacks) {
-			ws.errorCallbacks = append(ws.errorCallbacks[:index], ws.errorCallbacks[index+1:]...)
+		if _, ok := ws.errorCallbacks[id]; ok {
+			delete(ws.errorCallbacks, id)
+			ws.state.errorCallbacks.Add(-1)
 		}
 	}
 }
 
+// orderedCallbacks returns a copy of callbacks in registration order
+func orderedCallbacks[T any](callbacks map[uint64]T) []T {
+	ids := make([]uint64, 0, len(callbacks))
+	for id := range callbacks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	ordered := make([]T, len(ids))
+	for i, id := range ids {
+		ordered[i] = callbacks[id]
+	}
+	return ordered
+}
+
 // AsObservable returns the observable interface
 func (ws *WebhookSubscriptionManager) AsObservable() *WebhookObservable {
 	return ws.observable
 }
 
-// Start starts the webhook server
+// waitForListener dials host:port until it accepts connections, the server
+// reports an error on serveErr, or timeout elapses
+func waitForListener(host string, port int, timeout time.Duration, serveErr <-chan error) error {
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case err := <-serveErr:
+			return err
+		default:
+		}
+		
+		conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			// Serve fails fast on bad TLS configuration; catch that too
+			select {
+			case err := <-serveErr:
+				return err
+			case <-time.After(10 * time.Millisecond):
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("server not listening on %s after %v: %w", addr, timeout, err)
+		}
+		
+		select {
+		case err := <-serveErr:
+			return err
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// Start starts the webhook server and waits until it accepts connections
 func (ws *WebhookSubscriptionManager) Start() error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
@@ -269,6 +602,9 @@ func (ws *WebhookSubscriptionManager) Start() error {
 	
 	mux := http.NewServeMux()
 	mux.HandleFunc(ws.options.Path, ws.webhookHandler)
+	for path, pathOpts := range ws.paths {
+		mux.HandleFunc(path, ws.pathHandler(pathOpts))
+	}
 	
 	addr := fmt.Sprintf("%s:%d", ws.options.Host, ws.options.Port)
 	ws.server = &http.Server{
@@ -276,33 +612,39 @@ func (ws *WebhookSubscriptionManager) Start() error {
 		Handler: mux,
 	}
 	
-	var err error
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		ws.server = nil
+		return fmt.Errorf("failed to start webhook server: %w", err)
+	}
+	
+	serveErr := make(chan error, 1)
+	server := ws.server
 	if ws.options.HTTPS && ws.options.Key != "" && ws.options.Cert != "" {
 		go func() {
-			err = ws.server.ListenAndServeTLS(ws.options.Cert, ws.options.Key)
+			serveErr <- server.ServeTLS(listener, ws.options.Cert, ws.options.Key)
 		}()
 	} else {
 		go func() {
-			err = ws.server.ListenAndServe()
+			serveErr <- server.Serve(listener)
 		}()
 	}
 	
-	if err != nil && err != http.ErrServerClosed {
+	if err := waitForListener(ws.options.Host, ws.options.Port, ws.options.StartupTimeout, serveErr); err != nil {
+		server.Close()
+		ws.server = nil
 		return fmt.Errorf("failed to start webhook server: %w", err)
 	}
 	
-	// Give the server a moment to start
-	time.Sleep(100 * time.Millisecond)
-	
+	ws.mux = mux
 	ws.isRunning = true
+	ws.state.setRunning(true)
 	fmt.Printf("Webhook server listening on %s%s\n", addr, ws.options.Path)
 	
 	// Auto-register webhook if enabled
 	if ws.options.AutoRegister {
 		go func() {
-			// Small delay to ensure server is fully started
-			time.Sleep(200 * time.Millisecond)
-			if err := ws.Register(); err != nil {
+			if _, err := ws.Register(nil); err != nil {
 				ws.emitError(fmt.Errorf("failed to auto-register webhook: %w", err))
 			}
 		}()
@@ -311,77 +653,152 @@ func (ws *WebhookSubscriptionManager) Start() error {
 	return nil
 }
 
-// Stop stops the webhook server
+// Stop stops the webhook server, allowing up to 5 seconds for in-flight
+// deliveries to finish
 func (ws *WebhookSubscriptionManager) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return ws.StopGracefully(ctx)
+}
+
+// StopGracefully stops accepting new deliveries, then waits for in-flight
+// deliveries to be processed before closing the observable. If ctx expires
+// first, remaining deliveries are abandoned.
+func (ws *WebhookSubscriptionManager) StopGracefully(ctx context.Context) error {
 	ws.mu.Lock()
-	defer ws.mu.Unlock()
-	
 	if !ws.isRunning {
+		ws.mu.Unlock()
+		ws.stopPriorityQueue()
 		return nil
 	}
+	webhookID := ws.webhookID
+	server := ws.server
+	ws.webhookID = ""
+	ws.isRunning = false
+	ws.mu.Unlock()
+	ws.state.setRunning(false)
+	ws.state.setWebhookID("")
 	
 	// Unregister webhook if it was registered
-	if ws.webhookID != "" {
-		if _, err := ws.webhooksAPI.Delete(context.Background(), ws.webhookID); err != nil {
-			fmt.Printf("Failed to unregister webhook %s: %v\n", ws.webhookID, err)
+	if webhookID != "" {
+		if _, err := ws.webhooksAPI.Delete(WithRequestID(ctx, ws.requestID), webhookID); err != nil {
+			fmt.Printf("Failed to unregister webhook %s: %v\n", webhookID, err)
 		} else {
-			fmt.Printf("Unregistered webhook %s\n", ws.webhookID)
+			fmt.Printf("Unregistered webhook %s\n", webhookID)
 		}
-		ws.webhookID = ""
 	}
 	
-	// Stop the server
-	if ws.server != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		
-		if err := ws.server.Shutdown(ctx); err != nil {
-			return fmt.Errorf("failed to shutdown webhook server: %w", err)
+	// Stop accepting new connections
+	var shutdownErr error
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			shutdownErr = fmt.Errorf("failed to shutdown webhook server: %w", err)
 		}
+	}
+	
+	// Drain in-flight deliveries
+	drained := make(chan struct{})
+	go func() {
+		ws.inflight.Wait()
+		close(drained)
+	}()
+	
+	select {
+	case <-drained:
 		fmt.Println("Webhook server stopped")
+	case <-ctx.Done():
+		logf("webhook server stopped with %d in-flight deliveries dropped", ws.inflightCount.Load())
 	}
 	
-	ws.isRunning = false
-	close(ws.observable.completeChan)
+	ws.stopPriorityQueue()
+	ws.observable.complete()
 	
+	return shutdownErr
+}
+
+// ErrInvalidWebhookURL is returned when registering an external webhook URL
+// that is not an absolute http or https URL
+var ErrInvalidWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+
+// validateWebhookURL checks that raw is an http or https URL with a host
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidWebhookURL, raw)
+	}
 	return nil
 }
 
-// Register registers the webhook with Zeal
-func (ws *WebhookSubscriptionManager) Register() error {
+// Register registers the webhook with Zeal. Non-empty fields of cfg override
+// the namespace, URL, events, headers and metadata derived from the
+// subscription options; cfg may be nil. The URL is options.ExternalURL when
+// set, otherwise it is built from the host, port and path.
+func (ws *WebhookSubscriptionManager) Register(cfg *WebhookConfig) (*WebhookRegistrationResult, error) {
 	if !ws.isRunning {
-		return fmt.Errorf("webhook server must be running before registration")
+		return nil, fmt.Errorf("webhook server must be running before registration")
 	}
 	
 	// Determine the public URL for the webhook
-	protocol := "http"
-	if ws.options.HTTPS {
-		protocol = "https"
-	}
-	
-	host := ws.options.Host
-	if host == "0.0.0.0" {
-		host = "localhost"
+	webhookURL := ws.options.ExternalURL
+	if webhookURL != "" {
+		if err := validateWebhookURL(webhookURL); err != nil {
+			return nil, err
+		}
+	} else {
+		protocol := "http"
+		if ws.options.HTTPS {
+			protocol = "https"
+		}
+		
+		host := ws.options.Host
+		if host == "0.0.0.0" {
+			host = "localhost"
+		}
+		webhookURL = fmt.Sprintf("%s://%s:%d%s", protocol, host, ws.options.Port, ws.options.Path)
 	}
 	
-	webhookURL := fmt.Sprintf("%s://%s:%d%s", protocol, host, ws.options.Port, ws.options.Path)
-	
-	// Register with Zeal
 	req := CreateWebhookRequest{
-		URL:     webhookURL,
-		Events:  ws.options.Events,
-		Headers: ws.options.Headers,
+		Namespace: ws.options.Namespace,
+		URL:       webhookURL,
+		Events:    ws.options.Events,
+		Headers:   ws.options.Headers,
+	}
+	if cfg != nil {
+		if cfg.Namespace != "" {
+			req.Namespace = cfg.Namespace
+		}
+		if cfg.URL != "" {
+			req.URL = cfg.URL
+		}
+		if len(cfg.Events) > 0 {
+			req.Events = cfg.Events
+		}
+		if cfg.Headers != nil {
+			req.Headers = cfg.Headers
+		}
+		req.Metadata = cfg.Metadata
 	}
 	
-	result, err := ws.webhooksAPI.Create(context.Background(), req)
+	// Register with Zeal
+	result, err := ws.webhooksAPI.Create(WithRequestID(context.Background(), ws.requestID), req)
 	if err != nil {
-		return fmt.Errorf("failed to register webhook: %w", err)
+		return nil, fmt.Errorf("failed to register webhook: %w", err)
 	}
 	
 	ws.webhookID = result.Subscription.ID
-	fmt.Printf("Registered webhook %s at %s\n", ws.webhookID, webhookURL)
+	ws.state.setWebhookID(ws.webhookID)
+	fmt.Printf("Registered webhook %s at %s\n", ws.webhookID, req.URL)
 	
-	return nil
+	return &WebhookRegistrationResult{WebhookID: ws.webhookID, URL: req.URL}, nil
+}
+
+// RegisterWithURL registers the webhook with Zeal at externalURL, used
+// verbatim, instead of the URL derived from the subscription options
+func (ws *WebhookSubscriptionManager) RegisterWithURL(externalURL string) (*WebhookRegistrationResult, error) {
+	if err := validateWebhookURL(externalURL); err != nil {
+		return nil, err
+	}
+	return ws.Register(&WebhookConfig{URL: externalURL})
 }
 
 // IsRunning returns whether the subscription is running
@@ -391,6 +808,45 @@ func (ws *WebhookSubscriptionManager) IsRunning() bool {
 	return ws.isRunning
 }
 
+// Pause stops processing incoming deliveries without stopping the server or
+// unregistering the webhook. Deliveries received while paused are answered
+// with 503 Service Unavailable so Zeal retries them later.
+func (ws *WebhookSubscriptionManager) Pause() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.paused {
+		return fmt.Errorf("webhook subscription is already paused")
+	}
+	ws.paused = true
+	ws.state.setPaused(true)
+	return nil
+}
+
+// Resume resumes processing deliveries after Pause
+func (ws *WebhookSubscriptionManager) Resume() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if !ws.paused {
+		return fmt.Errorf("webhook subscription is not paused")
+	}
+	ws.paused = false
+	ws.state.setPaused(false)
+	return nil
+}
+
+// IsPaused returns whether delivery processing is paused
+func (ws *WebhookSubscriptionManager) IsPaused() bool {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return ws.paused
+}
+
+// RequestID returns the ID sent as X-Request-ID when registering and
+// unregistering the webhook
+func (ws *WebhookSubscriptionManager) RequestID() string {
+	return ws.requestID
+}
+
 // WebhookID returns the current webhook ID if registered
 func (ws *WebhookSubscriptionManager) WebhookID() string {
 	ws.mu.RLock()
@@ -405,10 +861,10 @@ func (ws *WebhookSubscriptionManager) OnEventType(eventTypes []string, callback
 		eventTypeSet[eventType] = true
 	}
 	
-	filteredCallback := func(event map[string]interface{}) error {
+	filteredCallback := func(ctx context.Context, event map[string]interface{}) error {
 		if eventTypeStr, ok := event["type"].(string); ok {
 			if eventTypeSet[eventTypeStr] {
-				return callback(event)
+				return callback(ctx, event)
 			}
 		}
 		return nil
@@ -424,10 +880,10 @@ func (ws *WebhookSubscriptionManager) OnEventSource(sources []string, callback W
 		sourceSet[source] = true
 	}
 	
-	filteredCallback := func(event map[string]interface{}) error {
+	filteredCallback := func(ctx context.Context, event map[string]interface{}) error {
 		if workflowID, ok := event["workflowId"].(string); ok {
 			if sourceSet[workflowID] {
-				return callback(event)
+				return callback(ctx, event)
 			}
 		}
 		return nil
@@ -441,23 +897,84 @@ func (ws *WebhookSubscriptionManager) FilterEvents(predicate func(map[string]int
 	return ws.observable.Filter(predicate)
 }
 
+// AddPath serves deliveries on an additional path, with its own signature
+// settings and tag. Events from every path reach the same callbacks and
+// observable. Paths can be added before or after Start.
+func (ws *WebhookSubscriptionManager) AddPath(path string, opts *PathOptions) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("webhook path must start with /: %q", path)
+	}
+	if opts == nil {
+		opts = &PathOptions{}
+	}
+	
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if _, exists := ws.paths[path]; exists || path == ws.options.Path {
+		return fmt.Errorf("webhook path %s is already registered", path)
+	}
+	if ws.paths == nil {
+		ws.paths = make(map[string]*PathOptions)
+	}
+	pathOpts := *opts
+	ws.paths[path] = &pathOpts
+	if ws.mux != nil {
+		ws.mux.HandleFunc(path, ws.pathHandler(&pathOpts))
+	}
+	return nil
+}
+
+// pathHandler returns the HTTP handler for a path added with AddPath
+func (ws *WebhookSubscriptionManager) pathHandler(opts *PathOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ws.serveWebhook(w, r, opts)
+	}
+}
+
 func (ws *WebhookSubscriptionManager) webhookHandler(w http.ResponseWriter, r *http.Request) {
+	ws.serveWebhook(w, r, nil)
+}
+
+// serveWebhook handles a delivery on the main path (opts nil) or on a path
+// added with AddPath
+func (ws *WebhookSubscriptionManager) serveWebhook(w http.ResponseWriter, r *http.Request, opts *PathOptions) {
+	if r.Method == http.MethodGet && ws.options.VerificationHandler != nil {
+		ws.options.VerificationHandler(w, r)
+		return
+	}
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+
+	if ws.IsPaused() {
+		http.Error(w, "Webhook processing is paused", http.StatusServiceUnavailable)
+		return
+	}
 	
-	// Read the request body
-	body, err := io.ReadAll(r.Body)
+	// Read the request body, reading one byte past the limit to detect
+	// oversized bodies
+	body, err := io.ReadAll(io.LimitReader(r.Body, ws.options.MaxBodyBytes+1))
 	if err != nil {
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		ws.emitError(fmt.Errorf("failed to read request body: %w", err))
 		return
 	}
 	defer r.Body.Close()
+	if int64(len(body)) > ws.options.MaxBodyBytes {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		ws.emitError(fmt.Errorf("webhook body on %s exceeds %d bytes", r.URL.Path, ws.options.MaxBodyBytes))
+		return
+	}
 	
 	// Verify signature if enabled
-	if ws.options.VerifySignature && ws.options.SecretKey != "" {
+	if opts != nil {
+		if opts.VerifySignature && opts.SecretKey != "" && !signatureMatches(body, r.Header.Get("X-Zeal-Signature"), opts.SecretKey) {
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			ws.emitError(fmt.Errorf("invalid webhook signature on %s", r.URL.Path))
+			return
+		}
+	} else if ws.options.VerifySignature && ws.hasSecretKey() {
 		signature := r.Header.Get("X-Zeal-Signature")
 		if !ws.verifySignature(body, signature) {
 			http.Error(w, "Invalid signature", http.StatusUnauthorized)
@@ -466,68 +983,272 @@ func (ws *WebhookSubscriptionManager) webhookHandler(w http.ResponseWriter, r *h
 		}
 	}
 	
+	// Decrypt the body if encryption is enabled
+	if len(ws.options.EncryptionKey) > 0 {
+		body, err = DecryptWebhookPayload(body, ws.options.EncryptionKey)
+		if err != nil {
+			http.Error(w, "Failed to decrypt request body", http.StatusBadRequest)
+			ws.emitError(err)
+			return
+		}
+	}
+
 	// Parse the delivery
-	var delivery WebhookDelivery
-	if err := json.Unmarshal(body, &delivery); err != nil {
+	delivery := deliveryPool.Get()
+	if err := eventJSONCodec().Unmarshal(body, delivery); err != nil {
+		deliveryPool.Put(delivery)
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		ws.emitError(fmt.Errorf("failed to parse webhook delivery: %w", err))
 		return
 	}
+	delivery.Metadata.RequestID = requestIDFromHeaders(r.Header)
+	if opts != nil && opts.Tag != "" {
+		tagDelivery(delivery, opts.Tag)
+	}
+	
+	// Callbacks run after the response is written, so keep the request's
+	// values but not its cancellation
+	ctx := context.WithoutCancel(r.Context())
+	ctx = WithRequestID(ctx, delivery.Metadata.RequestID)
+	ctx = WithDeliveryID(ctx, delivery.Metadata.DeliveryID)
 	
 	// Process the delivery
-	go ws.processDelivery(delivery)
+	ws.inflight.Add(1)
+	ws.inflightCount.Add(1)
+	go func() {
+		defer ws.inflight.Done()
+		defer ws.inflightCount.Add(-1)
+		defer deliveryPool.Put(delivery)
+		defer func() {
+			if r := recover(); r != nil {
+				ws.emitError(fmt.Errorf("delivery processing panicked: %v", r))
+			}
+		}()
+		ws.processDelivery(ctx, *delivery)
+	}()
 	
 	// Send success response
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
 
-func (ws *WebhookSubscriptionManager) processDelivery(delivery WebhookDelivery) {
+// OnEventGraph subscribes to events of one graph within a workflow. Events
+// without a graphId belong to the "main" graph, as does an empty graphID.
+func (ws *WebhookSubscriptionManager) OnEventGraph(workflowID, graphID string, callback WebhookEventCallback) func() {
+	filteredCallback := func(ctx context.Context, event map[string]interface{}) error {
+		if eventWorkflowID, _ := event["workflowId"].(string); eventWorkflowID != workflowID {
+			return nil
+		}
+		var base ZipEventBase
+		if eventGraphID, ok := event["graphId"].(string); ok {
+			base.GraphID = &eventGraphID
+		}
+		if !base.HasGraph(string(ParseGraphID(graphID))) {
+			return nil
+		}
+		return callback(ctx, event)
+	}
+	
+	return ws.OnEvent(filteredCallback)
+}
+
+// DispatchDelivery processes a delivery as if it had been received by the
+// webhook server, invoking delivery and event callbacks with ctx and the
+// observable
+func (ws *WebhookSubscriptionManager) DispatchDelivery(ctx context.Context, delivery WebhookDelivery) {
+	ws.processDelivery(ctx, delivery)
+}
+
+// ForwardTo forwards events received by this manager to dst. A nil filter
+// forwards every event. The returned function stops forwarding without
+// stopping either manager.
+func (ws *WebhookSubscriptionManager) ForwardTo(dst *WebhookSubscriptionManager, filter func(map[string]interface{}) bool) func() {
+	return ws.OnEvent(func(ctx context.Context, event map[string]interface{}) error {
+		if filter != nil && !filter(event) {
+			return nil
+		}
+		dst.DispatchDelivery(ctx, WebhookDelivery{
+			WebhookID: ws.WebhookID(),
+			Events:    []map[string]interface{}{event},
+			Metadata: WebhookMetadata{
+				Namespace: ws.options.Namespace,
+				Timestamp: currentTimestamp(),
+			},
+		})
+		return nil
+	})
+}
+
+// lateDeliveryThreshold is the delivery age above which processDelivery warns
+const lateDeliveryThreshold = 5 * time.Minute
+
+func (ws *WebhookSubscriptionManager) processDelivery(ctx context.Context, delivery WebhookDelivery) {
+	if ws.isDuplicateDelivery(delivery.Metadata.DeliveryID) {
+		return
+	}
+
+	if age, err := delivery.Age(); err == nil && age > lateDeliveryThreshold {
+		logf("delivery %s processed %s after its timestamp, the event queue may be backed up",
+			delivery.Metadata.DeliveryID, age.Round(time.Second))
+	}
+
+	start := time.Now()
+	defer ws.metrics.recordDelivery(start)
+
 	// Call delivery callbacks
 	ws.mu.RLock()
-	deliveryCallbacks := make([]WebhookDeliveryCallback, len(ws.deliveryCallbacks))
-	copy(deliveryCallbacks, ws.deliveryCallbacks)
+	deliveryCallbacks := orderedCallbacks(ws.deliveryCallbacks)
 	ws.mu.RUnlock()
 	
 	for _, callback := range deliveryCallbacks {
-		if err := callback(delivery); err != nil {
+		if err := callSafely(func() error { return callback(ctx, delivery) }); err != nil {
+			ws.metrics.deliveryErrors.Add(1)
 			ws.emitError(fmt.Errorf("delivery callback error: %w", err))
 		}
 	}
 	
 	// Process individual events
 	for _, event := range delivery.Events {
+		if !ws.matchesWorkflowFilter(event) {
+			continue
+		}
+		ws.metrics.recordEvent()
+
 		// Send to observable
-		select {
-		case ws.observable.eventChan <- event:
-		default:
-			// Channel is full, skip this event
-			ws.emitError(fmt.Errorf("event channel is full, skipping event"))
+		ws.mu.RLock()
+		queue := ws.priorityQueue
+		ws.mu.RUnlock()
+
+		if queue != nil {
+			evicted, accepted := queue.Push(event)
+			if !accepted {
+				ws.metrics.eventsDropped.Add(1)
+				ws.emitError(fmt.Errorf("event queue is full, skipping event"))
+			} else if evicted != nil {
+				ws.metrics.eventsDropped.Add(1)
+				ws.emitError(fmt.Errorf("event queue is full, evicted lower priority event"))
+			}
+		} else {
+			select {
+			case ws.observable.eventChan <- event:
+			default:
+				// Channel is full, skip this event
+				ws.metrics.eventsDropped.Add(1)
+				ws.emitError(fmt.Errorf("event channel is full, skipping event"))
+			}
 		}
 		
 		// Call event callbacks
 		ws.mu.RLock()
-		eventCallbacks := make([]WebhookEventCallback, len(ws.eventCallbacks))
-		copy(eventCallbacks, ws.eventCallbacks)
+		eventCallbacks := orderedCallbacks(ws.eventCallbacks)
 		ws.mu.RUnlock()
 		
-		for _, callback := range eventCallbacks {
-			if err := callback(event); err != nil {
+		ws.dispatchEvent(ctx, event, eventCallbacks)
+		ws.metrics.eventsProcessed.Add(1)
+	}
+
+	ws.mu.RLock()
+	bus := ws.eventBus
+	ws.mu.RUnlock()
+	if bus != nil {
+		ws.publishParsedEvents(bus, delivery)
+	}
+}
+
+// publishParsedEvents publishes the typed events of a delivery to bus
+func (ws *WebhookSubscriptionManager) publishParsedEvents(bus EventBus, delivery WebhookDelivery) {
+	events, errs := delivery.ParsedEvents()
+	for _, err := range errs {
+		ws.emitError(fmt.Errorf("failed to parse webhook event: %w", err))
+	}
+	for _, event := range events {
+		if !ws.matchesWorkflowID(event.GetWorkflowID()) {
+			continue
+		}
+		if err := bus.Publish(event); err != nil {
+			ws.metrics.deliveryErrors.Add(1)
+			ws.emitError(fmt.Errorf("event bus error: %w", err))
+		}
+	}
+}
+
+// dispatchEvent invokes the event callbacks according to the dispatch mode
+func (ws *WebhookSubscriptionManager) dispatchEvent(ctx context.Context, event map[string]interface{}, callbacks []WebhookEventCallback) {
+	if ws.options.DispatchMode != DispatchConcurrent {
+		for _, callback := range callbacks {
+			if err := callSafely(func() error { return callback(ctx, event) }); err != nil {
+				ws.metrics.deliveryErrors.Add(1)
 				ws.emitError(fmt.Errorf("event callback error: %w", err))
 			}
 		}
+		return
+	}
+
+	timeout := ws.options.ConcurrentDispatchTimeout
+	errs := make(chan error, len(callbacks))
+	var wg sync.WaitGroup
+	for i, callback := range callbacks {
+		wg.Add(1)
+		go func(i int, callback WebhookEventCallback) {
+			defer wg.Done()
+
+			done := make(chan error, 1)
+			go func() { done <- callSafely(func() error { return callback(ctx, event) }) }()
+
+			var expired <-chan time.Time
+			if timeout > 0 {
+				timer := time.NewTimer(timeout)
+				defer timer.Stop()
+				expired = timer.C
+			}
+
+			select {
+			case err := <-done:
+				if err != nil {
+					errs <- fmt.Errorf("event callback error: %w", err)
+				}
+			case <-expired:
+				errs <- fmt.Errorf("event callback %d timed out after %s", i, timeout)
+			}
+		}(i, callback)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		ws.metrics.deliveryErrors.Add(1)
+		ws.emitError(err)
+	}
+}
+
+// matchesWorkflowFilter reports whether an event passes the WorkflowFilter option.
+// An empty filter accepts events from all workflows.
+func (ws *WebhookSubscriptionManager) matchesWorkflowFilter(event map[string]interface{}) bool {
+	workflowID, _ := event["workflowId"].(string)
+	return ws.matchesWorkflowID(workflowID)
+}
+
+// matchesWorkflowID reports whether workflowID passes the WorkflowFilter option
+func (ws *WebhookSubscriptionManager) matchesWorkflowID(workflowID string) bool {
+	if len(ws.options.WorkflowFilter) == 0 {
+		return true
+	}
+	for _, id := range ws.options.WorkflowFilter {
+		if id == workflowID {
+			return true
+		}
 	}
+	return false
 }
 
 func (ws *WebhookSubscriptionManager) emitError(err error) {
 	// Call error callbacks
 	ws.mu.RLock()
-	errorCallbacks := make([]WebhookErrorCallback, len(ws.errorCallbacks))
-	copy(errorCallbacks, ws.errorCallbacks)
+	errorCallbacks := orderedCallbacks(ws.errorCallbacks)
 	ws.mu.RUnlock()
 	
 	for _, callback := range errorCallbacks {
-		if callbackErr := callback(err); callbackErr != nil {
+		if callbackErr := callSafely(func() error { return callback(err) }); callbackErr != nil {
 			fmt.Printf("Error callback failed: %v\n", callbackErr)
 		}
 	}
@@ -541,11 +1262,50 @@ func (ws *WebhookSubscriptionManager) emitError(err error) {
 	}
 }
 
+// UpdateSecretKey replaces the secret used to verify webhook signatures.
+// Signatures made with the previous secret are still accepted for
+// SubscriptionOptions.SecretGracePeriod.
+func (ws *WebhookSubscriptionManager) UpdateSecretKey(newSecret string) {
+	ws.secretMu.Lock()
+	defer ws.secretMu.Unlock()
+	ws.previousSecret = ws.options.SecretKey
+	ws.previousSecretTTL = time.Now().Add(ws.options.SecretGracePeriod)
+	ws.options.SecretKey = SecretString(newSecret)
+}
+
+// hasSecretKey reports whether a signing secret is configured
+func (ws *WebhookSubscriptionManager) hasSecretKey() bool {
+	ws.secretMu.RLock()
+	defer ws.secretMu.RUnlock()
+	return ws.options.SecretKey != ""
+}
+
+// signingSecrets returns the current secret followed by the previous one
+// while it is within its grace period
+func (ws *WebhookSubscriptionManager) signingSecrets() []SecretString {
+	ws.secretMu.RLock()
+	defer ws.secretMu.RUnlock()
+	var secrets []SecretString
+	if ws.options.SecretKey != "" {
+		secrets = append(secrets, ws.options.SecretKey)
+	}
+	if ws.previousSecret != "" && time.Now().Before(ws.previousSecretTTL) {
+		secrets = append(secrets, ws.previousSecret)
+	}
+	return secrets
+}
+
 func (ws *WebhookSubscriptionManager) verifySignature(body []byte, signature string) bool {
-	if ws.options.SecretKey == "" {
-		return false
+	for _, secret := range ws.signingSecrets() {
+		if signatureMatches(body, signature, secret) {
+			return true
+		}
 	}
-	
+	return false
+}
+
+// signatureMatches checks a "sha256=<hex>" signature of body against secret
+func signatureMatches(body []byte, signature string, secret SecretString) bool {
 	// Parse the signature (format: "sha256=...")
 	if !strings.HasPrefix(signature, "sha256=") {
 		return false
@@ -554,7 +1314,7 @@ func (ws *WebhookSubscriptionManager) verifySignature(body []byte, signature str
 	expectedSig := signature[7:] // Remove "sha256=" prefix
 	
 	// Calculate HMAC
-	mac := hmac.New(sha256.New, []byte(ws.options.SecretKey))
+	mac := hmac.New(sha256.New, []byte(secret.Reveal()))
 	mac.Write(body)
 	calculatedSig := hex.EncodeToString(mac.Sum(nil))
 	
@@ -562,6 +1322,19 @@ func (ws *WebhookSubscriptionManager) verifySignature(body []byte, signature str
 	return hmac.Equal([]byte(expectedSig), []byte(calculatedSig))
 }
 
+// tagDelivery records the path tag on the delivery and each of its events
+func tagDelivery(delivery *WebhookDelivery, tag string) {
+	delivery.Metadata.Tag = tag
+	for _, event := range delivery.Events {
+		metadata, ok := event["metadata"].(map[string]interface{})
+		if !ok {
+			metadata = make(map[string]interface{})
+			event["metadata"] = metadata
+		}
+		metadata["tag"] = tag
+	}
+}
+
 // WebhookConfig represents webhook configuration for registration
 type WebhookConfig struct {
 	Namespace string            `json:"namespace"`
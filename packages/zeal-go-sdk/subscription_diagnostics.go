@@ -0,0 +1,78 @@
+package zeal
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SubscriptionDiagnostics is a snapshot of a subscription's internal state,
+// see DumpState
+type SubscriptionDiagnostics struct {
+	IsRunning             bool       `json:"isRunning"`
+	WebhookID             string     `json:"webhookId,omitempty"`
+	IsPaused              bool       `json:"isPaused"`
+	EventCallbackCount    int        `json:"eventCallbackCount"`
+	DeliveryCallbackCount int        `json:"deliveryCallbackCount"`
+	ErrorCallbackCount    int        `json:"errorCallbackCount"`
+	EventChannelLen       int        `json:"eventChannelLen"`
+	EventChannelCap       int        `json:"eventChannelCap"`
+	RegisteredAt          *time.Time `json:"registeredAt,omitempty"`
+	LastEventAt           *time.Time `json:"lastEventAt,omitempty"`
+	TotalEventsProcessed  uint64     `json:"totalEventsProcessed"` // events whose callbacks have returned
+}
+
+// subscriptionState mirrors the mutex-guarded manager state in atomics so
+// DumpState can read it while the manager lock is held, e.g. during Start
+type subscriptionState struct {
+	running           atomic.Bool
+	paused            atomic.Bool
+	webhookID         atomic.Value // string
+	registeredAtNs    atomic.Int64
+	eventCallbacks    atomic.Int64
+	deliveryCallbacks atomic.Int64
+	errorCallbacks    atomic.Int64
+}
+
+func (s *subscriptionState) setRunning(running bool) {
+	s.running.Store(running)
+}
+
+func (s *subscriptionState) setPaused(paused bool) {
+	s.paused.Store(paused)
+}
+
+// setWebhookID records a registration, or its removal when id is empty
+func (s *subscriptionState) setWebhookID(id string) {
+	s.webhookID.Store(id)
+	var registeredAtNs int64
+	if id != "" {
+		registeredAtNs = time.Now().UnixNano()
+	}
+	s.registeredAtNs.Store(registeredAtNs)
+}
+
+// DumpState returns a snapshot of the subscription's state for diagnosing a
+// stuck subscription. It never blocks: every field is read atomically, so
+// fields may reflect slightly different instants.
+func (ws *WebhookSubscriptionManager) DumpState() *SubscriptionDiagnostics {
+	state := &ws.state
+	diagnostics := &SubscriptionDiagnostics{
+		IsRunning:             state.running.Load(),
+		IsPaused:              state.paused.Load(),
+		EventCallbackCount:    int(state.eventCallbacks.Load()),
+		DeliveryCallbackCount: int(state.deliveryCallbacks.Load()),
+		ErrorCallbackCount:    int(state.errorCallbacks.Load()),
+		EventChannelLen:       len(ws.observable.eventChan),
+		EventChannelCap:       cap(ws.observable.eventChan),
+	}
+	diagnostics.WebhookID, _ = state.webhookID.Load().(string)
+	if ns := state.registeredAtNs.Load(); ns > 0 {
+		registeredAt := time.Unix(0, ns)
+		diagnostics.RegisteredAt = &registeredAt
+	}
+
+	metrics := ws.metrics.snapshot()
+	diagnostics.LastEventAt = metrics.LastEventAt
+	diagnostics.TotalEventsProcessed = ws.metrics.eventsProcessed.Load()
+	return diagnostics
+}
@@ -0,0 +1,87 @@
+package zeal
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SubscriptionMetrics is a snapshot of webhook subscription instrumentation
+type SubscriptionMetrics struct {
+	EventsReceived      uint64     `json:"eventsReceived"`
+	EventsDropped       uint64     `json:"eventsDropped"`
+	DeliveryErrors      uint64     `json:"deliveryErrors"`
+	AverageProcessingNs int64      `json:"averageProcessingNs"`
+	LastEventAt         *time.Time `json:"lastEventAt,omitempty"`
+	IsPaused            bool       `json:"isPaused"`
+	EventChannelDepth   int        `json:"eventChannelDepth"` // sampled when Metrics is called
+}
+
+// subscriptionMetricsCounters holds the live counters, updated atomically
+type subscriptionMetricsCounters struct {
+	eventsReceived    atomic.Uint64
+	eventsProcessed   atomic.Uint64
+	eventsDropped     atomic.Uint64
+	deliveryErrors    atomic.Uint64
+	deliveries        atomic.Int64
+	totalProcessingNs atomic.Int64
+	lastEventAtNs     atomic.Int64
+}
+
+func (m *subscriptionMetricsCounters) recordEvent() {
+	m.eventsReceived.Add(1)
+	m.lastEventAtNs.Store(time.Now().UnixNano())
+}
+
+func (m *subscriptionMetricsCounters) recordDelivery(start time.Time) {
+	m.totalProcessingNs.Add(int64(time.Since(start)))
+	m.deliveries.Add(1)
+}
+
+func (m *subscriptionMetricsCounters) snapshot() SubscriptionMetrics {
+	metrics := SubscriptionMetrics{
+		EventsReceived: m.eventsReceived.Load(),
+		EventsDropped:  m.eventsDropped.Load(),
+		DeliveryErrors: m.deliveryErrors.Load(),
+	}
+	if deliveries := m.deliveries.Load(); deliveries > 0 {
+		metrics.AverageProcessingNs = m.totalProcessingNs.Load() / deliveries
+	}
+	if lastNs := m.lastEventAtNs.Load(); lastNs > 0 {
+		lastEventAt := time.Unix(0, lastNs)
+		metrics.LastEventAt = &lastEventAt
+	}
+	return metrics
+}
+
+// Metrics returns a snapshot of the subscription metrics
+func (ws *WebhookSubscriptionManager) Metrics() SubscriptionMetrics {
+	metrics := ws.metrics.snapshot()
+	metrics.IsPaused = ws.IsPaused()
+	metrics.EventChannelDepth, _ = ws.observable.ChannelDepth()
+	return metrics
+}
+
+// OnMetricsSnapshot calls fn with a metrics snapshot every interval until the
+// returned function is called
+func (ws *WebhookSubscriptionManager) OnMetricsSnapshot(interval time.Duration, fn func(SubscriptionMetrics)) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn(ws.Metrics())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
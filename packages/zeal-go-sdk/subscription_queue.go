@@ -0,0 +1,248 @@
+package zeal
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// EventPriorityFn assigns a priority to a webhook event. Higher values are
+// more important and are kept longest when the buffer is full.
+type EventPriorityFn func(map[string]interface{}) int
+
+// DefaultEventPriority ranks SLA-critical failures above completions and
+// everything else
+func DefaultEventPriority(event map[string]interface{}) int {
+	eventType, _ := event["type"].(string)
+	switch eventType {
+	case "execution.failed":
+		return 3
+	case "node.failed":
+		return 2
+	case "execution.completed":
+		return 1
+	}
+	return 0
+}
+
+type prioritizedEvent struct {
+	event    map[string]interface{}
+	priority int
+	seq      uint64
+	minIndex int
+	maxIndex int
+}
+
+// eventHeap orders events by priority, oldest first among equal priorities.
+// A min-heap keeps the lowest priority event at the root for eviction; a
+// max-heap keeps the highest priority event at the root for delivery.
+type eventHeap struct {
+	items []*prioritizedEvent
+	max   bool
+}
+
+func (h *eventHeap) Len() int { return len(h.items) }
+
+func (h *eventHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if a.priority != b.priority {
+		if h.max {
+			return a.priority > b.priority
+		}
+		return a.priority < b.priority
+	}
+	return a.seq < b.seq
+}
+
+func (h *eventHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.setIndex(h.items[i], i)
+	h.setIndex(h.items[j], j)
+}
+
+func (h *eventHeap) Push(x interface{}) {
+	item := x.(*prioritizedEvent)
+	h.setIndex(item, len(h.items))
+	h.items = append(h.items, item)
+}
+
+func (h *eventHeap) Pop() interface{} {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	return item
+}
+
+func (h *eventHeap) setIndex(item *prioritizedEvent, i int) {
+	if h.max {
+		item.maxIndex = i
+	} else {
+		item.minIndex = i
+	}
+}
+
+// PriorityEventQueue is a bounded event buffer that evicts the lowest
+// priority event instead of dropping new high-priority events when full
+type PriorityEventQueue struct {
+	lowest     eventHeap
+	highest    eventHeap
+	capacity   int
+	priorityFn EventPriorityFn
+	seq        uint64
+	notify     chan struct{}
+	mu         sync.Mutex
+}
+
+// NewPriorityEventQueue creates a queue holding at most capacity events.
+// A nil priority function uses DefaultEventPriority.
+func NewPriorityEventQueue(capacity int, fn EventPriorityFn) *PriorityEventQueue {
+	if fn == nil {
+		fn = DefaultEventPriority
+	}
+	return &PriorityEventQueue{
+		lowest:     eventHeap{items: make([]*prioritizedEvent, 0, capacity)},
+		highest:    eventHeap{items: make([]*prioritizedEvent, 0, capacity), max: true},
+		capacity:   capacity,
+		priorityFn: fn,
+		notify:     make(chan struct{}, 1),
+	}
+}
+
+// Push adds an event to the queue. When the queue is full the lowest
+// priority event is evicted and returned, provided it ranks below the new
+// event; otherwise the new event is rejected and accepted is false.
+func (q *PriorityEventQueue) Push(event map[string]interface{}) (evicted map[string]interface{}, accepted bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	priority := q.priorityFn(event)
+	if q.capacity > 0 && q.lowest.Len() >= q.capacity {
+		if q.lowest.items[0].priority >= priority {
+			return nil, false
+		}
+		lowest := heap.Pop(&q.lowest).(*prioritizedEvent)
+		heap.Remove(&q.highest, lowest.maxIndex)
+		evicted = lowest.event
+	}
+
+	q.seq++
+	item := &prioritizedEvent{event: event, priority: priority, seq: q.seq}
+	heap.Push(&q.lowest, item)
+	heap.Push(&q.highest, item)
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return evicted, true
+}
+
+// Pop removes and returns the highest priority event, oldest first among
+// equal priorities
+func (q *PriorityEventQueue) Pop() (map[string]interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.highest.Len() == 0 {
+		return nil, false
+	}
+
+	item := heap.Pop(&q.highest).(*prioritizedEvent)
+	heap.Remove(&q.lowest, item.minIndex)
+	return item.event, true
+}
+
+// Len returns the number of queued events
+func (q *PriorityEventQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.lowest.Len()
+}
+
+// WithPriorityQueue buffers events for the observable in a PriorityEventQueue
+// so high-priority events are never dropped when the buffer is full. Calling
+// it again replaces the queue, carrying over any events still buffered. The
+// queue is drained into the observable until Stop is called.
+func (ws *WebhookSubscriptionManager) WithPriorityQueue(fn EventPriorityFn) *WebhookSubscriptionManager {
+	queue := NewPriorityEventQueue(ws.options.BufferSize, fn)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	ws.mu.Lock()
+	previous := ws.priorityQueue
+	previousDone := ws.stopPriorityQueueLocked()
+	ws.priorityQueue = queue
+	ws.priorityQueueStop = stop
+	ws.priorityQueueDone = done
+	ws.mu.Unlock()
+
+	if previous != nil {
+		<-previousDone
+		for {
+			event, ok := previous.Pop()
+			if !ok {
+				break
+			}
+			queue.Push(event)
+		}
+	}
+
+	go ws.pumpPriorityQueue(queue, stop, done)
+
+	return ws
+}
+
+// pumpPriorityQueue forwards queued events to the observable until stop is
+// closed or the observable completes, then closes done
+func (ws *WebhookSubscriptionManager) pumpPriorityQueue(queue *PriorityEventQueue, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	for {
+		select {
+		case <-queue.notify:
+		case <-stop:
+			return
+		case <-ws.observable.completeChan:
+			return
+		}
+		for {
+			event, ok := queue.Pop()
+			if !ok {
+				break
+			}
+			select {
+			case ws.observable.eventChan <- event:
+			case <-stop:
+				// Keep the event for a replacement queue to pick up
+				queue.Push(event)
+				return
+			case <-ws.observable.completeChan:
+				return
+			}
+		}
+	}
+}
+
+// stopPriorityQueue stops the goroutine started by WithPriorityQueue
+func (ws *WebhookSubscriptionManager) stopPriorityQueue() {
+	ws.mu.Lock()
+	done := ws.stopPriorityQueueLocked()
+	ws.mu.Unlock()
+	<-done
+}
+
+// stopPriorityQueueLocked signals the pump goroutine to exit and returns a
+// channel closed once it has. The caller must hold ws.mu.
+func (ws *WebhookSubscriptionManager) stopPriorityQueueLocked() <-chan struct{} {
+	done := ws.priorityQueueDone
+	if ws.priorityQueueStop != nil {
+		close(ws.priorityQueueStop)
+	}
+	ws.priorityQueueStop = nil
+	ws.priorityQueueDone = nil
+	if done == nil {
+		closed := make(chan struct{})
+		close(closed)
+		return closed
+	}
+	return done
+}
@@ -1,7 +1,22 @@
 package zeal
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestDefaultSubscriptionOptions(t *testing.T) {
@@ -51,7 +66,7 @@ func TestWebhookSubscriptionCallbacks(t *testing.T) {
 	subscription := NewWebhookSubscription(mockWebhooksAPI, nil)
 	
 	// Test event callback
-	unsubscribe := subscription.OnEvent(func(event map[string]interface{}) error {
+	unsubscribe := subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
 		return nil
 	})
 	
@@ -66,7 +81,7 @@ func TestWebhookSubscriptionCallbacks(t *testing.T) {
 	}
 	
 	// Test delivery callback
-	subscription.OnDelivery(func(delivery WebhookDelivery) error {
+	subscription.OnDelivery(func(_ context.Context, delivery WebhookDelivery) error {
 		return nil
 	})
 	
@@ -122,7 +137,7 @@ func TestEventTypeFiltering(t *testing.T) {
 	eventReceived := false
 	eventTypes := []string{"node.completed", "workflow.started"}
 	
-	unsubscribe := subscription.OnEventType(eventTypes, func(event map[string]interface{}) error {
+	unsubscribe := subscription.OnEventType(eventTypes, func(_ context.Context, event map[string]interface{}) error {
 		eventReceived = true
 		return nil
 	})
@@ -136,7 +151,7 @@ func TestEventTypeFiltering(t *testing.T) {
 	
 	// This would normally be called by the webhook handler
 	for _, callback := range subscription.eventCallbacks {
-		callback(matchingEvent)
+		callback(context.Background(), matchingEvent)
 	}
 	
 	if !eventReceived {
@@ -151,7 +166,7 @@ func TestEventTypeFiltering(t *testing.T) {
 	}
 	
 	for _, callback := range subscription.eventCallbacks {
-		callback(nonMatchingEvent)
+		callback(context.Background(), nonMatchingEvent)
 	}
 	
 	if eventReceived {
@@ -167,7 +182,7 @@ func TestEventSourceFiltering(t *testing.T) {
 	eventReceived := false
 	sources := []string{"workflow-123", "workflow-456"}
 	
-	unsubscribe := subscription.OnEventSource(sources, func(event map[string]interface{}) error {
+	unsubscribe := subscription.OnEventSource(sources, func(_ context.Context, event map[string]interface{}) error {
 		eventReceived = true
 		return nil
 	})
@@ -180,7 +195,7 @@ func TestEventSourceFiltering(t *testing.T) {
 	}
 	
 	for _, callback := range subscription.eventCallbacks {
-		callback(matchingEvent)
+		callback(context.Background(), matchingEvent)
 	}
 	
 	if !eventReceived {
@@ -195,7 +210,7 @@ func TestEventSourceFiltering(t *testing.T) {
 	}
 	
 	for _, callback := range subscription.eventCallbacks {
-		callback(nonMatchingEvent)
+		callback(context.Background(), nonMatchingEvent)
 	}
 	
 	if eventReceived {
@@ -260,4 +275,1114 @@ func TestCustomSubscriptionOptions(t *testing.T) {
 	if subscription.options.SecretKey != "my-secret" {
 		t.Errorf("Expected custom secret key, got %s", subscription.options.SecretKey)
 	}
-}
\ No newline at end of file
+}
+
+func TestWorkflowFilter(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+
+	all := NewWebhookSubscription(mockWebhooksAPI, nil)
+	if !all.matchesWorkflowFilter(map[string]interface{}{"workflowId": "any"}) {
+		t.Error("Expected empty WorkflowFilter to accept all workflows")
+	}
+
+	filtered := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{
+		WorkflowFilter: []string{"workflow-123"},
+	})
+	if !filtered.matchesWorkflowFilter(map[string]interface{}{"workflowId": "workflow-123"}) {
+		t.Error("Expected listed workflow to be accepted")
+	}
+	if filtered.matchesWorkflowFilter(map[string]interface{}{"workflowId": "workflow-999"}) {
+		t.Error("Expected unlisted workflow to be rejected")
+	}
+}
+
+func TestPriorityEventQueueEviction(t *testing.T) {
+	queue := NewPriorityEventQueue(2, nil)
+
+	queue.Push(map[string]interface{}{"type": "node.completed", "id": "1"})
+	queue.Push(map[string]interface{}{"type": "execution.completed", "id": "2"})
+
+	evicted, accepted := queue.Push(map[string]interface{}{"type": "execution.failed", "id": "3"})
+	if !accepted {
+		t.Fatal("Expected high-priority event to be accepted")
+	}
+	if evicted == nil || evicted["id"] != "1" {
+		t.Errorf("Expected lowest priority event to be evicted, got %v", evicted)
+	}
+
+	if _, accepted := queue.Push(map[string]interface{}{"type": "node.added", "id": "4"}); accepted {
+		t.Error("Expected low-priority event to be rejected when full")
+	}
+
+	first, _ := queue.Pop()
+	second, _ := queue.Pop()
+	if first["id"] != "3" || second["id"] != "2" {
+		t.Errorf("Expected events in priority order, got %v then %v", first["id"], second["id"])
+	}
+	if queue.Len() != 0 {
+		t.Errorf("Expected empty queue, got %d", queue.Len())
+	}
+}
+
+func TestPriorityEventQueueOrdersManyEvents(t *testing.T) {
+	priority := func(event map[string]interface{}) int { return event["priority"].(int) }
+	queue := NewPriorityEventQueue(4, priority)
+
+	for i, p := range []int{1, 5, 3, 5, 0, 4} {
+		queue.Push(map[string]interface{}{"priority": p, "id": i})
+	}
+
+	var got []int
+	for {
+		event, ok := queue.Pop()
+		if !ok {
+			break
+		}
+		got = append(got, event["id"].(int))
+	}
+	want := []int{1, 3, 5, 2}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+}
+
+func TestWithPriorityQueueReplacesPump(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	ws := NewWebhookSubscription(mockWebhooksAPI, nil)
+
+	ws.WithPriorityQueue(nil)
+	first := ws.priorityQueueDone
+	ws.WithPriorityQueue(nil)
+
+	select {
+	case <-first:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the first pump to exit when the queue was replaced")
+	}
+
+	second := ws.priorityQueueDone
+	if err := ws.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the pump to exit on Stop")
+	}
+}
+
+func TestForwardTo(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	src := NewWebhookSubscription(mockWebhooksAPI, nil)
+	dst := NewWebhookSubscription(mockWebhooksAPI, nil)
+
+	var received []string
+	dst.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		received = append(received, event["type"].(string))
+		return nil
+	})
+
+	stop := src.ForwardTo(dst, func(event map[string]interface{}) bool {
+		return event["type"] != "node.added"
+	})
+
+	src.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{
+		{"type": "node.completed"},
+		{"type": "node.added"},
+	}})
+
+	if len(received) != 1 || received[0] != "node.completed" {
+		t.Errorf("Expected only node.completed to be forwarded, got %v", received)
+	}
+
+	stop()
+	src.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{{"type": "node.failed"}}})
+	if len(received) != 1 {
+		t.Errorf("Expected forwarding to stop, got %v", received)
+	}
+}
+
+func TestStopGracefullyDrainsDeliveries(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	var processed int32
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+
+	subscription.isRunning = true
+	body := `{"webhook_id":"wh_1","events":[{"type":"node.completed"}],"metadata":{}}`
+	recorder := httptest.NewRecorder()
+	subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := subscription.StopGracefully(ctx); err != nil {
+		t.Fatalf("StopGracefully failed: %v", err)
+	}
+
+	if atomic.LoadInt32(&processed) != 1 {
+		t.Error("Expected in-flight delivery to finish before stop returned")
+	}
+	if subscription.IsRunning() {
+		t.Error("Expected subscription to be stopped")
+	}
+}
+
+func TestSubscriptionMetrics(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{BufferSize: 1})
+
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		if event["type"] == "node.failed" {
+			return errors.New("callback failed")
+		}
+		return nil
+	})
+
+	subscription.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{
+		{"type": "node.completed"},
+		{"type": "node.failed"},
+	}})
+
+	metrics := subscription.Metrics()
+	if metrics.EventsReceived != 2 {
+		t.Errorf("Expected 2 events received, got %d", metrics.EventsReceived)
+	}
+	if metrics.EventsDropped != 1 {
+		t.Errorf("Expected 1 event dropped by full buffer, got %d", metrics.EventsDropped)
+	}
+	if metrics.DeliveryErrors != 1 {
+		t.Errorf("Expected 1 delivery error, got %d", metrics.DeliveryErrors)
+	}
+	if metrics.LastEventAt == nil {
+		t.Error("Expected LastEventAt to be set")
+	}
+}
+
+func TestWebhookRequestIDPropagation(t *testing.T) {
+	var requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get("X-Request-ID"))
+		w.Write([]byte(`{"success":true,"subscription":{"id":"wh_1"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	subscription := NewWebhookSubscription(client.Webhooks(), nil)
+
+	deliveries := make(chan WebhookDelivery, 2)
+	subscription.OnDelivery(func(_ context.Context, delivery WebhookDelivery) error {
+		deliveries <- delivery
+		return nil
+	})
+
+	subscription.isRunning = true
+	if _, err := subscription.Register(nil); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	body := `{"webhook_id":"wh_1","events":[],"metadata":{}}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set("X-Correlation-ID", "corr-1")
+	req.Header.Set("X-Trace-ID", "trace-1")
+	subscription.webhookHandler(httptest.NewRecorder(), req)
+	subscription.webhookHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)))
+
+	if err := subscription.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	ids := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		ids[(<-deliveries).Metadata.RequestID] = true
+	}
+	if !ids["corr-1"] || len(ids) != 2 || ids[""] {
+		t.Errorf("Expected correlation ID and a generated ID, got %v", ids)
+	}
+
+	if len(requestIDs) != 2 || requestIDs[0] != subscription.RequestID() || requestIDs[1] != subscription.RequestID() {
+		t.Errorf("Expected register and delete to carry request ID %s, got %v", subscription.RequestID(), requestIDs)
+	}
+}
+
+func TestConcurrentDispatch(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{
+		DispatchMode:              DispatchConcurrent,
+		ConcurrentDispatchTimeout: 50 * time.Millisecond,
+	})
+
+	release := make(chan struct{})
+	defer close(release)
+	var fastCalls int32
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		<-release
+		return nil
+	})
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		atomic.AddInt32(&fastCalls, 1)
+		return errors.New("fast failure")
+	})
+
+	var errs []error
+	subscription.OnError(func(err error) error {
+		errs = append(errs, err)
+		return nil
+	})
+
+	start := time.Now()
+	subscription.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{{"type": "node.completed"}}})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the slow callback to be abandoned after the timeout, took %v", elapsed)
+	}
+
+	if atomic.LoadInt32(&fastCalls) != 1 {
+		t.Error("Expected the fast callback to run despite the slow one")
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", errs)
+	}
+	timedOut := false
+	for _, err := range errs {
+		if strings.Contains(err.Error(), "event callback 0 timed out") {
+			timedOut = true
+		}
+	}
+	if !timedOut {
+		t.Errorf("Expected a timeout error naming callback 0, got %v", errs)
+	}
+	if subscription.Metrics().DeliveryErrors != 2 {
+		t.Errorf("Expected 2 delivery errors, got %d", subscription.Metrics().DeliveryErrors)
+	}
+}
+
+func TestWebhookDeliveryParsedEvents(t *testing.T) {
+	delivery := WebhookDelivery{Events: []map[string]interface{}{
+		{"type": "node.completed", "workflowId": "wf-1", "nodeId": "n1"},
+		{"type": "unknown.event"},
+		{"type": "node.failed", "workflowId": "wf-1", "nodeId": "n2"},
+	}}
+
+	events, errs := delivery.ParsedEvents()
+	if len(events) != 2 || len(errs) != 1 {
+		t.Fatalf("Expected 2 events and 1 error, got %d and %d", len(events), len(errs))
+	}
+	if _, ok := events[1].(*NodeFailedEvent); !ok {
+		t.Errorf("Expected *NodeFailedEvent, got %T", events[1])
+	}
+
+	if failed := delivery.FilterEventsByType("node.failed"); len(failed) != 1 || failed[0]["nodeId"] != "n2" {
+		t.Errorf("Unexpected filtered events %v", failed)
+	}
+}
+
+func TestWebhookSubscriptionEventBus(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{
+		AutoRegister:   false,
+		WorkflowFilter: []string{"wf-1"},
+	})
+
+	var published []string
+	subscription.WithEventBus(EventBusFunc(func(event ZipWebhookEvent) error {
+		published = append(published, event.GetEventType())
+		return nil
+	}))
+	var errs []error
+	subscription.OnError(func(err error) error {
+		errs = append(errs, err)
+		return nil
+	})
+
+	subscription.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{
+		{"type": "node.completed", "workflowId": "wf-1"},
+		{"type": "node.completed", "workflowId": "wf-2"},
+		{"type": "bogus", "workflowId": "wf-1"},
+	}})
+
+	if len(published) != 1 || published[0] != "node.completed" {
+		t.Errorf("Expected one node.completed event on the bus, got %v", published)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected one parse error, got %v", errs)
+	}
+}
+
+func TestRegisterWithWebhookConfig(t *testing.T) {
+	var received CreateWebhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"success":true,"subscription":{"id":"wh_2"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	subscription := NewWebhookSubscription(client.Webhooks(), &SubscriptionOptions{Namespace: "default-ns", Port: 4000})
+	subscription.isRunning = true
+
+	result, err := subscription.Register(nil)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if received.Namespace != "default-ns" || received.URL != "http://localhost:4000/webhooks" {
+		t.Errorf("Unexpected default registration %+v", received)
+	}
+	if result.WebhookID != "wh_2" || result.URL != received.URL {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	result, err = subscription.Register(&WebhookConfig{
+		Namespace: "custom-ns",
+		URL:       "https://hooks.example.com/zeal",
+		Headers:   map[string]string{"X-Env": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if received.Namespace != "custom-ns" || received.URL != "https://hooks.example.com/zeal" || received.Headers["X-Env"] != "prod" {
+		t.Errorf("Expected config overrides, got %+v", received)
+	}
+	if result.URL != "https://hooks.example.com/zeal" {
+		t.Errorf("Unexpected result URL %s", result.URL)
+	}
+}
+
+func TestRegisterWithExternalURL(t *testing.T) {
+	var received CreateWebhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"success":true,"subscription":{"id":"wh_3"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	subscription := NewWebhookSubscription(client.Webhooks(), &SubscriptionOptions{
+		Port:        4000,
+		ExternalURL: "https://public.example.com/hooks/zeal",
+	})
+	subscription.isRunning = true
+
+	if _, err := subscription.Register(nil); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if received.URL != "https://public.example.com/hooks/zeal" {
+		t.Errorf("Expected ExternalURL to be registered, got %s", received.URL)
+	}
+
+	result, err := subscription.RegisterWithURL("http://10.0.0.5:8080/in")
+	if err != nil {
+		t.Fatalf("RegisterWithURL failed: %v", err)
+	}
+	if received.URL != "http://10.0.0.5:8080/in" || result.URL != received.URL {
+		t.Errorf("Expected URL to be used verbatim, got %s", received.URL)
+	}
+
+	for _, invalid := range []string{"", "ftp://example.com/hooks", "/webhooks", "https://", "://bad"} {
+		if _, err := subscription.RegisterWithURL(invalid); !errors.Is(err, ErrInvalidWebhookURL) {
+			t.Errorf("Expected ErrInvalidWebhookURL for %q, got %v", invalid, err)
+		}
+	}
+
+	subscription.options.ExternalURL = "example.com/hooks"
+	if _, err := subscription.Register(nil); !errors.Is(err, ErrInvalidWebhookURL) {
+		t.Errorf("Expected ErrInvalidWebhookURL for invalid ExternalURL, got %v", err)
+	}
+}
+
+func TestWebhookSubscriptionPauseResume(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	deliveries := make(chan WebhookDelivery, 1)
+	subscription.OnDelivery(func(_ context.Context, delivery WebhookDelivery) error {
+		deliveries <- delivery
+		return nil
+	})
+
+	if err := subscription.Pause(); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if err := subscription.Pause(); err == nil {
+		t.Error("Expected error pausing twice")
+	}
+	if !subscription.IsPaused() || !subscription.Metrics().IsPaused {
+		t.Error("Expected subscription to report paused")
+	}
+
+	body := `{"webhook_id":"wh_1","events":[],"metadata":{}}`
+	recorder := httptest.NewRecorder()
+	subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while paused, got %d", recorder.Code)
+	}
+
+	if err := subscription.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if err := subscription.Resume(); err == nil {
+		t.Error("Expected error resuming when not paused")
+	}
+	recorder = httptest.NewRecorder()
+	subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected 200 after resume, got %d", recorder.Code)
+	}
+	select {
+	case <-deliveries:
+	case <-time.After(time.Second):
+		t.Error("Expected delivery to be processed after resume")
+	}
+	if subscription.Metrics().IsPaused {
+		t.Error("Expected metrics to report resumed")
+	}
+}
+
+func TestUpdateSecretKeyGracePeriod(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{
+		AutoRegister:      false,
+		VerifySignature:   true,
+		SecretKey:         "old-secret",
+		SecretGracePeriod: 50 * time.Millisecond,
+	})
+
+	body := `{"webhook_id":"wh_1","events":[],"metadata":{}}`
+	deliver := func(secret string) int {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+		req.Header.Set("X-Zeal-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		recorder := httptest.NewRecorder()
+		subscription.webhookHandler(recorder, req)
+		return recorder.Code
+	}
+
+	subscription.UpdateSecretKey("new-secret")
+	if code := deliver("new-secret"); code != http.StatusOK {
+		t.Errorf("Expected new secret to be accepted, got %d", code)
+	}
+	if code := deliver("old-secret"); code != http.StatusOK {
+		t.Errorf("Expected old secret to be accepted during grace period, got %d", code)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if code := deliver("old-secret"); code != http.StatusUnauthorized {
+		t.Errorf("Expected old secret to be rejected after grace period, got %d", code)
+	}
+	if code := deliver("new-secret"); code != http.StatusOK {
+		t.Errorf("Expected new secret to be accepted, got %d", code)
+	}
+	subscription.inflight.Wait()
+}
+
+func TestCallbackUnsubscribeRemovesOwnCallback(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	var calls []string
+	record := func(name string) WebhookEventCallback {
+		return func(_ context.Context, event map[string]interface{}) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	unsubscribeFirst := subscription.OnEvent(record("first"))
+	unsubscribeSecond := subscription.OnEvent(record("second"))
+	subscription.OnEvent(record("third"))
+
+	unsubscribeFirst()
+	unsubscribeSecond()
+	unsubscribeSecond()
+	subscription.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{{"type": "node.completed"}}})
+
+	if len(calls) != 1 || calls[0] != "third" {
+		t.Errorf("Expected only the third callback to remain, got %v", calls)
+	}
+}
+
+func TestCallbackSubscribeConcurrently(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unsubscribeEvent := subscription.OnEvent(func(context.Context, map[string]interface{}) error { return nil })
+			unsubscribeDelivery := subscription.OnDelivery(func(context.Context, WebhookDelivery) error { return nil })
+			unsubscribeError := subscription.OnError(func(error) error { return nil })
+			subscription.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{{"type": "node.completed"}}})
+			unsubscribeEvent()
+			unsubscribeDelivery()
+			unsubscribeError()
+		}()
+	}
+	wg.Wait()
+
+	if len(subscription.eventCallbacks) != 0 || len(subscription.deliveryCallbacks) != 0 || len(subscription.errorCallbacks) != 0 {
+		t.Errorf("Expected all callbacks to be removed, got %d/%d/%d",
+			len(subscription.eventCallbacks), len(subscription.deliveryCallbacks), len(subscription.errorCallbacks))
+	}
+}
+
+func TestStartWaitsForListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	mockWebhooksAPI := &WebhooksAPI{client: &Client{}}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{Host: "127.0.0.1", Port: port, AutoRegister: false})
+	if err := subscription.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer subscription.Stop()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Expected server to be listening after Start: %v", err)
+	}
+	conn.Close()
+
+	conflicting := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{Host: "127.0.0.1", Port: port, AutoRegister: false})
+	if err := conflicting.Start(); err == nil {
+		conflicting.Stop()
+		t.Fatal("Expected Start to fail when the port is in use")
+	}
+	if conflicting.IsRunning() {
+		t.Error("Expected failed subscription not to be running")
+	}
+}
+
+func TestPanickingCallbackDoesNotStopDeliveries(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	var delivered int32
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		if event["type"] == "node.failed" {
+			panic("bad callback")
+		}
+		return nil
+	})
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		atomic.AddInt32(&delivered, 1)
+		return nil
+	})
+	var panics int32
+	subscription.OnError(func(err error) error {
+		if strings.Contains(err.Error(), "panicked") {
+			atomic.AddInt32(&panics, 1)
+		}
+		return nil
+	})
+
+	for _, eventType := range []string{"node.failed", "node.completed"} {
+		body := `{"webhook_id":"wh_1","events":[{"type":"` + eventType + `"}],"metadata":{}}`
+		recorder := httptest.NewRecorder()
+		subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Expected 200 for %s, got %d", eventType, recorder.Code)
+		}
+	}
+	subscription.inflight.Wait()
+
+	if got := atomic.LoadInt32(&delivered); got != 2 {
+		t.Errorf("Expected other callback to see both events, got %d", got)
+	}
+	if got := atomic.LoadInt32(&panics); got != 1 {
+		t.Errorf("Expected 1 panic to be reported, got %d", got)
+	}
+
+	filtered := subscription.FilterEvents(func(event map[string]interface{}) bool {
+		if event["type"] == "node.failed" {
+			panic("bad predicate")
+		}
+		return true
+	})
+	subscription.observable.eventChan <- map[string]interface{}{"type": "node.failed"}
+	subscription.observable.eventChan <- map[string]interface{}{"type": "node.completed"}
+	select {
+	case event := <-filtered.eventChan:
+		if event["type"] != "node.completed" {
+			t.Errorf("Unexpected filtered event %v", event)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected filter to keep running after a panicking predicate")
+	}
+}
+
+func TestStopTwice(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	subscription.isRunning = true
+	if err := subscription.Stop(); err != nil {
+		t.Fatalf("First Stop failed: %v", err)
+	}
+	if err := subscription.Stop(); err != nil {
+		t.Errorf("Expected second Stop to return nil, got %v", err)
+	}
+
+	// A restarted subscription must not close the observable again
+	subscription.isRunning = true
+	if err := subscription.Stop(); err != nil {
+		t.Errorf("Expected Stop after restart to return nil, got %v", err)
+	}
+}
+
+func TestWebhookCallbackContext(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	type seen struct {
+		requestID, deliveryID string
+		err                   error
+	}
+	results := make(chan seen, 2)
+	record := func(ctx context.Context) {
+		results <- seen{RequestIDFromContext(ctx), DeliveryIDFromContext(ctx), ctx.Err()}
+	}
+	subscription.OnDelivery(func(ctx context.Context, delivery WebhookDelivery) error {
+		record(ctx)
+		return nil
+	})
+	subscription.OnEvent(func(ctx context.Context, event map[string]interface{}) error {
+		record(ctx)
+		return nil
+	})
+
+	body := `{"webhook_id":"wh_1","events":[{"type":"node.completed"}],"metadata":{"delivery_id":"del_1"}}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	req.Header.Set("X-Request-ID", "req-1")
+	subscription.webhookHandler(httptest.NewRecorder(), req)
+	subscription.inflight.Wait()
+
+	for i := 0; i < 2; i++ {
+		got := <-results
+		if got.requestID != "req-1" || got.deliveryID != "del_1" {
+			t.Errorf("Unexpected context values %+v", got)
+		}
+		if got.err != nil {
+			t.Errorf("Expected callback context to outlive the request, got %v", got.err)
+		}
+	}
+}
+
+func TestAddPath(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	if err := subscription.AddPath("/staging", &PathOptions{VerifySignature: true, SecretKey: "staging-secret", Tag: "staging"}); err != nil {
+		t.Fatalf("AddPath failed: %v", err)
+	}
+	if err := subscription.AddPath("/staging", nil); err == nil {
+		t.Error("Expected duplicate path to be rejected")
+	}
+	if err := subscription.AddPath("/webhooks", nil); err == nil {
+		t.Error("Expected main path to be rejected")
+	}
+	if err := subscription.AddPath("staging", nil); err == nil {
+		t.Error("Expected relative path to be rejected")
+	}
+
+	deliveries := make(chan WebhookDelivery, 1)
+	events := make(chan map[string]interface{}, 1)
+	subscription.OnDelivery(func(_ context.Context, delivery WebhookDelivery) error {
+		deliveries <- delivery
+		return nil
+	})
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		events <- event
+		return nil
+	})
+
+	handler := subscription.pathHandler(subscription.paths["/staging"])
+	body := `{"webhook_id":"wh_1","events":[{"type":"node.completed"}],"metadata":{}}`
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/staging", strings.NewReader(body))
+	req.Header.Set("X-Zeal-Signature", "sha256=bad")
+	handler(recorder, req)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad signature, got %d", recorder.Code)
+	}
+
+	mac := hmac.New(sha256.New, []byte("staging-secret"))
+	mac.Write([]byte(body))
+	recorder = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/staging", strings.NewReader(body))
+	req.Header.Set("X-Zeal-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	handler(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+	subscription.inflight.Wait()
+
+	if delivery := <-deliveries; delivery.Metadata.Tag != "staging" {
+		t.Errorf("Expected delivery tag staging, got %q", delivery.Metadata.Tag)
+	}
+	event := <-events
+	if metadata, _ := event["metadata"].(map[string]interface{}); metadata["tag"] != "staging" {
+		t.Errorf("Expected event tag staging, got %v", event["metadata"])
+	}
+}
+
+func TestOnEventGraph(t *testing.T) {
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false})
+
+	var mainIDs, subIDs []string
+	subscription.OnEventGraph("wf-1", "main", func(_ context.Context, event map[string]interface{}) error {
+		mainIDs = append(mainIDs, event["id"].(string))
+		return nil
+	})
+	subscription.OnEventGraph("wf-1", "sub-1", func(_ context.Context, event map[string]interface{}) error {
+		subIDs = append(subIDs, event["id"].(string))
+		return nil
+	})
+
+	subscription.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{
+		{"id": "e1", "type": "node.added", "workflowId": "wf-1"},
+		{"id": "e2", "type": "node.added", "workflowId": "wf-1", "graphId": "main"},
+		{"id": "e3", "type": "node.added", "workflowId": "wf-1", "graphId": "sub-1"},
+		{"id": "e4", "type": "node.added", "workflowId": "wf-2", "graphId": "sub-1"},
+	}})
+
+	if strings.Join(mainIDs, ",") != "e1,e2" {
+		t.Errorf("Expected main graph events e1,e2, got %v", mainIDs)
+	}
+	if strings.Join(subIDs, ",") != "e3" {
+		t.Errorf("Expected sub graph event e3, got %v", subIDs)
+	}
+}
+
+func TestProcessDeliveryWarnsOnLateDelivery(t *testing.T) {
+	log := &recordingLogger{}
+	useLogger(t, log)
+
+	late := WebhookDelivery{Metadata: WebhookMetadata{
+		DeliveryID: "late",
+		Timestamp:  time.Now().Add(-10 * time.Minute).Format(time.RFC3339),
+	}}
+	if age, err := late.Age(); err != nil || age < 10*time.Minute {
+		t.Fatalf("Expected age of at least 10 minutes, got %v (%v)", age, err)
+	}
+	if _, err := (WebhookMetadata{Timestamp: "yesterday"}).ParsedTimestamp(); err == nil {
+		t.Error("Expected error for invalid timestamp")
+	}
+
+	subscription := NewWebhookSubscription(&WebhooksAPI{client: &Client{}}, &SubscriptionOptions{AutoRegister: false})
+	subscription.DispatchDelivery(context.Background(), WebhookDelivery{Metadata: WebhookMetadata{
+		DeliveryID: "fresh",
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}})
+	if len(log.messages) != 0 {
+		t.Fatalf("Expected no warning for a fresh delivery, got %v", log.messages)
+	}
+
+	subscription.DispatchDelivery(context.Background(), late)
+	if len(log.messages) != 1 || !strings.Contains(log.messages[0], "delivery late processed") {
+		t.Errorf("Expected a late delivery warning, got %v", log.messages)
+	}
+}
+
+func TestListenAndServeWebhooks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: "http://127.0.0.1"})
+	published := make(chan ZipWebhookEvent, 1)
+	bus := EventBusFunc(func(event ZipWebhookEvent) error {
+		published <- event
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- client.ListenAndServeWebhooks(ctx, bus, &SubscriptionOptions{Host: "127.0.0.1", Port: port, AutoRegister: false})
+	}()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/webhooks", port)
+	body := `{"webhook_id":"wh","events":[{"id":"e1","type":"node.added","workflowId":"wf-1","nodeId":"n1","timestamp":"2024-01-01T00:00:00Z"}],"metadata":{"delivery_id":"d1"}}`
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Post(url, "application/json", strings.NewReader(body)); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Failed to post delivery: %v", err)
+	}
+	resp.Body.Close()
+
+	select {
+	case event := <-published:
+		if event.GetEventType() != "node.added" {
+			t.Errorf("Expected node.added, got %s", event.GetEventType())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected event to be published to the bus")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(6 * time.Second):
+		t.Fatal("ListenAndServeWebhooks did not return after cancel")
+	}
+
+	if err := client.ListenAndServeWebhooks(context.Background(), bus, &SubscriptionOptions{Host: "256.0.0.1", Port: port}); err == nil {
+		t.Error("Expected Start error to be returned")
+	}
+}
+
+func TestObservableChannelDepthWarning(t *testing.T) {
+	log := &recordingLogger{}
+	useLogger(t, log)
+
+	interval := channelDepthCheckInterval
+	channelDepthCheckInterval = 10 * time.Millisecond
+	defer func() { channelDepthCheckInterval = interval }()
+
+	subscription := NewWebhookSubscription(&WebhooksAPI{client: &Client{}}, &SubscriptionOptions{AutoRegister: false, BufferSize: 10})
+	observable := subscription.AsObservable()
+	if observable.Capacity() != 10 {
+		t.Fatalf("Expected capacity 10, got %d", observable.Capacity())
+	}
+	for i := 0; i < 10; i++ {
+		observable.eventChan <- map[string]interface{}{"type": "node.added"}
+	}
+
+	release := make(chan struct{})
+	unsubscribe := observable.Subscribe(context.Background(), func(context.Context, map[string]interface{}) error {
+		<-release
+		return nil
+	}, nil, nil)
+	defer unsubscribe()
+	defer close(release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		log.mu.Lock()
+		warned := len(log.messages) > 0
+		log.mu.Unlock()
+		if warned {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a back-pressure warning")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if events, errs := observable.ChannelDepth(); events != 9 || errs != 0 {
+		t.Errorf("Expected depth 9/0, got %d/%d", events, errs)
+	}
+	if depth := subscription.Metrics().EventChannelDepth; depth != 9 {
+		t.Errorf("Expected sampled depth 9, got %d", depth)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if len(log.messages) != 1 || !strings.Contains(log.messages[0], "9/10") {
+		t.Errorf("Expected a single warning while the buffer stays full, got %v", log.messages)
+	}
+}
+
+func TestWebhookHandlerRejectsOversizedBody(t *testing.T) {
+	if DefaultSubscriptionOptions().MaxBodyBytes != 10<<20 {
+		t.Errorf("Expected a 10 MB default limit, got %d", DefaultSubscriptionOptions().MaxBodyBytes)
+	}
+
+	subscription := NewWebhookSubscription(&WebhooksAPI{client: &Client{}}, &SubscriptionOptions{AutoRegister: false, MaxBodyBytes: 1024})
+	var errs []error
+	subscription.OnError(func(err error) error {
+		errs = append(errs, err)
+		return nil
+	})
+
+	oversized := `{"webhook_id":"wh","events":[],"padding":"` + strings.Repeat("x", 2048) + `"}`
+	recorder := httptest.NewRecorder()
+	subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(oversized)))
+	subscription.inflight.Wait()
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413, got %d", recorder.Code)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "exceeds 1024 bytes") {
+		t.Errorf("Expected an oversized body error, got %v", errs)
+	}
+
+	exact := `{"webhook_id":"wh","events":[],"metadata":{"delivery_id":"d1"}}`
+	exact += strings.Repeat(" ", 1024-len(exact))
+	recorder = httptest.NewRecorder()
+	subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(exact)))
+	subscription.inflight.Wait()
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected a body at the limit to be accepted, got %d", recorder.Code)
+	}
+}
+
+func TestWebhookObservableOnCompleteAndOnError(t *testing.T) {
+	subscription := NewWebhookSubscription(&WebhooksAPI{client: &Client{}}, &SubscriptionOptions{AutoRegister: false})
+	observable := subscription.AsObservable()
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(call string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, call)
+	}
+
+	observable.OnError(func(err error) { record("onError:" + err.Error()) })
+	observable.OnComplete(func() { record("onComplete") })
+
+	done := make(chan struct{})
+	observable.Subscribe(context.Background(), func(context.Context, map[string]interface{}) error {
+		return errors.New("callback")
+	}, func(err error) error {
+		record("errorHandler:" + err.Error())
+		return nil
+	}, func() {
+		record("complete")
+	})
+	observable.OnComplete(func() {
+		record("lateComplete")
+		close(done)
+	})
+
+	observable.eventChan <- map[string]interface{}{"type": "node.added"}
+	time.Sleep(20 * time.Millisecond)
+	observable.errorChan <- errors.New("stream")
+	time.Sleep(20 * time.Millisecond)
+	observable.complete()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected completion handlers to run")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{
+		"errorHandler:callback", "onError:callback",
+		"errorHandler:stream", "onError:stream",
+		"complete", "onComplete", "lateComplete",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+}
+
+func TestWebhookObservableOnCompleteSkippedOnUnsubscribe(t *testing.T) {
+	subscription := NewWebhookSubscription(&WebhooksAPI{client: &Client{}}, &SubscriptionOptions{AutoRegister: false})
+	observable := subscription.AsObservable()
+
+	var completed int32
+	observable.OnComplete(func() { atomic.AddInt32(&completed, 1) })
+	unsubscribe := observable.Subscribe(context.Background(), func(context.Context, map[string]interface{}) error { return nil }, nil, nil)
+	unsubscribe()
+	time.Sleep(20 * time.Millisecond)
+	observable.complete()
+	time.Sleep(20 * time.Millisecond)
+
+	if atomic.LoadInt32(&completed) != 0 {
+		t.Error("Expected OnComplete handlers not to run after unsubscribing")
+	}
+}
+
+func TestWebhookSubscriptionDumpState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"subscription":{"id":"wh_9"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	subscription := NewWebhookSubscription(client.Webhooks(), &SubscriptionOptions{AutoRegister: false, BufferSize: 20})
+
+	unsubscribe := subscription.OnEvent(func(context.Context, map[string]interface{}) error { return nil })
+	var processedDuringDispatch uint64
+	subscription.OnEvent(func(context.Context, map[string]interface{}) error {
+		processedDuringDispatch = subscription.DumpState().TotalEventsProcessed
+		return nil
+	})
+	subscription.OnError(func(error) error { return nil })
+	unsubscribe()
+	unsubscribe()
+
+	state := subscription.DumpState()
+	if state.IsRunning || state.WebhookID != "" || state.RegisteredAt != nil || state.LastEventAt != nil {
+		t.Errorf("Unexpected initial state %+v", state)
+	}
+	if state.EventCallbackCount != 1 || state.DeliveryCallbackCount != 0 || state.ErrorCallbackCount != 1 {
+		t.Errorf("Unexpected callback counts %+v", state)
+	}
+	if state.EventChannelCap != 20 {
+		t.Errorf("Expected channel capacity 20, got %d", state.EventChannelCap)
+	}
+
+	subscription.isRunning = true
+	subscription.state.setRunning(true)
+	if _, err := subscription.Register(nil); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	subscription.Pause()
+	subscription.Resume()
+	subscription.DispatchDelivery(context.Background(), WebhookDelivery{Events: []map[string]interface{}{{"type": "node.added"}}})
+
+	// DumpState must not wait for the manager lock
+	subscription.mu.Lock()
+	done := make(chan *SubscriptionDiagnostics)
+	go func() { done <- subscription.DumpState() }()
+	select {
+	case state = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("DumpState blocked on the manager lock")
+	}
+	subscription.mu.Unlock()
+
+	if !state.IsRunning || state.IsPaused || state.WebhookID != "wh_9" || state.RegisteredAt == nil {
+		t.Errorf("Unexpected registered state %+v", state)
+	}
+	if state.TotalEventsProcessed != 1 || state.LastEventAt == nil {
+		t.Errorf("Expected one processed event, got %+v", state)
+	}
+	if processedDuringDispatch != 0 {
+		t.Errorf("Expected the event to count as processed only after its callbacks, got %d", processedDuringDispatch)
+	}
+}
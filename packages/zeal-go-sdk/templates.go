@@ -0,0 +1,497 @@
+package zeal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FilterTemplates applies a search query to a list of templates on the client side.
+// It mirrors the server-side search so results are consistent when the
+// search endpoint is unavailable.
+func FilterTemplates(templates []NodeTemplate, query *TemplateSearchQuery) []NodeTemplate {
+	result := make([]NodeTemplate, 0, len(templates))
+	for _, template := range templates {
+		if query != nil && !query.Matches(template) {
+			continue
+		}
+		result = append(result, template)
+		if query != nil && query.MaxResults > 0 && len(result) >= query.MaxResults {
+			break
+		}
+	}
+	return result
+}
+
+// HasTag reports whether the template carries the tag, ignoring case
+func HasTag(template NodeTemplate, tag string) bool {
+	for _, templateTag := range template.Tags {
+		if strings.EqualFold(templateTag, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTemplatesByTag returns the templates carrying every one of tags
+func FilterTemplatesByTag(templates []NodeTemplate, tags []string) []NodeTemplate {
+	result := make([]NodeTemplate, 0, len(templates))
+	for _, template := range templates {
+		if hasAllTags(template, tags) {
+			result = append(result, template)
+		}
+	}
+	return result
+}
+
+func hasAllTags(template NodeTemplate, tags []string) bool {
+	for _, tag := range tags {
+		if !HasTag(template, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether a template satisfies every filter set on the query
+func (q *TemplateSearchQuery) Matches(template NodeTemplate) bool {
+	if q.CategoryFilter != "" && !strings.EqualFold(template.Category, q.CategoryFilter) {
+		return false
+	}
+
+	if q.SubcategoryFilter != nil {
+		if template.Subcategory == nil || !strings.EqualFold(*template.Subcategory, *q.SubcategoryFilter) {
+			return false
+		}
+	}
+
+	if q.HasGPU != nil {
+		hasGPU := template.Runtime != nil && template.Runtime.GPU != nil && *template.Runtime.GPU
+		if hasGPU != *q.HasGPU {
+			return false
+		}
+	}
+
+	if !hasAllTags(template, q.Tags) {
+		return false
+	}
+
+	if q.TextSearch != "" {
+		needle := strings.ToLower(q.TextSearch)
+		haystack := []string{template.ID, template.Type, template.Title, template.Description}
+		if template.Subtitle != nil {
+			haystack = append(haystack, *template.Subtitle)
+		}
+		matched := false
+		for _, field := range haystack {
+			if strings.Contains(strings.ToLower(field), needle) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TemplateDependencyGraph tracks template-to-template references declared
+// through NodeTemplate.DependsOn. An edge points from a template to each
+// template it embeds.
+type TemplateDependencyGraph struct {
+	ids        []string
+	dependsOn  map[string][]string
+	dependents map[string][]string
+}
+
+// BuildTemplateDependencyGraph builds a dependency graph from a set of templates.
+// Returns an error for duplicate template IDs or references to unknown templates.
+func BuildTemplateDependencyGraph(templates []NodeTemplate) (*TemplateDependencyGraph, error) {
+	graph := &TemplateDependencyGraph{
+		ids:        make([]string, 0, len(templates)),
+		dependsOn:  make(map[string][]string, len(templates)),
+		dependents: make(map[string][]string, len(templates)),
+	}
+
+	for _, template := range templates {
+		if _, exists := graph.dependsOn[template.ID]; exists {
+			return nil, fmt.Errorf("duplicate template ID: %s", template.ID)
+		}
+		graph.ids = append(graph.ids, template.ID)
+		graph.dependsOn[template.ID] = nil
+	}
+	sort.Strings(graph.ids)
+
+	for _, template := range templates {
+		for _, dep := range template.DependsOn {
+			if _, exists := graph.dependsOn[dep]; !exists {
+				return nil, fmt.Errorf("template %s depends on unknown template %s", template.ID, dep)
+			}
+			graph.dependsOn[template.ID] = append(graph.dependsOn[template.ID], dep)
+			graph.dependents[dep] = append(graph.dependents[dep], template.ID)
+		}
+	}
+
+	return graph, nil
+}
+
+// Ancestors returns all templates that directly or transitively embed the given template
+func (g *TemplateDependencyGraph) Ancestors(templateID string) []string {
+	return g.walk(templateID, g.dependents)
+}
+
+// Descendants returns all templates the given template directly or transitively embeds
+func (g *TemplateDependencyGraph) Descendants(templateID string) []string {
+	return g.walk(templateID, g.dependsOn)
+}
+
+func (g *TemplateDependencyGraph) walk(start string, edges map[string][]string) []string {
+	visited := map[string]bool{start: true}
+	stack := append([]string(nil), edges[start]...)
+	result := make([]string, 0)
+
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		result = append(result, id)
+		stack = append(stack, edges[id]...)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// HasCycle reports whether any template transitively depends on itself,
+// which would cause infinite expansion
+func (g *TemplateDependencyGraph) HasCycle() bool {
+	return g.TopoSort() == nil && len(g.ids) > 0
+}
+
+// TopoSort returns template IDs ordered so every template appears after the
+// templates it depends on. Returns nil when the graph contains a cycle.
+func (g *TemplateDependencyGraph) TopoSort() []string {
+	remaining := make(map[string]int, len(g.ids))
+	ready := make([]string, 0)
+	for _, id := range g.ids {
+		remaining[id] = len(g.dependsOn[id])
+		if remaining[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	order := make([]string, 0, len(g.ids))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+
+		for _, dependent := range g.dependents[id] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) != len(g.ids) {
+		return nil
+	}
+	return order
+}
+
+// StringPropertyDef creates a string property definition
+func StringPropertyDef(label string, defaultValue string) PropertyDefinition {
+	return PropertyDefinition{Type: "string", Label: &label, DefaultValue: defaultValue}
+}
+
+// NumberPropertyDef creates a number property definition
+func NumberPropertyDef(label string, defaultValue float64) PropertyDefinition {
+	return PropertyDefinition{Type: "number", Label: &label, DefaultValue: defaultValue}
+}
+
+// BoolPropertyDef creates a boolean property definition
+func BoolPropertyDef(label string, defaultValue bool) PropertyDefinition {
+	return PropertyDefinition{Type: "boolean", Label: &label, DefaultValue: defaultValue}
+}
+
+// SelectPropertyDef creates a select property definition with the given options
+func SelectPropertyDef(label string, opts ...PropertyOption) PropertyDefinition {
+	return PropertyDefinition{Type: "select", Label: &label, Options: opts}
+}
+
+// UnmarshalJSON accepts both the label/value object form and the legacy
+// bare-value form, where the value doubles as the label
+func (o *PropertyOption) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	options, err := MigratePropertyOptions([]interface{}{raw})
+	if err != nil {
+		return err
+	}
+	*o = options[0]
+	return nil
+}
+
+// MigratePropertyOptions converts legacy untyped options into PropertyOptions.
+// Scalars become options whose label is the formatted value; objects must
+// carry a "value" and may carry "label" and "disabled".
+func MigratePropertyOptions(raw []interface{}) ([]PropertyOption, error) {
+	options := make([]PropertyOption, 0, len(raw))
+	for i, item := range raw {
+		switch v := item.(type) {
+		case string, float64, int, int64, bool:
+			options = append(options, PropertyOption{Label: fmt.Sprint(v), Value: v})
+		case map[string]interface{}:
+			value, ok := v["value"]
+			if !ok {
+				return nil, fmt.Errorf("option %d: missing value", i)
+			}
+			option := PropertyOption{Value: value, Label: fmt.Sprint(value)}
+			if label, ok := v["label"].(string); ok {
+				option.Label = label
+			}
+			if disabled, ok := v["disabled"].(bool); ok {
+				option.Disabled = &disabled
+			}
+			options = append(options, option)
+		default:
+			return nil, fmt.Errorf("option %d: unsupported type %T", i, item)
+		}
+	}
+	return options, nil
+}
+
+// NodeShape is the shape UI renderers draw a node with
+type NodeShape string
+
+const (
+	NodeShapeRectangle NodeShape = "rectangle"
+	NodeShapeCircle    NodeShape = "circle"
+	NodeShapeDiamond   NodeShape = "diamond"
+)
+
+// IsValid reports whether s is a known node shape
+func (s NodeShape) IsValid() bool {
+	switch s {
+	case NodeShapeRectangle, NodeShapeCircle, NodeShapeDiamond:
+		return true
+	}
+	return false
+}
+
+// NodeSize is the size UI renderers draw a node at
+type NodeSize string
+
+const (
+	NodeSizeSmall  NodeSize = "small"
+	NodeSizeMedium NodeSize = "medium"
+	NodeSizeLarge  NodeSize = "large"
+)
+
+// IsValid reports whether s is a known node size
+func (s NodeSize) IsValid() bool {
+	switch s {
+	case NodeSizeSmall, NodeSizeMedium, NodeSizeLarge:
+		return true
+	}
+	return false
+}
+
+// NodeVariant is a rendering hint UI renderers use to style a node
+type NodeVariant string
+
+const (
+	NodeVariantDefault   NodeVariant = "default"
+	NodeVariantTrigger   NodeVariant = "trigger"
+	NodeVariantAction    NodeVariant = "action"
+	NodeVariantCondition NodeVariant = "condition"
+	NodeVariantOutput    NodeVariant = "output"
+	NodeVariantLoop      NodeVariant = "loop"
+	NodeVariantGroup     NodeVariant = "group"
+	NodeVariantCustom    NodeVariant = "custom"
+)
+
+// IsValid reports whether v is a known node variant
+func (v NodeVariant) IsValid() bool {
+	switch v {
+	case NodeVariantDefault, NodeVariantTrigger, NodeVariantAction, NodeVariantCondition,
+		NodeVariantOutput, NodeVariantLoop, NodeVariantGroup, NodeVariantCustom:
+		return true
+	}
+	return false
+}
+
+// ParseNodeVariant converts s to a NodeVariant, reporting false for unknown
+// variants
+func ParseNodeVariant(s string) (NodeVariant, bool) {
+	variant := NodeVariant(s)
+	return variant, variant.IsValid()
+}
+
+// PortPosition is the side of a node a port is drawn on
+type PortPosition string
+
+const (
+	PortPositionTop    PortPosition = "top"
+	PortPositionBottom PortPosition = "bottom"
+	PortPositionLeft   PortPosition = "left"
+	PortPositionRight  PortPosition = "right"
+)
+
+// IsValid reports whether p is a known port position
+func (p PortPosition) IsValid() bool {
+	switch p {
+	case PortPositionTop, PortPositionBottom, PortPositionLeft, PortPositionRight:
+		return true
+	}
+	return false
+}
+
+// Opposite returns the mirror position, such as left for right. Unknown
+// positions are returned unchanged.
+func (p PortPosition) Opposite() PortPosition {
+	switch p {
+	case PortPositionTop:
+		return PortPositionBottom
+	case PortPositionBottom:
+		return PortPositionTop
+	case PortPositionLeft:
+		return PortPositionRight
+	case PortPositionRight:
+		return PortPositionLeft
+	}
+	return p
+}
+
+// FilterPortsByPosition returns the ports drawn at pos
+func FilterPortsByPosition(ports []Port, pos PortPosition) []Port {
+	filtered := make([]Port, 0)
+	for _, port := range ports {
+		if port.Position == pos {
+			filtered = append(filtered, port)
+		}
+	}
+	return filtered
+}
+
+var (
+	// ErrInvalidNodeShape is returned for shapes other than the NodeShape constants
+	ErrInvalidNodeShape = errors.New("invalid node shape")
+	// ErrInvalidNodeSize is returned for sizes other than the NodeSize constants
+	ErrInvalidNodeSize = errors.New("invalid node size")
+	// ErrInvalidPortPosition is returned for port positions other than the
+	// PortPosition constants
+	ErrInvalidPortPosition = errors.New("invalid port position")
+)
+
+// ParseNodeShape converts s to a NodeShape, rejecting unknown shapes
+func ParseNodeShape(s string) (NodeShape, error) {
+	shape := NodeShape(s)
+	if !shape.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidNodeShape, s)
+	}
+	return shape, nil
+}
+
+// ParseNodeSize converts s to a NodeSize, rejecting unknown sizes
+func ParseNodeSize(s string) (NodeSize, error) {
+	size := NodeSize(s)
+	if !size.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidNodeSize, s)
+	}
+	return size, nil
+}
+
+// ErrInvalidIconFormat is returned for icons not in "namespace:name" form
+var ErrInvalidIconFormat = errors.New(`icon must have the form "namespace:name"`)
+
+// KnownIconNamespaces lists the icon sets UI renderers can resolve
+var KnownIconNamespaces = []string{"lucide", "mdi", "tabler"}
+
+// ParseTemplateIcon splits an icon such as "lucide:activity" into its
+// namespace and name
+func ParseTemplateIcon(icon string) (namespace, name string, err error) {
+	namespace, name, found := strings.Cut(icon, ":")
+	if !found || namespace == "" || name == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidIconFormat, icon)
+	}
+	return namespace, name, nil
+}
+
+// ValidateIconNamespace reports whether namespace is in KnownIconNamespaces
+func ValidateIconNamespace(namespace string) bool {
+	for _, known := range KnownIconNamespaces {
+		if namespace == known {
+			return true
+		}
+	}
+	return false
+}
+
+// templateTagPattern matches tags such as "gpu" or "data-pipeline"
+var templateTagPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidateNodeTemplate checks a template for structural errors before registration
+func ValidateNodeTemplate(template NodeTemplate) error {
+	if template.ID == "" {
+		return errors.New("template ID is required")
+	}
+	if template.Title == "" {
+		return fmt.Errorf("template %s: title is required", template.ID)
+	}
+
+	for name, prop := range template.Properties {
+		if prop.Type == "select" && len(prop.Options) == 0 {
+			return fmt.Errorf("template %s: select property %s must define options", template.ID, name)
+		}
+	}
+
+	if template.Shape != nil && !template.Shape.IsValid() {
+		return fmt.Errorf("template %s: %w: %q", template.ID, ErrInvalidNodeShape, *template.Shape)
+	}
+	if template.Size != nil && !template.Size.IsValid() {
+		return fmt.Errorf("template %s: %w: %q", template.ID, ErrInvalidNodeSize, *template.Size)
+	}
+
+	// Unknown variants are not rejected, so templates can use variants added
+	// to renderers after this SDK version
+	if template.Variant != nil && !template.Variant.IsValid() {
+		logf("template %s: unknown node variant %q", template.ID, *template.Variant)
+	}
+
+	// Ports without a position are left to the renderer's default layout
+	for _, port := range template.Ports {
+		if port.Position != "" && !port.Position.IsValid() {
+			return fmt.Errorf("template %s: port %s: %w: %q", template.ID, port.ID, ErrInvalidPortPosition, port.Position)
+		}
+	}
+
+	// Icons are not rejected, since templates predating icon namespaces use bare names
+	if template.Icon != "" {
+		if namespace, _, err := ParseTemplateIcon(template.Icon); err != nil {
+			logf("template %s: %v", template.ID, err)
+		} else if !ValidateIconNamespace(namespace) {
+			logf("template %s: unknown icon namespace %q", template.ID, namespace)
+		}
+	}
+
+	for _, tag := range template.Tags {
+		if !templateTagPattern.MatchString(tag) {
+			return fmt.Errorf("template %s: tag %q must be lowercase alphanumeric words separated by hyphens", template.ID, tag)
+		}
+	}
+
+	return nil
+}
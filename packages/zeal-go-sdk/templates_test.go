@@ -0,0 +1,316 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFilterTemplates(t *testing.T) {
+	gpu := true
+	sub := "http"
+	templates := []NodeTemplate{
+		{ID: "tpl_http", Title: "HTTP Request", Category: "integrations", Subcategory: &sub, Tags: []string{"network"}},
+		{ID: "tpl_llm", Title: "LLM Prompt", Category: "ai", Runtime: &RuntimeRequirements{GPU: &gpu}, Tags: []string{"ml", "text"}},
+		{ID: "tpl_embed", Title: "Embeddings", Category: "ai", Description: "Compute text embeddings", Tags: []string{"ml"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    *TemplateSearchQuery
+		expected []string
+	}{
+		{"nil query", nil, []string{"tpl_http", "tpl_llm", "tpl_embed"}},
+		{"category", &TemplateSearchQuery{CategoryFilter: "ai"}, []string{"tpl_llm", "tpl_embed"}},
+		{"subcategory", &TemplateSearchQuery{SubcategoryFilter: &sub}, []string{"tpl_http"}},
+		{"gpu", &TemplateSearchQuery{HasGPU: &gpu}, []string{"tpl_llm"}},
+		{"tags", &TemplateSearchQuery{Tags: []string{"ml", "text"}}, []string{"tpl_llm"}},
+		{"text", &TemplateSearchQuery{TextSearch: "EMBEDDINGS"}, []string{"tpl_embed"}},
+		{"max results", &TemplateSearchQuery{CategoryFilter: "ai", MaxResults: 1}, []string{"tpl_llm"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := FilterTemplates(templates, test.query)
+			if len(result) != len(test.expected) {
+				t.Fatalf("Expected %d templates, got %d", len(test.expected), len(result))
+			}
+			for i, id := range test.expected {
+				if result[i].ID != id {
+					t.Errorf("Expected template %d to be '%s', got '%s'", i, id, result[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchTemplatesFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/zip/templates/search":
+			http.NotFound(w, r)
+		case "/api/zip/templates/default":
+			json.NewEncoder(w).Encode(ListTemplatesResponse{
+				Templates: []NodeTemplate{
+					{ID: "tpl_a", Title: "Alpha", Category: "data"},
+					{ID: "tpl_b", Title: "Beta", Category: "ai"},
+				},
+				Total: 2,
+			})
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	config.MaxRetries = 0
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	result, err := client.Templates().SearchTemplates(context.Background(), "default", &TemplateSearchQuery{CategoryFilter: "ai"})
+	if err != nil {
+		t.Fatalf("Expected fallback search to succeed, got %v", err)
+	}
+	if result.Total != 1 || result.Templates[0].ID != "tpl_b" {
+		t.Errorf("Expected only 'tpl_b', got %+v", result.Templates)
+	}
+}
+
+func TestTemplateDependencyGraph(t *testing.T) {
+	templates := []NodeTemplate{
+		{ID: "tpl_root", DependsOn: []string{"tpl_sub", "tpl_leaf"}},
+		{ID: "tpl_sub", DependsOn: []string{"tpl_leaf"}},
+		{ID: "tpl_leaf"},
+	}
+
+	graph, err := BuildTemplateDependencyGraph(templates)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+
+	if graph.HasCycle() {
+		t.Error("Expected acyclic graph")
+	}
+
+	order := graph.TopoSort()
+	expected := []string{"tpl_leaf", "tpl_sub", "tpl_root"}
+	for i, id := range expected {
+		if order[i] != id {
+			t.Errorf("Expected TopoSort()[%d] to be '%s', got '%s'", i, id, order[i])
+		}
+	}
+
+	if ancestors := graph.Ancestors("tpl_leaf"); len(ancestors) != 2 {
+		t.Errorf("Expected 2 ancestors of tpl_leaf, got %v", ancestors)
+	}
+	if descendants := graph.Descendants("tpl_root"); len(descendants) != 2 {
+		t.Errorf("Expected 2 descendants of tpl_root, got %v", descendants)
+	}
+
+	cyclic, err := BuildTemplateDependencyGraph([]NodeTemplate{
+		{ID: "a", DependsOn: []string{"b"}},
+		{ID: "b", DependsOn: []string{"a"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	if !cyclic.HasCycle() {
+		t.Error("Expected cycle to be detected")
+	}
+
+	if _, err := BuildTemplateDependencyGraph([]NodeTemplate{{ID: "a", DependsOn: []string{"missing"}}}); err == nil {
+		t.Error("Expected error for unknown dependency")
+	}
+}
+
+func TestPropertyOptionUnmarshal(t *testing.T) {
+	var def PropertyDefinition
+	data := []byte(`{"type":"select","options":["GET",{"label":"Post","value":"POST","disabled":true}]}`)
+	if err := json.Unmarshal(data, &def); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	if len(def.Options) != 2 {
+		t.Fatalf("Expected 2 options, got %d", len(def.Options))
+	}
+	if def.Options[0].Label != "GET" || def.Options[0].Value != "GET" {
+		t.Errorf("Expected legacy option to migrate, got %+v", def.Options[0])
+	}
+	if def.Options[1].Label != "Post" || def.Options[1].Disabled == nil || !*def.Options[1].Disabled {
+		t.Errorf("Expected object option to be preserved, got %+v", def.Options[1])
+	}
+}
+
+func TestValidateNodeTemplate(t *testing.T) {
+	template := NodeTemplate{
+		ID:    "tpl_http",
+		Title: "HTTP Request",
+		Properties: map[string]PropertyDefinition{
+			"method": SelectPropertyDef("Method"),
+		},
+	}
+	if err := ValidateNodeTemplate(template); err == nil {
+		t.Error("Expected error for select property without options")
+	}
+
+	template.Properties["method"] = SelectPropertyDef("Method", PropertyOption{Label: "GET", Value: "GET"})
+	if err := ValidateNodeTemplate(template); err != nil {
+		t.Errorf("Expected valid template, got %v", err)
+	}
+
+	template.Tags = []string{"gpu", "data-pipeline", "v2"}
+	if err := ValidateNodeTemplate(template); err != nil {
+		t.Errorf("Expected valid tags, got %v", err)
+	}
+	for _, tag := range []string{"GPU", "data_pipeline", "-beta", "two  words", ""} {
+		template.Tags = []string{tag}
+		if err := ValidateNodeTemplate(template); err == nil {
+			t.Errorf("Expected error for tag %q", tag)
+		}
+	}
+}
+
+func TestNodeShapeAndSize(t *testing.T) {
+	if shape, err := ParseNodeShape("rectangle"); err != nil || shape != NodeShapeRectangle {
+		t.Errorf("Expected rectangle, got %q (%v)", shape, err)
+	}
+	if _, err := ParseNodeShape("hexagon"); !errors.Is(err, ErrInvalidNodeShape) {
+		t.Errorf("Expected ErrInvalidNodeShape, got %v", err)
+	}
+	if size, err := ParseNodeSize("medium"); err != nil || size != NodeSizeMedium {
+		t.Errorf("Expected medium, got %q (%v)", size, err)
+	}
+	if _, err := ParseNodeSize("md"); !errors.Is(err, ErrInvalidNodeSize) {
+		t.Errorf("Expected ErrInvalidNodeSize, got %v", err)
+	}
+
+	shape, size := NodeShapeCircle, NodeSizeSmall
+	template := NodeTemplate{ID: "tpl", Title: "Node", Shape: &shape, Size: &size}
+	if err := ValidateNodeTemplate(template); err != nil {
+		t.Errorf("Expected valid template, got %v", err)
+	}
+	shape = "triangle"
+	if err := ValidateNodeTemplate(template); !errors.Is(err, ErrInvalidNodeShape) {
+		t.Errorf("Expected ErrInvalidNodeShape, got %v", err)
+	}
+	shape, size = NodeShapeCircle, "huge"
+	if err := ValidateNodeTemplate(template); !errors.Is(err, ErrInvalidNodeSize) {
+		t.Errorf("Expected ErrInvalidNodeSize, got %v", err)
+	}
+}
+
+func TestNodeVariant(t *testing.T) {
+	if variant, ok := ParseNodeVariant("trigger"); !ok || variant != NodeVariantTrigger {
+		t.Errorf("Expected trigger, got %q (%v)", variant, ok)
+	}
+	if variant, ok := ParseNodeVariant("blue-600"); ok || variant != "blue-600" {
+		t.Errorf("Expected unknown variant to be reported, got %q (%v)", variant, ok)
+	}
+
+	log := &recordingLogger{}
+	useLogger(t, log)
+
+	variant := NodeVariantCondition
+	template := NodeTemplate{ID: "tpl", Title: "Node", Variant: &variant}
+	if err := ValidateNodeTemplate(template); err != nil || len(log.messages) != 0 {
+		t.Errorf("Expected known variant to pass silently, got %v, %v", err, log.messages)
+	}
+	variant = "sparkly"
+	if err := ValidateNodeTemplate(template); err != nil {
+		t.Errorf("Expected unknown variant not to be rejected, got %v", err)
+	}
+	if len(log.messages) != 1 || !strings.Contains(log.messages[0], "sparkly") {
+		t.Errorf("Expected a warning for the unknown variant, got %v", log.messages)
+	}
+}
+
+func TestPortPosition(t *testing.T) {
+	opposites := map[PortPosition]PortPosition{
+		PortPositionTop:    PortPositionBottom,
+		PortPositionBottom: PortPositionTop,
+		PortPositionLeft:   PortPositionRight,
+		PortPositionRight:  PortPositionLeft,
+	}
+	for pos, want := range opposites {
+		if got := pos.Opposite(); got != want {
+			t.Errorf("Expected opposite of %s to be %s, got %s", pos, want, got)
+		}
+	}
+
+	ports := []Port{
+		{ID: "in", Position: PortPositionLeft},
+		{ID: "out", Position: PortPositionRight},
+		{ID: "config", Position: PortPositionLeft},
+	}
+	if left := FilterPortsByPosition(ports, PortPositionLeft); len(left) != 2 || left[0].ID != "in" || left[1].ID != "config" {
+		t.Errorf("Unexpected left ports %v", left)
+	}
+	if top := FilterPortsByPosition(ports, PortPositionTop); len(top) != 0 {
+		t.Errorf("Expected no top ports, got %v", top)
+	}
+
+	template := NodeTemplate{ID: "tpl", Title: "Node", Ports: append(ports, Port{ID: "unplaced"})}
+	if err := ValidateNodeTemplate(template); err != nil {
+		t.Errorf("Expected valid template, got %v", err)
+	}
+	template.Ports = append(template.Ports, Port{ID: "bad", Position: "center"})
+	if err := ValidateNodeTemplate(template); !errors.Is(err, ErrInvalidPortPosition) {
+		t.Errorf("Expected ErrInvalidPortPosition, got %v", err)
+	}
+}
+
+func TestFilterTemplatesByTag(t *testing.T) {
+	templates := []NodeTemplate{
+		{ID: "a", Tags: []string{"gpu", "beta"}},
+		{ID: "b", Tags: []string{"gpu"}},
+		{ID: "c"},
+	}
+
+	if !HasTag(templates[0], "BETA") || HasTag(templates[2], "gpu") {
+		t.Error("Unexpected HasTag result")
+	}
+
+	result := FilterTemplatesByTag(templates, []string{"gpu", "beta"})
+	if len(result) != 1 || result[0].ID != "a" {
+		t.Errorf("Expected only template a, got %v", result)
+	}
+	if result := FilterTemplatesByTag(templates, nil); len(result) != 3 {
+		t.Errorf("Expected no tags to match every template, got %d", len(result))
+	}
+}
+
+func TestParseTemplateIcon(t *testing.T) {
+	namespace, name, err := ParseTemplateIcon("lucide:activity")
+	if err != nil || namespace != "lucide" || name != "activity" {
+		t.Errorf("Expected lucide/activity, got %s/%s (%v)", namespace, name, err)
+	}
+	for _, icon := range []string{"activity", ":activity", "lucide:"} {
+		if _, _, err := ParseTemplateIcon(icon); !errors.Is(err, ErrInvalidIconFormat) {
+			t.Errorf("Expected ErrInvalidIconFormat for %q, got %v", icon, err)
+		}
+	}
+
+	if !ValidateIconNamespace("mdi") || ValidateIconNamespace("fontawesome") {
+		t.Error("Unexpected namespace validation result")
+	}
+}
+
+func TestValidateNodeTemplateWarnsOnIcons(t *testing.T) {
+	log := &recordingLogger{}
+	useLogger(t, log)
+
+	for _, icon := range []string{"lucide:activity", "fontawesome:robot", "database"} {
+		if err := ValidateNodeTemplate(NodeTemplate{ID: "tpl", Title: "Node", Icon: icon}); err != nil {
+			t.Errorf("Expected icon %q to be accepted, got %v", icon, err)
+		}
+	}
+	if len(log.messages) != 2 {
+		t.Errorf("Expected warnings for the unknown namespace and bare icon, got %v", log.messages)
+	}
+}
@@ -0,0 +1,112 @@
+package zeal
+
+import (
+	"sort"
+	"sync"
+)
+
+// TeamSet is a set of team names for constant-time membership checks
+type TeamSet map[string]struct{}
+
+// NewTeamSet returns a TeamSet holding names
+func NewTeamSet(names ...string) TeamSet {
+	return TeamSet(newStringSet(names))
+}
+
+// Contains reports whether name is in the set
+func (s TeamSet) Contains(name string) bool {
+	_, ok := s[name]
+	return ok
+}
+
+// Intersects reports whether the sets share at least one team
+func (s TeamSet) Intersects(other TeamSet) bool {
+	return intersects(s, other)
+}
+
+// ToSlice returns the team names in sorted order
+func (s TeamSet) ToSlice() []string {
+	return sortedKeys(s)
+}
+
+// GroupSet is a set of group names for constant-time membership checks
+type GroupSet map[string]struct{}
+
+// NewGroupSet returns a GroupSet holding names
+func NewGroupSet(names ...string) GroupSet {
+	return GroupSet(newStringSet(names))
+}
+
+// Contains reports whether name is in the set
+func (s GroupSet) Contains(name string) bool {
+	_, ok := s[name]
+	return ok
+}
+
+// Intersects reports whether the sets share at least one group
+func (s GroupSet) Intersects(other GroupSet) bool {
+	return intersects(s, other)
+}
+
+// ToSlice returns the group names in sorted order
+func (s GroupSet) ToSlice() []string {
+	return sortedKeys(s)
+}
+
+// tokenPayloadSets caches the sets built from a parsed TokenPayload
+type tokenPayloadSets struct {
+	teamsOnce  sync.Once
+	teams      TeamSet
+	groupsOnce sync.Once
+	groups     GroupSet
+}
+
+// TeamSet returns p.Teams as a set. For payloads returned by
+// VerifyAndParseToken and ParseTokenUnsafe the set is built on the first call
+// and cached, so changes to Teams after that call are not reflected; for
+// other payloads it is built on every call.
+func (p *TokenPayload) TeamSet() TeamSet {
+	if p.sets == nil {
+		return NewTeamSet(p.Teams...)
+	}
+	p.sets.teamsOnce.Do(func() { p.sets.teams = NewTeamSet(p.Teams...) })
+	return p.sets.teams
+}
+
+// GroupSet returns p.Groups as a set, cached like TeamSet
+func (p *TokenPayload) GroupSet() GroupSet {
+	if p.sets == nil {
+		return NewGroupSet(p.Groups...)
+	}
+	p.sets.groupsOnce.Do(func() { p.sets.groups = NewGroupSet(p.Groups...) })
+	return p.sets.groups
+}
+
+func newStringSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	return set
+}
+
+func intersects(a, b map[string]struct{}) bool {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	for name := range a {
+		if _, ok := b[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+package zeal
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestTeamAndGroupSets(t *testing.T) {
+	teams := NewTeamSet("core", "infra", "core")
+	if !teams.Contains("infra") || teams.Contains("sales") {
+		t.Error("Unexpected Contains result")
+	}
+	if !teams.Intersects(NewTeamSet("sales", "core")) || teams.Intersects(NewTeamSet("sales")) || teams.Intersects(nil) {
+		t.Error("Unexpected Intersects result")
+	}
+	if !reflect.DeepEqual(teams.ToSlice(), []string{"core", "infra"}) {
+		t.Errorf("Expected sorted unique teams, got %v", teams.ToSlice())
+	}
+
+	groups := NewGroupSet("admins")
+	if !groups.Contains("admins") || !groups.Intersects(NewGroupSet("admins", "ops")) {
+		t.Error("Unexpected GroupSet result")
+	}
+}
+
+func TestTokenPayloadSetsCached(t *testing.T) {
+	token, err := GenerateAuthToken(&TokenSubject{ID: "user", Teams: []string{"core"}, Groups: []string{"admins"}}, &TokenOptions{SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("GenerateAuthToken failed: %v", err)
+	}
+	payload, err := VerifyAndParseToken(token, "secret")
+	if err != nil {
+		t.Fatalf("VerifyAndParseToken failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !payload.TeamSet().Contains("core") || !payload.GroupSet().Contains("admins") {
+				t.Error("Expected parsed teams and groups in the sets")
+			}
+		}()
+	}
+	wg.Wait()
+
+	payload.Teams = append(payload.Teams, "infra")
+	if payload.TeamSet().Contains("infra") {
+		t.Error("Expected the team set to be cached after the first call")
+	}
+
+	manual := &TokenPayload{Teams: []string{"core"}}
+	manual.TeamSet()
+	manual.Teams = []string{"infra"}
+	if !manual.TeamSet().Contains("infra") {
+		t.Error("Expected sets of unparsed payloads to follow Teams")
+	}
+}
@@ -0,0 +1,182 @@
+package zeal
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSubmitterClosed is returned when submitting to a closed BatchTraceSubmitter
+var ErrSubmitterClosed = errors.New("batch trace submitter is closed")
+
+// BatchSubmitOptions configures a BatchTraceSubmitter
+type BatchSubmitOptions struct {
+	// MaxBatchSize triggers a flush once this many events are pending; defaults to 100
+	MaxBatchSize int
+	// FlushInterval flushes pending events periodically; defaults to 1s, negative disables
+	FlushInterval time.Duration
+	// OnFlushError is called when an automatic flush fails
+	OnFlushError func(error)
+}
+
+// BatchTraceSubmitter buffers trace events of a session and submits them in
+// batches. Events of a failed flush are dropped. It is safe for concurrent use.
+type BatchTraceSubmitter struct {
+	api       *TracesAPI
+	sessionID string
+	opts      BatchSubmitOptions
+	pending   []TraceEvent
+	closed    bool
+	stop      chan struct{}
+	mu        sync.Mutex
+	flushMu   sync.Mutex
+}
+
+// NewBatchSubmitter creates a BatchTraceSubmitter for sessionID. Pending
+// events are flushed automatically when the session is completed with
+// CompleteSession.
+func (api *TracesAPI) NewBatchSubmitter(sessionID string, opts *BatchSubmitOptions) *BatchTraceSubmitter {
+	b := &BatchTraceSubmitter{
+		api:       api,
+		sessionID: sessionID,
+		opts:      BatchSubmitOptions{MaxBatchSize: 100, FlushInterval: time.Second},
+		stop:      make(chan struct{}),
+	}
+	if opts != nil {
+		if opts.MaxBatchSize > 0 {
+			b.opts.MaxBatchSize = opts.MaxBatchSize
+		}
+		if opts.FlushInterval != 0 {
+			b.opts.FlushInterval = opts.FlushInterval
+		}
+		b.opts.OnFlushError = opts.OnFlushError
+	}
+
+	api.batchMu.Lock()
+	if api.batchSubmitters == nil {
+		api.batchSubmitters = make(map[string][]*BatchTraceSubmitter)
+	}
+	api.batchSubmitters[sessionID] = append(api.batchSubmitters[sessionID], b)
+	api.batchMu.Unlock()
+
+	if b.opts.FlushInterval > 0 {
+		go b.flushPeriodically()
+	}
+	return b
+}
+
+// Submit queues an event without blocking. A full batch is flushed in the
+// background.
+func (b *BatchTraceSubmitter) Submit(event TraceEvent) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrSubmitterClosed
+	}
+	b.pending = append(b.pending, event)
+	full := len(b.pending) >= b.opts.MaxBatchSize
+	b.mu.Unlock()
+
+	if full {
+		go b.autoFlush()
+	}
+	return nil
+}
+
+// Flush submits all pending events and waits for the request to finish
+func (b *BatchTraceSubmitter) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	events := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+	_, err := b.api.SubmitEvents(ctx, b.sessionID, events)
+	return err
+}
+
+// Close flushes pending events and stops the submitter
+func (b *BatchTraceSubmitter) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.stop)
+	b.mu.Unlock()
+
+	b.api.removeBatchSubmitter(b)
+	return b.Flush(ctx)
+}
+
+// Pending returns the number of events waiting to be flushed
+func (b *BatchTraceSubmitter) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+func (b *BatchTraceSubmitter) flushPeriodically() {
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.autoFlush()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+func (b *BatchTraceSubmitter) autoFlush() {
+	if err := b.Flush(context.Background()); err != nil {
+		b.reportError(err)
+	}
+}
+
+func (b *BatchTraceSubmitter) reportError(err error) {
+	if b.opts.OnFlushError != nil {
+		b.opts.OnFlushError(err)
+	} else {
+		logf("batch trace flush failed for session %s: %v", b.sessionID, err)
+	}
+}
+
+func (api *TracesAPI) removeBatchSubmitter(b *BatchTraceSubmitter) {
+	api.batchMu.Lock()
+	defer api.batchMu.Unlock()
+
+	submitters := api.batchSubmitters[b.sessionID]
+	for i, s := range submitters {
+		if s == b {
+			submitters = append(submitters[:i], submitters[i+1:]...)
+			break
+		}
+	}
+	if len(submitters) == 0 {
+		delete(api.batchSubmitters, b.sessionID)
+	} else {
+		api.batchSubmitters[b.sessionID] = submitters
+	}
+}
+
+// closeBatchSubmitters flushes and closes the submitters of a session
+func (api *TracesAPI) closeBatchSubmitters(ctx context.Context, sessionID string) {
+	api.batchMu.Lock()
+	submitters := api.batchSubmitters[sessionID]
+	api.batchMu.Unlock()
+
+	for _, b := range submitters {
+		if err := b.Close(ctx); err != nil {
+			b.reportError(err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchServer records the batch sizes of submitted trace events
+type batchServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	batches   []int
+	completed bool
+}
+
+func newBatchServer() *batchServer {
+	s := &batchServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch r.URL.Path {
+		case "/api/zip/traces/s1/events":
+			var body struct {
+				Events []TraceEvent `json:"events"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			s.batches = append(s.batches, len(body.Events))
+			w.Write([]byte(`{"success":true}`))
+		case "/api/zip/traces/s1/complete":
+			s.completed = true
+			w.Write([]byte(`{"success":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return s
+}
+
+func (s *batchServer) snapshot() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.batches...)
+}
+
+func newBatchTestClient(t *testing.T, server *batchServer) *TracesAPI {
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return client.Traces()
+}
+
+func TestBatchSubmitterFlushesFullBatch(t *testing.T) {
+	server := newBatchServer()
+	defer server.Close()
+	api := newBatchTestClient(t, server)
+
+	submitter := api.NewBatchSubmitter("s1", &BatchSubmitOptions{MaxBatchSize: 2, FlushInterval: -1})
+	defer submitter.Close(context.Background())
+
+	submitter.Submit(TraceEvent{NodeID: "a", EventType: "input"})
+	submitter.Submit(TraceEvent{NodeID: "a", EventType: "output"})
+
+	deadline := time.Now().Add(time.Second)
+	for len(server.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if batches := server.snapshot(); len(batches) != 1 || batches[0] != 2 {
+		t.Errorf("Expected one batch of 2 events, got %v", batches)
+	}
+}
+
+func TestBatchSubmitterFlushesOnInterval(t *testing.T) {
+	server := newBatchServer()
+	defer server.Close()
+	api := newBatchTestClient(t, server)
+
+	submitter := api.NewBatchSubmitter("s1", &BatchSubmitOptions{MaxBatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer submitter.Close(context.Background())
+
+	submitter.Submit(TraceEvent{NodeID: "a", EventType: "log"})
+	deadline := time.Now().Add(time.Second)
+	for len(server.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if batches := server.snapshot(); len(batches) != 1 || batches[0] != 1 {
+		t.Errorf("Expected one batch of 1 event, got %v", batches)
+	}
+}
+
+func TestBatchSubmitterFlushesOnSessionComplete(t *testing.T) {
+	server := newBatchServer()
+	defer server.Close()
+	api := newBatchTestClient(t, server)
+
+	submitter := api.NewBatchSubmitter("s1", &BatchSubmitOptions{FlushInterval: -1})
+	for i := 0; i < 3; i++ {
+		submitter.Submit(TraceEvent{NodeID: "a", EventType: "log"})
+	}
+
+	if _, err := api.CompleteSession(context.Background(), "s1", CompleteSessionRequest{Status: SessionStatusCompleted}); err != nil {
+		t.Fatalf("CompleteSession failed: %v", err)
+	}
+	if batches := server.snapshot(); len(batches) != 1 || batches[0] != 3 {
+		t.Errorf("Expected pending events flushed before completion, got %v", batches)
+	}
+	if err := submitter.Submit(TraceEvent{}); err != ErrSubmitterClosed {
+		t.Errorf("Expected ErrSubmitterClosed, got %v", err)
+	}
+}
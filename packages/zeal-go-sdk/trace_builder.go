@@ -0,0 +1,95 @@
+package zeal
+
+import "time"
+
+// TraceEventBuilder assembles a TraceEvent. Builders made with
+// NewTimedTraceEventBuilder, or on which MarkStart was called, fill in
+// Duration from the recorded start time when Build is called.
+type TraceEventBuilder struct {
+	event     TraceEvent
+	startTime time.Time
+	endTime   time.Time
+}
+
+// NewTraceEventBuilder creates a builder for an event of eventType on nodeID
+func NewTraceEventBuilder(nodeID, eventType string) *TraceEventBuilder {
+	return &TraceEventBuilder{event: TraceEvent{NodeID: nodeID, EventType: eventType}}
+}
+
+// NewTimedTraceEventBuilder is like NewTraceEventBuilder but also starts
+// timing the event
+func NewTimedTraceEventBuilder(nodeID, eventType string) *TraceEventBuilder {
+	return NewTraceEventBuilder(nodeID, eventType).MarkStart()
+}
+
+// MarkStart starts timing the event now, discarding any earlier MarkEnd
+func (b *TraceEventBuilder) MarkStart() *TraceEventBuilder {
+	b.startTime = time.Now()
+	b.endTime = time.Time{}
+	return b
+}
+
+// MarkEnd stops timing the event now. Without it, Build measures up to the
+// time it is called.
+func (b *TraceEventBuilder) MarkEnd() *TraceEventBuilder {
+	b.endTime = time.Now()
+	return b
+}
+
+// WithPort sets the port the event refers to
+func (b *TraceEventBuilder) WithPort(portID string) *TraceEventBuilder {
+	b.event.PortID = &portID
+	return b
+}
+
+// WithData sets the event data
+func (b *TraceEventBuilder) WithData(data TraceData) *TraceEventBuilder {
+	b.event.Data = data
+	return b
+}
+
+// WithDuration sets the duration explicitly, overriding the measured one
+func (b *TraceEventBuilder) WithDuration(d time.Duration) *TraceEventBuilder {
+	ms := d.Milliseconds()
+	b.event.Duration = &ms
+	return b
+}
+
+// WithMetadata sets a metadata value
+func (b *TraceEventBuilder) WithMetadata(key string, value interface{}) *TraceEventBuilder {
+	if b.event.Metadata == nil {
+		b.event.Metadata = make(map[string]interface{})
+	}
+	b.event.Metadata[key] = value
+	return b
+}
+
+// WithError sets the event error
+func (b *TraceEventBuilder) WithError(err *TraceError) *TraceEventBuilder {
+	b.event.Error = err
+	return b
+}
+
+// Build returns the event. Timestamp defaults to now, and Duration, when not
+// set explicitly, is the time between MarkStart and MarkEnd or now.
+func (b *TraceEventBuilder) Build() TraceEvent {
+	event := b.event
+	if b.event.Metadata != nil {
+		event.Metadata = make(map[string]interface{}, len(b.event.Metadata))
+		for key, value := range b.event.Metadata {
+			event.Metadata[key] = value
+		}
+	}
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().UnixMilli()
+	}
+	if event.Duration == nil && !b.startTime.IsZero() {
+		end := b.endTime
+		if end.IsZero() {
+			end = time.Now()
+		}
+		ms := end.Sub(b.startTime).Milliseconds()
+		event.Duration = &ms
+	}
+	return event
+}
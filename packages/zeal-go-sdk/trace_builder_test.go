@@ -0,0 +1,39 @@
+package zeal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTraceEventBuilder(t *testing.T) {
+	event := NewTraceEventBuilder("n1", "output").WithPort("out").WithMetadata("attempt", 2).Build()
+	if event.NodeID != "n1" || event.EventType != "output" || *event.PortID != "out" || event.Metadata["attempt"] != 2 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if event.Duration != nil {
+		t.Errorf("Expected no duration for an untimed builder, got %d", *event.Duration)
+	}
+	if event.Timestamp == 0 {
+		t.Error("Expected timestamp to default to now")
+	}
+
+	timed := NewTimedTraceEventBuilder("n1", "output")
+	time.Sleep(20 * time.Millisecond)
+	event = timed.Build()
+	if event.Duration == nil || *event.Duration < 20 {
+		t.Errorf("Expected measured duration of at least 20ms, got %v", event.Duration)
+	}
+
+	builder := NewTraceEventBuilder("n1", "output").MarkStart()
+	time.Sleep(20 * time.Millisecond)
+	builder.MarkEnd()
+	time.Sleep(50 * time.Millisecond)
+	if duration := *builder.Build().Duration; duration < 20 || duration >= 70 {
+		t.Errorf("Expected duration to stop at MarkEnd, got %dms", duration)
+	}
+
+	explicit := NewTimedTraceEventBuilder("n1", "output").WithDuration(5 * time.Second).Build()
+	if *explicit.Duration != 5000 {
+		t.Errorf("Expected explicit duration to win, got %d", *explicit.Duration)
+	}
+}
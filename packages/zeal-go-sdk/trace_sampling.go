@@ -0,0 +1,87 @@
+package zeal
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// TraceSampler decides which trace events are submitted
+type TraceSampler interface {
+	ShouldSample(event TraceEvent) bool
+}
+
+// WithSampler makes SubmitEvent and TraceNodeExecution drop events rejected by
+// s. Error events are always submitted. A nil sampler submits every event.
+func (api *TracesAPI) WithSampler(s TraceSampler) *TracesAPI {
+	api.sampler = s
+	return api
+}
+
+// sampled reports whether event should be submitted
+func (api *TracesAPI) sampled(event TraceEvent) bool {
+	if api.sampler == nil || isErrorTraceEvent(event) {
+		return true
+	}
+	return api.sampler.ShouldSample(event)
+}
+
+func isErrorTraceEvent(event TraceEvent) bool {
+	return event.Error != nil || event.EventType == "error"
+}
+
+// rateSampler is a token bucket refilled at a fixed rate
+type rateSampler struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// RateSampler samples at most eventsPerSecond events per second, allowing
+// bursts of up to one second's worth of events
+func RateSampler(eventsPerSecond float64) TraceSampler {
+	return &rateSampler{rate: eventsPerSecond, tokens: eventsPerSecond, last: time.Now()}
+}
+
+func (s *rateSampler) ShouldSample(event TraceEvent) bool {
+	if isErrorTraceEvent(event) {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.tokens += now.Sub(s.last).Seconds() * s.rate
+	if s.tokens > s.rate {
+		s.tokens = s.rate
+	}
+	s.last = now
+
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+// probabilisticSampler samples each event independently
+type probabilisticSampler struct {
+	rate float64
+}
+
+// ProbabilisticSampler samples error events always and other events with
+// probability rate, clamped to [0, 1]
+func ProbabilisticSampler(rate float64) TraceSampler {
+	if rate < 0 {
+		rate = 0
+	} else if rate > 1 {
+		rate = 1
+	}
+	return probabilisticSampler{rate: rate}
+}
+
+func (s probabilisticSampler) ShouldSample(event TraceEvent) bool {
+	return isErrorTraceEvent(event) || rand.Float64() < s.rate
+}
@@ -0,0 +1,54 @@
+package zeal
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRateSamplerLimitsBurst(t *testing.T) {
+	sampler := RateSampler(3)
+	sampled := 0
+	for i := 0; i < 10; i++ {
+		if sampler.ShouldSample(TraceEvent{EventType: "log"}) {
+			sampled++
+		}
+	}
+	if sampled != 3 {
+		t.Errorf("Expected 3 events sampled, got %d", sampled)
+	}
+	if !sampler.ShouldSample(TraceEvent{EventType: "error"}) {
+		t.Error("Expected error events to always be sampled")
+	}
+}
+
+func TestProbabilisticSampler(t *testing.T) {
+	never := ProbabilisticSampler(0)
+	always := ProbabilisticSampler(1)
+	for i := 0; i < 100; i++ {
+		if never.ShouldSample(TraceEvent{EventType: "log"}) {
+			t.Fatal("Expected rate 0 to drop all events")
+		}
+		if !always.ShouldSample(TraceEvent{EventType: "log"}) {
+			t.Fatal("Expected rate 1 to keep all events")
+		}
+	}
+	if !never.ShouldSample(TraceEvent{EventType: "output", Error: &TraceError{Message: "boom"}}) {
+		t.Error("Expected events with an error to always be sampled")
+	}
+}
+
+func TestTracesAPISamplerSkipsSubmission(t *testing.T) {
+	server := newBatchServer()
+	defer server.Close()
+	api := newBatchTestClient(t, server).WithSampler(ProbabilisticSampler(0))
+
+	if err := api.TraceNodeExecution(context.Background(), "s1", "a", "output", "x", nil); err != nil {
+		t.Fatalf("TraceNodeExecution failed: %v", err)
+	}
+	if err := api.TraceNodeExecution(context.Background(), "s1", "a", "error", "x", nil); err != nil {
+		t.Fatalf("TraceNodeExecution failed: %v", err)
+	}
+	if batches := server.snapshot(); len(batches) != 1 {
+		t.Errorf("Expected only the error event to be submitted, got %v", batches)
+	}
+}
@@ -0,0 +1,44 @@
+package zeal
+
+import "context"
+
+// ScopedTracesAPI is a TracesAPI bound to one trace session, so calls do not
+// need to repeat the session ID
+type ScopedTracesAPI struct {
+	api       *TracesAPI
+	sessionID string
+}
+
+// WithSession returns a ScopedTracesAPI for sessionID, such as the ID
+// returned by CreateSession
+func (api *TracesAPI) WithSession(sessionID string) *ScopedTracesAPI {
+	return &ScopedTracesAPI{api: api, sessionID: sessionID}
+}
+
+// SessionID returns the session the API is bound to
+func (s *ScopedTracesAPI) SessionID() string {
+	return s.sessionID
+}
+
+// SubmitEvent submits a single trace event to the session, unless the
+// sampler of the underlying TracesAPI drops it
+func (s *ScopedTracesAPI) SubmitEvent(ctx context.Context, event TraceEvent) error {
+	_, err := s.api.SubmitEvent(ctx, s.sessionID, event)
+	return err
+}
+
+// SubmitEvents submits trace events to the session
+func (s *ScopedTracesAPI) SubmitEvents(ctx context.Context, events []TraceEvent) error {
+	_, err := s.api.SubmitEvents(ctx, s.sessionID, events)
+	return err
+}
+
+// Complete completes the session, flushing its batch submitters first
+func (s *ScopedTracesAPI) Complete(ctx context.Context, req CompleteSessionRequest) (*CompleteSessionResponse, error) {
+	return s.api.CompleteSession(ctx, s.sessionID, req)
+}
+
+// NewBatcher creates a BatchTraceSubmitter for the session
+func (s *ScopedTracesAPI) NewBatcher(opts *BatchSubmitOptions) *BatchTraceSubmitter {
+	return s.api.NewBatchSubmitter(s.sessionID, opts)
+}
@@ -0,0 +1,42 @@
+package zeal
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScopedTracesAPI(t *testing.T) {
+	server := newBatchServer()
+	defer server.Close()
+	scoped := newBatchTestClient(t, server).WithSession("s1")
+	ctx := context.Background()
+
+	if scoped.SessionID() != "s1" {
+		t.Errorf("Expected session s1, got %s", scoped.SessionID())
+	}
+	if err := scoped.SubmitEvent(ctx, TraceEvent{NodeID: "n1", EventType: "output"}); err != nil {
+		t.Fatalf("SubmitEvent failed: %v", err)
+	}
+	if err := scoped.SubmitEvents(ctx, []TraceEvent{{NodeID: "n1"}, {NodeID: "n2"}}); err != nil {
+		t.Fatalf("SubmitEvents failed: %v", err)
+	}
+
+	batcher := scoped.NewBatcher(&BatchSubmitOptions{FlushInterval: time.Hour})
+	if err := batcher.Submit(TraceEvent{NodeID: "n3"}); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	if _, err := scoped.Complete(ctx, CompleteSessionRequest{Status: SessionStatusCompleted}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if batches := server.snapshot(); !reflect.DeepEqual(batches, []int{1, 2, 1}) {
+		t.Errorf("Expected batches [1 2 1], got %v", batches)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if !server.completed {
+		t.Error("Expected the session to be completed")
+	}
+}
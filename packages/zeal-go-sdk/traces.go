@@ -0,0 +1,503 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultMaxPreviewBytes is the preview limit applied by TraceNodeExecution
+const defaultMaxPreviewBytes = 4096
+
+// TraceDataType classifies the payload of a trace event
+type TraceDataType string
+
+const (
+	TraceDataTypeJSON    TraceDataType = "json"
+	TraceDataTypeText    TraceDataType = "text"
+	TraceDataTypeBinary  TraceDataType = "binary"
+	TraceDataTypeCSV     TraceDataType = "csv"
+	TraceDataTypeParquet TraceDataType = "parquet"
+)
+
+// MIMEType returns the MIME type stored in TraceData.DataType for t
+func (t TraceDataType) MIMEType() string {
+	switch t {
+	case TraceDataTypeText:
+		return "text/plain"
+	case TraceDataTypeBinary:
+		return "application/octet-stream"
+	case TraceDataTypeCSV:
+		return "text/csv"
+	case TraceDataTypeParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "application/json"
+	}
+}
+
+// InferTraceDataType returns Binary for []byte, Text for strings and JSON for
+// everything else
+func InferTraceDataType(value interface{}) TraceDataType {
+	switch value.(type) {
+	case []byte:
+		return TraceDataTypeBinary
+	case string:
+		return TraceDataTypeText
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+		return TraceDataTypeText
+	}
+	return TraceDataTypeJSON
+}
+
+// TruncateTraceData limits the JSON encoding of data.Preview to maxPreviewBytes.
+// An oversized preview is replaced by its truncated JSON text and Truncated is
+// set; FullData is left untouched. A limit of 0 or less disables truncation.
+func TruncateTraceData(data TraceData, maxPreviewBytes int) TraceData {
+	if maxPreviewBytes <= 0 || data.Preview == nil {
+		return data
+	}
+
+	preview, err := json.Marshal(data.Preview)
+	if err != nil || len(preview) <= maxPreviewBytes {
+		return data
+	}
+
+	cut := maxPreviewBytes
+	for cut > 0 && !utf8.RuneStart(preview[cut]) {
+		cut--
+	}
+	data.Preview = string(preview[:cut])
+	data.Truncated = true
+	return data
+}
+
+// ErrInvalidSessionStatus is returned when a session is completed with an
+// unknown status
+var ErrInvalidSessionStatus = errors.New("invalid session status")
+
+// CompleteSessionSucceeded builds a request completing a session successfully
+func CompleteSessionSucceeded(summary SessionSummary) CompleteSessionRequest {
+	return CompleteSessionRequest{Status: SessionStatusCompleted, Summary: &summary}
+}
+
+// CompleteSessionFailed builds a request completing a session with an error
+func CompleteSessionFailed(err SessionError) CompleteSessionRequest {
+	return CompleteSessionRequest{Status: SessionStatusFailed, Error: &err}
+}
+
+// CompleteSessionCancelled builds a request completing a cancelled session
+func CompleteSessionCancelled() CompleteSessionRequest {
+	return CompleteSessionRequest{Status: SessionStatusCancelled}
+}
+
+// ComputeSessionSummary derives a session summary from submitted trace events.
+// Nodes are counted once each: a node with an "output" event is successful and
+// a node with an "error" event is failed.
+func ComputeSessionSummary(events []TraceEvent) SessionSummary {
+	nodes := make(map[string]bool)
+	successful := make(map[string]bool)
+	failed := make(map[string]bool)
+	var summary SessionSummary
+
+	for _, event := range events {
+		nodes[event.NodeID] = true
+		switch event.EventType {
+		case "output":
+			successful[event.NodeID] = true
+		case "error":
+			failed[event.NodeID] = true
+		}
+		if event.Duration != nil {
+			summary.TotalDuration += *event.Duration
+		}
+		summary.TotalDataProcessed += int64(event.Data.Size)
+	}
+
+	summary.TotalNodes = len(nodes)
+	summary.SuccessfulNodes = len(successful)
+	summary.FailedNodes = len(failed)
+	return summary
+}
+
+// FormatDataSize returns TotalDataProcessed as a human-readable size such
+// as "512 KB" or "2.3 MB"
+func (s SessionSummary) FormatDataSize() string {
+	return humanizeBytes(s.TotalDataProcessed)
+}
+
+// humanizeBytes formats n using binary (1024-based) units
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	suffixes := []string{"KB", "MB", "GB", "TB", "PB", "EB"}
+	i := -1
+	for (value >= unit || value <= -unit) && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	formatted := strconv.FormatFloat(value, 'f', 1, 64)
+	formatted = strings.TrimSuffix(formatted, ".0")
+	return formatted + " " + suffixes[i]
+}
+
+// WaterfallNode is the execution window of a single node within a trace.
+// StartMs and EndMs are relative to the earliest event in the trace.
+type WaterfallNode struct {
+	NodeID        string `json:"nodeId"`
+	StartMs       int64  `json:"startMs"`
+	EndMs         int64  `json:"endMs"`
+	DurationMs    int64  `json:"durationMs"`
+	EventType     string `json:"eventType"`
+	DataSizeBytes int    `json:"dataSizeBytes"`
+}
+
+// WaterfallReport is a per-node timing breakdown of a traced execution
+type WaterfallReport struct {
+	Nodes        []WaterfallNode `json:"nodes"`
+	CriticalPath []string        `json:"criticalPath"`
+}
+
+// GenerateWaterfallReport builds a waterfall from trace events. A node's window
+// spans its first event to the end of its last (timestamp plus duration), and
+// its EventType is that of its last event. A node is treated as depending on
+// every node that finished before it started; the critical path is the chain
+// of such nodes with the greatest total duration.
+func GenerateWaterfallReport(events []TraceEvent) *WaterfallReport {
+	report := &WaterfallReport{Nodes: []WaterfallNode{}, CriticalPath: []string{}}
+	if len(events) == 0 {
+		return report
+	}
+
+	origin := events[0].Timestamp
+	for _, event := range events {
+		if event.Timestamp < origin {
+			origin = event.Timestamp
+		}
+	}
+
+	index := make(map[string]int)
+	lastSeen := make(map[string]int64)
+	for _, event := range events {
+		start := event.Timestamp - origin
+		end := start
+		if event.Duration != nil {
+			end += *event.Duration
+		}
+
+		i, ok := index[event.NodeID]
+		if !ok {
+			i = len(report.Nodes)
+			index[event.NodeID] = i
+			report.Nodes = append(report.Nodes, WaterfallNode{NodeID: event.NodeID, StartMs: start, EndMs: end})
+			lastSeen[event.NodeID] = start
+		}
+
+		node := &report.Nodes[i]
+		if start < node.StartMs {
+			node.StartMs = start
+		}
+		if end > node.EndMs {
+			node.EndMs = end
+		}
+		if start >= lastSeen[event.NodeID] {
+			lastSeen[event.NodeID] = start
+			node.EventType = event.EventType
+		}
+		node.DataSizeBytes += event.Data.Size
+	}
+
+	for i := range report.Nodes {
+		report.Nodes[i].DurationMs = report.Nodes[i].EndMs - report.Nodes[i].StartMs
+	}
+	sort.SliceStable(report.Nodes, func(i, j int) bool {
+		return report.Nodes[i].StartMs < report.Nodes[j].StartMs
+	})
+
+	report.CriticalPath = criticalPath(report.Nodes)
+	return report
+}
+
+// criticalPath finds the longest chain of non-overlapping nodes, which must be
+// sorted by StartMs
+func criticalPath(nodes []WaterfallNode) []string {
+	best := make([]int64, len(nodes))
+	prev := make([]int, len(nodes))
+	end := 0
+	for i, node := range nodes {
+		best[i] = node.DurationMs
+		prev[i] = -1
+		for j := 0; j < i; j++ {
+			if nodes[j].EndMs <= node.StartMs && best[j]+node.DurationMs > best[i] {
+				best[i] = best[j] + node.DurationMs
+				prev[i] = j
+			}
+		}
+		if best[i] > best[end] {
+			end = i
+		}
+	}
+
+	var path []string
+	for i := end; i >= 0; i = prev[i] {
+		path = append([]string{nodes[i].NodeID}, path...)
+	}
+	return path
+}
+
+// PrintASCII writes the waterfall as a table with a bar per node, marking
+// nodes on the critical path with '*'
+func (r *WaterfallReport) PrintASCII(w io.Writer) {
+	const barWidth = 40
+
+	var total int64
+	nameWidth := len("NODE")
+	for _, node := range r.Nodes {
+		if node.EndMs > total {
+			total = node.EndMs
+		}
+		if len(node.NodeID) > nameWidth {
+			nameWidth = len(node.NodeID)
+		}
+	}
+	if total == 0 {
+		total = 1
+	}
+
+	critical := make(map[string]bool, len(r.CriticalPath))
+	for _, id := range r.CriticalPath {
+		critical[id] = true
+	}
+
+	fmt.Fprintf(w, "  %-*s %8s %8s  %s\n", nameWidth, "NODE", "START", "DURATION", "TIMELINE")
+	for _, node := range r.Nodes {
+		offset := int(node.StartMs * barWidth / total)
+		length := int(node.DurationMs * barWidth / total)
+		if length == 0 {
+			length = 1
+		}
+		if offset+length > barWidth {
+			offset = barWidth - length
+		}
+
+		marker := " "
+		if critical[node.NodeID] {
+			marker = "*"
+		}
+		bar := strings.Repeat(" ", offset) + strings.Repeat("#", length) + strings.Repeat(" ", barWidth-offset-length)
+		fmt.Fprintf(w, "%s %-*s %6dms %6dms |%s|\n", marker, nameWidth, node.NodeID, node.StartMs, node.DurationMs, bar)
+	}
+}
+
+// SessionTrace is the recorded event stream of a single trace session
+type SessionTrace struct {
+	SessionID string       `json:"sessionId"`
+	Events    []TraceEvent `json:"events"`
+}
+
+// NodeComparison is the change in a node's timing and output between two sessions
+type NodeComparison struct {
+	NodeID               string `json:"nodeId"`
+	DurationDeltaMs      int64  `json:"durationDeltaMs"`
+	OutputSizeDeltaBytes int    `json:"outputSizeDeltaBytes"`
+	Regression           bool   `json:"regression"`
+}
+
+// SessionComparison is the result of CompareTraceSessions
+type SessionComparison struct {
+	NodeComparisons      []NodeComparison `json:"nodeComparisons"`
+	TotalDurationDeltaMs int64            `json:"totalDurationDeltaMs"`
+}
+
+// regressionThreshold is the relative slowdown at which a node is flagged
+const regressionThreshold = 0.10
+
+// CompareTraceSessions diffs session b against baseline a. Deltas are b minus a;
+// a node is a regression when it takes more than 10% longer in b. Nodes are
+// listed in the order they started in a, followed by nodes only present in b.
+func CompareTraceSessions(a, b *SessionTrace) *SessionComparison {
+	before := GenerateWaterfallReport(a.Events)
+	after := GenerateWaterfallReport(b.Events)
+	beforeOutput := outputSizes(a.Events)
+	afterOutput := outputSizes(b.Events)
+
+	afterNodes := make(map[string]WaterfallNode, len(after.Nodes))
+	for _, node := range after.Nodes {
+		afterNodes[node.NodeID] = node
+	}
+
+	comparison := &SessionComparison{
+		NodeComparisons:      []NodeComparison{},
+		TotalDurationDeltaMs: waterfallSpan(after) - waterfallSpan(before),
+	}
+
+	seen := make(map[string]bool, len(before.Nodes))
+	for _, node := range before.Nodes {
+		seen[node.NodeID] = true
+		next := afterNodes[node.NodeID]
+		comparison.NodeComparisons = append(comparison.NodeComparisons, NodeComparison{
+			NodeID:               node.NodeID,
+			DurationDeltaMs:      next.DurationMs - node.DurationMs,
+			OutputSizeDeltaBytes: afterOutput[node.NodeID] - beforeOutput[node.NodeID],
+			Regression:           float64(next.DurationMs) > float64(node.DurationMs)*(1+regressionThreshold),
+		})
+	}
+	for _, node := range after.Nodes {
+		if seen[node.NodeID] {
+			continue
+		}
+		comparison.NodeComparisons = append(comparison.NodeComparisons, NodeComparison{
+			NodeID:               node.NodeID,
+			DurationDeltaMs:      node.DurationMs,
+			OutputSizeDeltaBytes: afterOutput[node.NodeID],
+		})
+	}
+
+	return comparison
+}
+
+// FetchSessionTrace retrieves all events recorded for a trace session,
+// following pagination until the server reports no more events
+func FetchSessionTrace(ctx context.Context, api *TracesAPI, sessionID string) (*SessionTrace, error) {
+	trace := &SessionTrace{SessionID: sessionID, Events: []TraceEvent{}}
+	for {
+		offset := len(trace.Events)
+		page, err := api.GetSessionEvents(ctx, sessionID, &TraceQueryOptions{Offset: &offset})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch trace session %s: %w", sessionID, err)
+		}
+
+		trace.Events = append(trace.Events, page.Events...)
+		if !page.HasMore || len(page.Events) == 0 {
+			return trace, nil
+		}
+	}
+}
+
+// outputSizes sums the data size of each node's output events
+func outputSizes(events []TraceEvent) map[string]int {
+	sizes := make(map[string]int)
+	for _, event := range events {
+		if event.EventType == "output" {
+			sizes[event.NodeID] += event.Data.Size
+		}
+	}
+	return sizes
+}
+
+// waterfallSpan returns the time from the first event to the last node finishing
+func waterfallSpan(report *WaterfallReport) int64 {
+	var span int64
+	for _, node := range report.Nodes {
+		if node.EndMs > span {
+			span = node.EndMs
+		}
+	}
+	return span
+}
+
+// NodeFunc is the signature of a node implementation traced by TracedNodeFunc
+type NodeFunc func(ctx context.Context, input interface{}) (interface{}, error)
+
+// TracedNodeOption configures TracedNodeFunc
+type TracedNodeOption func(*tracedNodeOptions)
+
+type tracedNodeOptions struct {
+	template *NodeTemplate
+}
+
+// WithNodeTemplate applies the template's runtime requirements, such as its
+// timeout, to the traced node
+func WithNodeTemplate(tmpl NodeTemplate) TracedNodeOption {
+	return func(o *tracedNodeOptions) {
+		o.template = &tmpl
+	}
+}
+
+// TracedNodeFunc wraps fn so each call submits an input event and then an
+// output or error event to the trace session. When the node template sets
+// Runtime.Timeout (in seconds), the call is cancelled after that long,
+// traced as an error with code "timeout", and returns
+// context.DeadlineExceeded.
+func (api *TracesAPI) TracedNodeFunc(sessionID, nodeID string, fn NodeFunc, opts ...TracedNodeOption) NodeFunc {
+	options := &tracedNodeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var timeout time.Duration
+	if tmpl := options.template; tmpl != nil && tmpl.Runtime != nil && tmpl.Runtime.Timeout != nil {
+		timeout = time.Duration(*tmpl.Runtime.Timeout) * time.Second
+	}
+
+	return func(ctx context.Context, input interface{}) (interface{}, error) {
+		if err := api.TraceNodeExecution(ctx, sessionID, nodeID, "input", input, nil); err != nil {
+			return nil, fmt.Errorf("failed to trace input: %w", err)
+		}
+
+		runCtx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		type result struct {
+			output interface{}
+			err    error
+		}
+		done := make(chan result, 1)
+		start := time.Now()
+		go func() {
+			output, err := fn(runCtx, input)
+			done <- result{output, err}
+		}()
+
+		select {
+		case r := <-done:
+			duration := time.Since(start)
+			if r.err != nil {
+				api.submitNodeError(ctx, sessionID, nodeID, TraceError{Message: r.err.Error()}, duration)
+				return nil, r.err
+			}
+			if err := api.TraceNodeExecution(ctx, sessionID, nodeID, "output", r.output, &duration); err != nil {
+				return r.output, fmt.Errorf("failed to trace output: %w", err)
+			}
+			return r.output, nil
+		case <-runCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			code := "timeout"
+			api.submitNodeError(ctx, sessionID, nodeID, TraceError{
+				Message: fmt.Sprintf("node %s timed out after %s", nodeID, timeout),
+				Code:    &code,
+			}, time.Since(start))
+			return nil, context.DeadlineExceeded
+		}
+	}
+}
+
+func (api *TracesAPI) submitNodeError(ctx context.Context, sessionID, nodeID string, traceErr TraceError, duration time.Duration) {
+	durationMs := duration.Milliseconds()
+	api.SubmitEvent(ctx, sessionID, TraceEvent{
+		Timestamp: time.Now().UnixMilli(),
+		NodeID:    nodeID,
+		EventType: "error",
+		Data:      TraceData{DataType: "application/json"},
+		Duration:  &durationMs,
+		Error:     &traceErr,
+	})
+}
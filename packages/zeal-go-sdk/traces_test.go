@@ -0,0 +1,287 @@
+package zeal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestComputeSessionSummary(t *testing.T) {
+	d1, d2 := int64(100), int64(250)
+	events := []TraceEvent{
+		{NodeID: "a", EventType: "input", Data: TraceData{Size: 10}},
+		{NodeID: "a", EventType: "output", Data: TraceData{Size: 20}, Duration: &d1},
+		{NodeID: "a", EventType: "output", Data: TraceData{Size: 5}},
+		{NodeID: "b", EventType: "error", Data: TraceData{Size: 1}, Duration: &d2},
+		{NodeID: "c", EventType: "log"},
+	}
+
+	summary := ComputeSessionSummary(events)
+	if summary.TotalNodes != 3 {
+		t.Errorf("Expected 3 total nodes, got %d", summary.TotalNodes)
+	}
+	if summary.SuccessfulNodes != 1 {
+		t.Errorf("Expected 1 successful node, got %d", summary.SuccessfulNodes)
+	}
+	if summary.FailedNodes != 1 {
+		t.Errorf("Expected 1 failed node, got %d", summary.FailedNodes)
+	}
+	if summary.TotalDuration != 350 {
+		t.Errorf("Expected total duration 350, got %d", summary.TotalDuration)
+	}
+	if summary.TotalDataProcessed != 36 {
+		t.Errorf("Expected 36 bytes processed, got %d", summary.TotalDataProcessed)
+	}
+}
+
+func TestGenerateWaterfallReport(t *testing.T) {
+	d := func(ms int64) *int64 { return &ms }
+	events := []TraceEvent{
+		{Timestamp: 1000, NodeID: "fetch", EventType: "input", Data: TraceData{Size: 10}},
+		{Timestamp: 1000, NodeID: "fetch", EventType: "output", Data: TraceData{Size: 90}, Duration: d(100)},
+		{Timestamp: 1100, NodeID: "parse", EventType: "output", Duration: d(50)},
+		{Timestamp: 1100, NodeID: "resize", EventType: "output", Duration: d(300)},
+		{Timestamp: 1400, NodeID: "store", EventType: "error", Duration: d(20)},
+	}
+
+	report := GenerateWaterfallReport(events)
+	if len(report.Nodes) != 4 {
+		t.Fatalf("Expected 4 nodes, got %d", len(report.Nodes))
+	}
+
+	fetch := report.Nodes[0]
+	if fetch.NodeID != "fetch" || fetch.StartMs != 0 || fetch.EndMs != 100 || fetch.DurationMs != 100 {
+		t.Errorf("Unexpected fetch window: %+v", fetch)
+	}
+	if fetch.EventType != "output" || fetch.DataSizeBytes != 100 {
+		t.Errorf("Expected fetch to end with output and 100 bytes, got %+v", fetch)
+	}
+
+	expected := []string{"fetch", "resize", "store"}
+	if strings.Join(report.CriticalPath, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected critical path %v, got %v", expected, report.CriticalPath)
+	}
+
+	var out bytes.Buffer
+	report.PrintASCII(&out)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected header and 4 rows, got:\n%s", out.String())
+	}
+	if !strings.HasPrefix(lines[3], "* resize") || !strings.HasPrefix(lines[2], "  parse") {
+		t.Errorf("Expected critical path markers, got:\n%s", out.String())
+	}
+}
+
+func TestGenerateWaterfallReportEmpty(t *testing.T) {
+	report := GenerateWaterfallReport(nil)
+	if len(report.Nodes) != 0 || len(report.CriticalPath) != 0 {
+		t.Errorf("Expected empty report, got %+v", report)
+	}
+}
+
+func TestCompareTraceSessions(t *testing.T) {
+	d := func(ms int64) *int64 { return &ms }
+	a := &SessionTrace{SessionID: "a", Events: []TraceEvent{
+		{Timestamp: 0, NodeID: "fetch", EventType: "output", Data: TraceData{Size: 100}, Duration: d(100)},
+		{Timestamp: 100, NodeID: "parse", EventType: "output", Data: TraceData{Size: 50}, Duration: d(200)},
+	}}
+	b := &SessionTrace{SessionID: "b", Events: []TraceEvent{
+		{Timestamp: 0, NodeID: "fetch", EventType: "output", Data: TraceData{Size: 100}, Duration: d(105)},
+		{Timestamp: 105, NodeID: "parse", EventType: "output", Data: TraceData{Size: 80}, Duration: d(300)},
+		{Timestamp: 405, NodeID: "notify", EventType: "output", Duration: d(10)},
+	}}
+
+	comparison := CompareTraceSessions(a, b)
+	if comparison.TotalDurationDeltaMs != 115 {
+		t.Errorf("Expected total delta 115ms, got %d", comparison.TotalDurationDeltaMs)
+	}
+	if len(comparison.NodeComparisons) != 3 {
+		t.Fatalf("Expected 3 node comparisons, got %d", len(comparison.NodeComparisons))
+	}
+
+	fetch, parse, notify := comparison.NodeComparisons[0], comparison.NodeComparisons[1], comparison.NodeComparisons[2]
+	if fetch.NodeID != "fetch" || fetch.DurationDeltaMs != 5 || fetch.Regression {
+		t.Errorf("Expected fetch within threshold, got %+v", fetch)
+	}
+	if parse.DurationDeltaMs != 100 || parse.OutputSizeDeltaBytes != 30 || !parse.Regression {
+		t.Errorf("Expected parse regression, got %+v", parse)
+	}
+	if notify.NodeID != "notify" || notify.DurationDeltaMs != 10 || notify.Regression {
+		t.Errorf("Expected new node notify, got %+v", notify)
+	}
+}
+
+func TestTruncateTraceData(t *testing.T) {
+	rows := make([]map[string]interface{}, 100)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": i, "name": "héllo"}
+	}
+	data := TraceData{Size: 4000, DataType: "application/json", Preview: rows, FullData: rows}
+
+	truncated := TruncateTraceData(data, 101)
+	preview, ok := truncated.Preview.(string)
+	if !ok || len(preview) > 101 || !truncated.Truncated {
+		t.Fatalf("Expected preview truncated to 101 bytes, got %T (%v)", truncated.Preview, truncated.Truncated)
+	}
+	if !utf8.ValidString(preview) {
+		t.Error("Expected truncation on a rune boundary")
+	}
+	if full, ok := truncated.FullData.([]map[string]interface{}); !ok || len(full) != 100 {
+		t.Error("Expected FullData to be untouched")
+	}
+
+	small := TraceData{Preview: map[string]interface{}{"ok": true}}
+	if result := TruncateTraceData(small, 4096); result.Truncated {
+		t.Error("Expected small preview to be left alone")
+	}
+	if result := TruncateTraceData(data, 0); result.Truncated {
+		t.Error("Expected a zero limit to disable truncation")
+	}
+}
+
+func TestInferTraceDataType(t *testing.T) {
+	type label string
+	cases := []struct {
+		value interface{}
+		want  TraceDataType
+	}{
+		{map[string]interface{}{"a": 1}, TraceDataTypeJSON},
+		{[]int{1, 2}, TraceDataTypeJSON},
+		{42, TraceDataTypeJSON},
+		{"hello", TraceDataTypeText},
+		{label("x"), TraceDataTypeText},
+		{[]byte{1, 2}, TraceDataTypeBinary},
+	}
+	for _, c := range cases {
+		if got := InferTraceDataType(c.value); got != c.want {
+			t.Errorf("InferTraceDataType(%#v) = %s, want %s", c.value, got, c.want)
+		}
+	}
+
+	if TraceDataTypeText.MIMEType() != "text/plain" || TraceDataTypeJSON.MIMEType() != "application/json" {
+		t.Error("Unexpected MIME types")
+	}
+}
+
+func TestTraceNodeExecutionMetrics(t *testing.T) {
+	var body struct {
+		Events []TraceEvent `json:"events"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"success":true,"eventsProcessed":1}`))
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	client, _ := NewClient(config)
+
+	duration := 1500 * time.Millisecond
+	if err := client.Traces().TraceNodeExecution(context.Background(), "s1", "n1", "output", map[string]int{"a": 1}, &duration); err != nil {
+		t.Fatalf("TraceNodeExecution failed: %v", err)
+	}
+	if len(body.Events) != 1 {
+		t.Fatalf("Expected one event, got %d", len(body.Events))
+	}
+	custom, _ := body.Events[0].Metadata["custom"].(map[string]interface{})
+	metrics, _ := custom["metrics"].(map[string]interface{})
+	if metrics["duration_ms"] != 1500.0 || metrics["data_size_bytes"] != 7.0 {
+		t.Errorf("Unexpected metrics %v", metrics)
+	}
+}
+
+func TestTraceEventDataDecodeData(t *testing.T) {
+	event := CreateTraceEventData("wf-1", "s1", "n1", TracePayload{
+		InputData: map[string]interface{}{"q": "x"},
+		Metrics:   map[string]float64{"duration_ms": 12},
+		Tags:      map[string]string{"env": "prod"},
+	}, nil)
+
+	payload, err := event.DecodeData()
+	if err != nil {
+		t.Fatalf("DecodeData failed: %v", err)
+	}
+	if payload.Metrics["duration_ms"] != 12 || payload.Tags["env"] != "prod" || payload.OutputData != nil {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+	if input, _ := payload.InputData.(map[string]interface{}); input["q"] != "x" {
+		t.Errorf("Unexpected input data %v", payload.InputData)
+	}
+}
+
+func TestCompleteSessionConstructors(t *testing.T) {
+	req := CompleteSessionSucceeded(SessionSummary{TotalNodes: 2})
+	if req.Status != SessionStatusCompleted || req.Summary == nil || req.Summary.TotalNodes != 2 {
+		t.Errorf("Unexpected succeeded request: %+v", req)
+	}
+	req = CompleteSessionFailed(SessionError{Message: "boom"})
+	if req.Status != SessionStatusFailed || req.Error == nil || req.Error.Message != "boom" {
+		t.Errorf("Unexpected failed request: %+v", req)
+	}
+	if req = CompleteSessionCancelled(); req.Status != SessionStatusCancelled {
+		t.Errorf("Expected cancelled status, got %q", req.Status)
+	}
+}
+
+func TestCompleteSessionRejectsInvalidStatus(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	_, err = client.Traces().CompleteSession(context.Background(), "s1", CompleteSessionRequest{Status: "done"})
+	if !errors.Is(err, ErrInvalidSessionStatus) {
+		t.Errorf("Expected ErrInvalidSessionStatus, got %v", err)
+	}
+	if called {
+		t.Error("Expected no request for an invalid status")
+	}
+}
+
+func TestCompleteSessionSendsTimedOutAsError(t *testing.T) {
+	var received CompleteSessionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"success":true,"sessionId":"s1"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	if _, err := client.Traces().CompleteSession(context.Background(), "s1", CompleteSessionRequest{Status: SessionStatusTimedOut}); err != nil {
+		t.Fatalf("CompleteSession failed: %v", err)
+	}
+	if received.Status != SessionStatusFailed || received.Error == nil || received.Error.Message != "session timed out" {
+		t.Errorf("Expected timed out session sent as error, got %+v", received)
+	}
+}
+
+func TestSessionSummaryFormatDataSize(t *testing.T) {
+	cases := map[int64]string{
+		0:                      "0 B",
+		512:                    "512 B",
+		1024:                   "1 KB",
+		512 * 1024:             "512 KB",
+		2411724:                "2.3 MB",
+		3 * 1024 * 1024 * 1024: "3 GB",
+	}
+	for size, want := range cases {
+		summary := SessionSummary{TotalDataProcessed: size}
+		if got := summary.FormatDataSize(); got != want {
+			t.Errorf("FormatDataSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package zeal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Transport executes a Zeal API call and decodes the response into result.
+// HTTPTransport is the only implementation shipped with the SDK; there is no
+// gRPC transport because the server defines no proto service for the ZIP API.
+// Other implementations can be installed with Client.WithTransport.
+type Transport interface {
+	Do(ctx context.Context, method, path string, body, result interface{}) error
+}
+
+// HTTPTransport is the default Transport, speaking JSON over the ZIP REST API
+type HTTPTransport struct {
+	config     *ClientConfig
+	httpClient *http.Client
+}
+
+// NewHTTPTransport creates a REST transport for the given configuration
+func NewHTTPTransport(config ClientConfig) *HTTPTransport {
+	return &HTTPTransport{
+		config:     &config,
+		httpClient: &http.Client{Timeout: config.DefaultTimeout},
+	}
+}
+
+// StreamingTransport is implemented by transports that can hand the raw
+// response body to the caller instead of decoding it in one piece
+type StreamingTransport interface {
+	Transport
+	Stream(ctx context.Context, method, path string, body interface{}, fn func(io.Reader) error) error
+}
+
+// Do sends the request, retrying on network and 5xx errors
+func (t *HTTPTransport) Do(ctx context.Context, method, path string, body, result interface{}) error {
+	resp, err := t.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Decode response if result is provided
+	if result != nil {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		if err := t.codec().Unmarshal(data, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// codec returns the configured JSONCodec, or DefaultJSONCodec
+func (t *HTTPTransport) codec() JSONCodec {
+	if t.config.JSONCodec != nil {
+		return t.config.JSONCodec
+	}
+	return DefaultJSONCodec{}
+}
+
+// Stream sends the request and passes the open response body to fn. The body
+// is drained afterwards, even if fn fails, so the connection can be reused.
+func (t *HTTPTransport) Stream(ctx context.Context, method, path string, body interface{}, fn func(io.Reader) error) error {
+	resp, err := t.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	defer io.Copy(io.Discard, resp.Body)
+
+	return fn(resp.Body)
+}
+
+// send executes the request with retries and returns the successful response
+func (t *HTTPTransport) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	url := strings.TrimSuffix(t.config.BaseURL, "/") + path
+
+	var jsonData []byte
+	compressed := false
+	if body != nil {
+		var err error
+		jsonData, err = t.codec().Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+
+		threshold := t.config.CompressionThresholdBytes
+		if threshold <= 0 {
+			threshold = defaultCompressionThreshold
+		}
+		if requestOptionsFromContext(ctx).compress && len(jsonData) > threshold {
+			if jsonData, err = gzipBody(jsonData); err != nil {
+				return nil, err
+			}
+			compressed = true
+		}
+	}
+
+	// Add auth token if provided
+	var authorization string
+	if t.config.TokenProvider != nil {
+		token, err := t.config.TokenProvider.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain auth token: %w", err)
+		}
+		authorization = "Bearer " + token
+	} else if t.config.AuthToken != "" {
+		authorization = "Bearer " + t.config.AuthToken.Reveal()
+	}
+
+	// Execute request with retries. Each attempt gets a fresh request so the
+	// body is sent in full every time.
+	var resp *http.Response
+	var lastErr error
+
+	for attempt := 0; attempt <= t.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			// Wait before retry
+			time.Sleep(time.Duration(t.config.RetryBackoffMs) * time.Millisecond)
+		}
+
+		req, err := t.newRequest(ctx, method, url, jsonData, compressed, authorization)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, lastErr = t.httpClient.Do(req)
+		if lastErr == nil && (resp.StatusCode < 500 || attempt == t.config.MaxRetries) {
+			// Success, client error (don't retry client errors) or out of retries
+			break
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("request failed after %d retries: %w", t.config.MaxRetries, lastErr)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+	}
+
+	return resp, nil
+}
+
+// newRequest builds a single attempt of an API request
+func (t *HTTPTransport) newRequest(ctx context.Context, method, url string, jsonData []byte, compressed bool, authorization string) (*http.Request, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	req.Header.Set("User-Agent", t.config.UserAgent)
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return req, nil
+}
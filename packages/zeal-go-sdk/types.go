@@ -1,35 +1,81 @@
 package zeal
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 )
 
 // Core configuration
 type ClientConfig struct {
-	BaseURL           string        `json:"baseUrl"`
-	AuthToken         string        `json:"authToken"`
-	DefaultTimeout    time.Duration `json:"defaultTimeout"`
-	VerifyTLS         bool          `json:"verifyTls"`
-	UserAgent         string        `json:"userAgent"`
-	MaxRetries        int           `json:"maxRetries"`
-	RetryBackoffMs    int           `json:"retryBackoffMs"`
-	EnableCompression bool          `json:"enableCompression"`
+	BaseURL                   string        `json:"baseUrl"`
+	AuthToken                 SecretString  `json:"authToken"`
+	DefaultTimeout            time.Duration `json:"defaultTimeout"`
+	VerifyTLS                 bool          `json:"verifyTls"`
+	UserAgent                 string        `json:"userAgent"`
+	MaxRetries                int           `json:"maxRetries"`
+	RetryBackoffMs            int           `json:"retryBackoffMs"`
+	EnableCompression         bool          `json:"enableCompression"`
+	TokenProvider             TokenProvider `json:"-"`                         // overrides AuthToken when set
+	CompressionThresholdBytes int           `json:"compressionThresholdBytes"` // body size above which CompressRequest applies
+	JSONCodec                 JSONCodec     `json:"-"`                         // encodes requests and decodes responses; DefaultJSONCodec when nil
 }
 
 // Default configuration
 func DefaultClientConfig() ClientConfig {
 	return ClientConfig{
-		BaseURL:           "http://localhost:3000",
-		AuthToken:         "",
-		DefaultTimeout:    30 * time.Second,
-		VerifyTLS:         true,
-		UserAgent:         "zeal-go-sdk/1.0.0",
-		MaxRetries:        3,
-		RetryBackoffMs:    1000,
-		EnableCompression: true,
+		BaseURL:                   "http://localhost:3000",
+		AuthToken:                 "",
+		DefaultTimeout:            30 * time.Second,
+		VerifyTLS:                 true,
+		UserAgent:                 FormatUserAgent(SDKVersion, ""),
+		MaxRetries:                3,
+		RetryBackoffMs:            1000,
+		EnableCompression:         true,
+		CompressionThresholdBytes: defaultCompressionThreshold,
 	}
 }
 
+// WithUserAgent returns a copy of the config whose User-Agent carries extra,
+// such as an application name and version, after the SDK and runtime info
+func (c ClientConfig) WithUserAgent(extra string) ClientConfig {
+	c.UserAgent = FormatUserAgent(SDKVersion, extra)
+	return c
+}
+
+// MainGraphID is the ID of a workflow's root graph
+const MainGraphID GraphID = "main"
+
+// GraphID identifies a graph within a workflow. The zero value refers to the
+// main graph.
+type GraphID string
+
+// ParseGraphID converts a string to a GraphID, treating blank input as the main graph
+func ParseGraphID(s string) GraphID {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return MainGraphID
+	}
+	return GraphID(s)
+}
+
+// IsMain reports whether the ID refers to the main graph
+func (g GraphID) IsMain() bool {
+	return g == "" || g == MainGraphID
+}
+
+// Ptr returns the ID as a *string for the API methods taking an optional
+// graph ID. The zero value yields "main".
+func (g GraphID) Ptr() *string {
+	s := string(g)
+	if s == "" {
+		s = string(MainGraphID)
+	}
+	return &s
+}
+
 // Position represents x,y coordinates
 type Position struct {
 	X float64 `json:"x"`
@@ -62,33 +108,201 @@ type CreateWorkflowResponse struct {
 	WorkflowID string                 `json:"workflowId"`
 	Name       string                 `json:"name"`
 	Version    int                    `json:"version"`
-	GraphID    string                 `json:"graphId"`
+	GraphID    GraphID                `json:"graphId"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 }
 
+type UpdateWorkflowMetadataRequest struct {
+	Name        *string                `json:"name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type UpdateWorkflowMetadataResponse struct {
+	Success   bool      `json:"success"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
 type ListWorkflowsParams struct {
 	Limit  *int `json:"limit,omitempty"`
 	Offset *int `json:"offset,omitempty"`
 }
 
+// WorkflowSearchQuery filters workflows in SearchWorkflows. Zero values
+// match everything.
+type WorkflowSearchQuery struct {
+	TextQuery      string                 `json:"textQuery,omitempty"`
+	MetadataFilter map[string]interface{} `json:"metadataFilter,omitempty"`
+	IsActive       *bool                  `json:"isActive,omitempty"` // needs the server search endpoint
+	CreatedAfter   *time.Time             `json:"createdAfter,omitempty"`
+	CreatedBefore  *time.Time             `json:"createdBefore,omitempty"`
+	Limit          int                    `json:"limit,omitempty"`
+	Offset         int                    `json:"offset,omitempty"`
+}
+
+// Matches reports whether workflow satisfies every filter in the query.
+// TextQuery is matched case-insensitively against the name and description.
+func (q *WorkflowSearchQuery) Matches(workflow *WorkflowSummary) bool {
+	if q.TextQuery != "" {
+		text := strings.ToLower(q.TextQuery)
+		if !strings.Contains(strings.ToLower(workflow.Name), text) &&
+			!strings.Contains(strings.ToLower(workflow.Description), text) {
+			return false
+		}
+	}
+	for key, want := range q.MetadataFilter {
+		got, ok := workflow.Metadata[key]
+		if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	if q.IsActive != nil && workflow.IsActive != *q.IsActive {
+		return false
+	}
+	if q.CreatedAfter != nil && !workflow.CreatedAt.After(*q.CreatedAfter) {
+		return false
+	}
+	if q.CreatedBefore != nil && !workflow.CreatedAt.Before(*q.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// WorkflowSummary is a workflow entry returned by ListWorkflows
+type WorkflowSummary struct {
+	WorkflowID  string                 `json:"workflowId"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Version     int                    `json:"version"`
+	GraphID     GraphID                `json:"graphId"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+	IsActive    bool                   `json:"isActive"`
+}
+
 type ListWorkflowsResponse struct {
+	Workflows []WorkflowSummary `json:"workflows"`
+	Total     int               `json:"total"`
+	Limit     int               `json:"limit"`
+	Offset    int               `json:"offset"`
+}
+
+// FindByName returns the first listed workflow named name
+func (r *ListWorkflowsResponse) FindByName(name string) (*WorkflowSummary, bool) {
+	for i := range r.Workflows {
+		if r.Workflows[i].Name == name {
+			return &r.Workflows[i], true
+		}
+	}
+	return nil, false
+}
+
+// ListWorkflowsResponseLegacy is the untyped ListWorkflowsResponse of earlier
+// SDK versions, for callers decoding the list response themselves while
+// migrating to WorkflowSummary
+type ListWorkflowsResponseLegacy struct {
 	Workflows []interface{} `json:"workflows"`
 	Total     int           `json:"total"`
 	Limit     int           `json:"limit"`
 	Offset    int           `json:"offset"`
 }
 
+// ExecutionHistoryOptions filters and pages GetExecutionHistory results
+type ExecutionHistoryOptions struct {
+	Limit    int
+	Offset   int
+	Status   *string
+	From     *time.Time
+	To       *time.Time
+	SortDesc bool // newest first
+}
+
+// ExecutionSummaryRecord is one past execution of a workflow
+type ExecutionSummaryRecord struct {
+	ExecutionID   string    `json:"executionId"`
+	SessionID     string    `json:"sessionId"`
+	Status        string    `json:"status"`
+	StartedAt     time.Time `json:"startedAt"`
+	Duration      int64     `json:"duration"` // milliseconds
+	NodesExecuted int       `json:"nodesExecuted"`
+}
+
+type ExecutionHistoryResponse struct {
+	Executions []ExecutionSummaryRecord `json:"executions"`
+	Total      int                      `json:"total"`
+	Limit      int                      `json:"limit"`
+	Offset     int                      `json:"offset"`
+}
+
+// NodeHistoryOptions filters and pages GetNodeExecutionHistory results
+type NodeHistoryOptions struct {
+	Limit            int
+	Offset           int
+	Status           *string
+	From             *time.Time
+	To               *time.Time
+	IncludeTraceData bool // embed each execution's trace events for the node
+}
+
+// NodeExecutionRecord is one past execution through a node. Sizes are in
+// bytes and Duration in milliseconds; they are nil when not recorded.
+type NodeExecutionRecord struct {
+	ExecutionID string       `json:"executionId"`
+	SessionID   string       `json:"sessionId"`
+	Status      string       `json:"status"`
+	StartedAt   time.Time    `json:"startedAt"`
+	Duration    *int64       `json:"duration,omitempty"`
+	InputSize   *int64       `json:"inputSize,omitempty"`
+	OutputSize  *int64       `json:"outputSize,omitempty"`
+	ErrorCode   *string      `json:"errorCode,omitempty"`
+	TraceEvents []TraceEvent `json:"traceEvents,omitempty"` // set with IncludeTraceData
+}
+
+type NodeHistoryResponse struct {
+	Executions []NodeExecutionRecord `json:"executions"`
+	Total      int                   `json:"total"`
+	Limit      int                   `json:"limit"`
+	Offset     int                   `json:"offset"`
+}
+
 type WorkflowState struct {
 	WorkflowID  string      `json:"workflowId"`
-	GraphID     string      `json:"graphId"`
+	GraphID     GraphID     `json:"graphId"`
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
 	Version     int         `json:"version"`
 	State       interface{} `json:"state"`
 	Metadata    interface{} `json:"metadata"`
+
+	stateJSON []byte // cached encoding of State, see DecodeState
+}
+
+// WorkflowStateData is the untyped graph data held in WorkflowState.State
+type WorkflowStateData struct {
+	Nodes       []map[string]interface{} `json:"nodes"`
+	Connections []map[string]interface{} `json:"connections"`
+	Groups      []map[string]interface{} `json:"groups"`
+}
+
+// WorkflowGraph is the node, connection and group data held in WorkflowState.State
+type WorkflowGraph struct {
+	Nodes       []NodeDetail       `json:"nodes"`
+	Connections []ConnectionDetail `json:"connections"`
+	Groups      []GroupDetail      `json:"groups"`
 }
 
 // Node types
+type NodeDetail struct {
+	ID           string                 `json:"id"`
+	TemplateID   string                 `json:"templateId,omitempty"`
+	InstanceName *string                `json:"instanceName,omitempty"`
+	Position     Position               `json:"position"`
+	Properties   map[string]interface{} `json:"properties,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+}
+
 type AddNodeRequest struct {
 	WorkflowID   string                 `json:"workflowId"`
 	GraphID      *string                `json:"graphId,omitempty"`
@@ -97,22 +311,27 @@ type AddNodeRequest struct {
 	Properties   map[string]interface{} `json:"properties,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 	InstanceName *string                `json:"instanceName,omitempty"`
+	// ExpectedVersion, when set, makes the server reject the change with a
+	// VersionConflictError if the workflow's version differs
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
 }
 
 type AddNodeResponse struct {
-	NodeID string      `json:"nodeId"`
-	Node   interface{} `json:"node"`
+	NodeID string     `json:"nodeId"`
+	Node   NodeDetail `json:"node"`
 }
 
 type UpdateNodeRequest struct {
-	WorkflowID string                 `json:"workflowId"`
-	GraphID    *string                `json:"graphId,omitempty"`
-	Properties map[string]interface{} `json:"properties,omitempty"`
-	Position   *Position              `json:"position,omitempty"`
+	WorkflowID   string                 `json:"workflowId"`
+	GraphID      *string                `json:"graphId,omitempty"`
+	Properties   map[string]interface{} `json:"properties,omitempty"`
+	Position     *Position              `json:"position,omitempty"`
+	InstanceName *string                `json:"instanceName,omitempty"`
 }
 
 type UpdateNodeResponse struct {
-	Success bool `json:"success"`
+	Success bool        `json:"success"`
+	Node    *NodeDetail `json:"node,omitempty"` // nil when the server omits the updated node
 }
 
 type DeleteNodeResponse struct {
@@ -126,11 +345,35 @@ type ConnectNodesRequest struct {
 	GraphID    *string  `json:"graphId,omitempty"`
 	Source     NodePort `json:"source"`
 	Target     NodePort `json:"target"`
+	// ExpectedVersion, when set, makes the server reject the change with a
+	// VersionConflictError if the workflow's version differs
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
+}
+
+type ConnectionDetail struct {
+	ID       string                 `json:"id"`
+	Source   NodePort               `json:"source"`
+	Target   NodePort               `json:"target"`
+	State    *string                `json:"state,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
 type ConnectionResponse struct {
-	ConnectionID string      `json:"connectionId"`
-	Connection   interface{} `json:"connection"`
+	ConnectionID string           `json:"connectionId"`
+	Connection   ConnectionDetail `json:"connection"`
+}
+
+// BulkConnectionResponse is returned by BatchConnectNodes. Results are in
+// request order; a failed connection does not stop the others.
+type BulkConnectionResponse struct {
+	Results []BulkConnectionResult `json:"results"`
+}
+
+// BulkConnectionResult is the outcome of one connection in a batch
+type BulkConnectionResult struct {
+	ConnectionID string  `json:"connectionId,omitempty"`
+	Success      bool    `json:"success"`
+	Error        *string `json:"error,omitempty"`
 }
 
 type RemoveConnectionRequest struct {
@@ -157,7 +400,7 @@ type CreateGroupRequest struct {
 type CreateGroupResponse struct {
 	Success bool        `json:"success"`
 	GroupID string      `json:"groupId"`
-	Group   interface{} `json:"group"`
+	Group   GroupDetail `json:"group"`
 }
 
 type UpdateGroupRequest struct {
@@ -171,8 +414,8 @@ type UpdateGroupRequest struct {
 }
 
 type UpdateGroupResponse struct {
-	Success bool        `json:"success"`
-	Group   interface{} `json:"group"`
+	Success bool         `json:"success"`
+	Group   *GroupDetail `json:"group,omitempty"` // nil when the server omits the updated group
 }
 
 type RemoveGroupRequest struct {
@@ -186,6 +429,31 @@ type RemoveGroupResponse struct {
 	Message string `json:"message"`
 }
 
+// GroupDetail describes a node group and its members
+type GroupDetail struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Color         *string   `json:"color,omitempty"`
+	Description   *string   `json:"description,omitempty"`
+	MemberNodeIDs []string  `json:"nodeIds"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// ContainsNode reports whether the node is a member of the group
+func (g *GroupDetail) ContainsNode(nodeID string) bool {
+	for _, id := range g.MemberNodeIDs {
+		if id == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+type ListGroupsResponse struct {
+	Groups []GroupDetail `json:"groups"`
+	Total  int           `json:"total"`
+}
+
 // === Template Types ===
 
 // DisplayComponent for Web Component-based node rendering
@@ -221,23 +489,25 @@ type NodeTemplate struct {
 	Subcategory  *string                       `json:"subcategory,omitempty"`
 	Description  string                        `json:"description"`
 	Icon         string                        `json:"icon"`
-	Variant      *string                       `json:"variant,omitempty"`
-	Shape        *string                       `json:"shape,omitempty"`
-	Size         *string                       `json:"size,omitempty"`
+	Variant      *NodeVariant                  `json:"variant,omitempty"`
+	Shape        *NodeShape                    `json:"shape,omitempty"`
+	Size         *NodeSize                     `json:"size,omitempty"`
 	Ports        []Port                        `json:"ports"`
 	Properties   map[string]PropertyDefinition `json:"properties,omitempty"`
 	Runtime      *RuntimeRequirements          `json:"runtime,omitempty"`
 	Display      *DisplayComponent             `json:"display,omitempty"`
+	Tags         []string                      `json:"tags,omitempty"`
+	DependsOn    []string                      `json:"dependsOn,omitempty"`
 }
 
 type Port struct {
-	ID       string  `json:"id"`
-	Label    string  `json:"label"`
-	Type     string  `json:"type"`
-	Position string  `json:"position"`
-	DataType *string `json:"dataType,omitempty"`
-	Required *bool   `json:"required,omitempty"`
-	Multiple *bool   `json:"multiple,omitempty"`
+	ID       string       `json:"id"`
+	Label    string       `json:"label"`
+	Type     string       `json:"type"`
+	Position PortPosition `json:"position"`
+	DataType *string      `json:"dataType,omitempty"`
+	Required *bool        `json:"required,omitempty"`
+	Multiple *bool        `json:"multiple,omitempty"`
 }
 
 type PropertyDefinition struct {
@@ -245,10 +515,17 @@ type PropertyDefinition struct {
 	Label        *string                `json:"label,omitempty"`
 	Description  *string                `json:"description,omitempty"`
 	DefaultValue interface{}            `json:"defaultValue,omitempty"`
-	Options      []interface{}          `json:"options,omitempty"`
+	Options      []PropertyOption       `json:"options,omitempty"`
 	Validation   *PropertyValidation    `json:"validation,omitempty"`
 }
 
+// PropertyOption is a selectable label/value pair for select properties
+type PropertyOption struct {
+	Label    string      `json:"label"`
+	Value    interface{} `json:"value"`
+	Disabled *bool       `json:"disabled,omitempty"`
+}
+
 type PropertyValidation struct {
 	Required   *bool    `json:"required,omitempty"`
 	Min        *float64 `json:"min,omitempty"`
@@ -344,6 +621,16 @@ type ListTemplatesResponse struct {
 	Total     int            `json:"total"`
 }
 
+// TemplateSearchQuery filters templates returned by TemplatesAPI.SearchTemplates
+type TemplateSearchQuery struct {
+	CategoryFilter    string   `json:"category,omitempty"`
+	TextSearch        string   `json:"q,omitempty"`
+	SubcategoryFilter *string  `json:"subcategory,omitempty"`
+	HasGPU            *bool    `json:"gpu,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+	MaxResults        int      `json:"limit,omitempty"`
+}
+
 type UpdateTemplateResponse struct {
 	Success  bool         `json:"success"`
 	Template NodeTemplate `json:"template"`
@@ -354,6 +641,19 @@ type DeleteTemplateResponse struct {
 	Message string `json:"message"`
 }
 
+// TemplateWorkflowRef is a workflow that uses a template
+type TemplateWorkflowRef struct {
+	WorkflowID   string    `json:"workflowId"`
+	WorkflowName string    `json:"workflowName"`
+	NodeCount    int       `json:"nodeCount"`
+	LastSeenAt   time.Time `json:"lastSeenAt"`
+}
+
+type TemplateUsageResponse struct {
+	WorkflowRefs   []TemplateWorkflowRef `json:"workflowRefs"`
+	TotalWorkflows int                   `json:"totalWorkflows"`
+}
+
 // === Trace Types ===
 
 type CreateTraceSessionRequest struct {
@@ -382,10 +682,11 @@ type TraceEvent struct {
 }
 
 type TraceData struct {
-	Size     int         `json:"size"`
-	DataType string      `json:"dataType"`
-	Preview  interface{} `json:"preview,omitempty"`
-	FullData interface{} `json:"fullData,omitempty"`
+	Size      int         `json:"size"`
+	DataType  string      `json:"dataType"`
+	Preview   interface{} `json:"preview,omitempty"`
+	FullData  interface{} `json:"fullData,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"` // Preview was cut to a size limit
 }
 
 type TraceError struct {
@@ -399,8 +700,45 @@ type SubmitEventsResponse struct {
 	EventsProcessed  int  `json:"eventsProcessed"`
 }
 
+// TraceQueryOptions filters and pages the events returned by GetSessionEvents
+type TraceQueryOptions struct {
+	NodeID    *string
+	EventType *string
+	From      *time.Time
+	To        *time.Time
+	Limit     *int
+	Offset    *int
+}
+
+type TraceEventsResponse struct {
+	Events  []TraceEvent `json:"events"`
+	Total   int          `json:"total"`
+	HasMore bool         `json:"hasMore"`
+}
+
+// SessionStatus is the final status of a trace session
+type SessionStatus string
+
+const (
+	SessionStatusCompleted SessionStatus = "success"
+	SessionStatusFailed    SessionStatus = "error"
+	SessionStatusCancelled SessionStatus = "cancelled"
+	// SessionStatusTimedOut is sent to the server as SessionStatusFailed,
+	// since the server does not accept it
+	SessionStatusTimedOut SessionStatus = "timeout"
+)
+
+// IsValid reports whether s is one of the known session statuses
+func (s SessionStatus) IsValid() bool {
+	switch s {
+	case SessionStatusCompleted, SessionStatusFailed, SessionStatusCancelled, SessionStatusTimedOut:
+		return true
+	}
+	return false
+}
+
 type CompleteSessionRequest struct {
-	Status  string          `json:"status"`
+	Status  SessionStatus   `json:"status"`
 	Summary *SessionSummary `json:"summary,omitempty"`
 	Error   *SessionError   `json:"error,omitempty"`
 }
@@ -428,24 +766,82 @@ type CompleteSessionResponse struct {
 // === Webhook Types ===
 
 type WebhookSubscription struct {
-	ID            string            `json:"id"`
-	URL           string            `json:"url"`
-	Events        []string          `json:"events"`
-	Headers       map[string]string `json:"headers,omitempty"`
-	Secret        *string           `json:"secret,omitempty"`
-	MaxRetries    int               `json:"maxRetries"`
-	RetryInterval int               `json:"retryInterval"`
-	IsActive      bool              `json:"isActive"`
-	CreatedAt     time.Time         `json:"createdAt"`
+	ID            string              `json:"id"`
+	URL           string              `json:"url"`
+	Events        []string            `json:"events"`
+	Headers       map[string]string   `json:"headers,omitempty"`
+	Secret        *string             `json:"secret,omitempty"`
+	MaxRetries    int                 `json:"maxRetries"`
+	RetryInterval int                 `json:"retryInterval"`
+	IsActive      bool                `json:"isActive"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	ExpiresAt     *time.Time          `json:"expiresAt,omitempty"`
+	BatchConfig   *WebhookBatchConfig `json:"batchConfig,omitempty"`
+}
+
+// IsExpiring reports whether the subscription expires within threshold.
+// Subscriptions without an expiry never expire.
+func (s *WebhookSubscription) IsExpiring(threshold time.Duration) bool {
+	return s.ExpiresAt != nil && time.Until(*s.ExpiresAt) < threshold
 }
 
 type CreateWebhookRequest struct {
-	URL           string            `json:"url"`
-	Events        []string          `json:"events"`
-	Headers       map[string]string `json:"headers,omitempty"`
-	Secret        *string           `json:"secret,omitempty"`
-	MaxRetries    *int              `json:"maxRetries,omitempty"`
-	RetryInterval *int              `json:"retryInterval,omitempty"`
+	Namespace     string              `json:"namespace,omitempty"`
+	URL           string              `json:"url"`
+	Events        []string            `json:"events"`
+	Headers       map[string]string   `json:"headers,omitempty"`
+	Metadata      map[string]string   `json:"metadata,omitempty"`
+	Secret        *string             `json:"secret,omitempty"`
+	MaxRetries    *int                `json:"maxRetries,omitempty"`
+	RetryInterval *int                `json:"retryInterval,omitempty"`
+	BatchConfig   *WebhookBatchConfig `json:"batchConfig,omitempty"`
+}
+
+// WebhookBatchConfig asks the server to accumulate events and deliver them
+// together in WebhookDelivery.Events, flushing when MaxBatchSize events are
+// pending or MaxBatchDelayMs has passed since the first one.
+type WebhookBatchConfig struct {
+	MaxBatchSize    int `json:"maxBatchSize"`
+	MaxBatchDelayMs int `json:"maxBatchDelayMs"`
+}
+
+// defaultWebhookRetryPolicy holds the retry settings applied by
+// CreateWebhookRequest.WithDefaults
+var defaultWebhookRetryPolicy = struct {
+	sync.RWMutex
+	maxRetries    int
+	retryInterval int
+}{maxRetries: 3, retryInterval: 60}
+
+// SetDefaultWebhookRetryPolicy changes the retry settings WithDefaults fills
+// in for webhooks created without them. The initial defaults are 3 retries
+// 60 seconds apart.
+func SetDefaultWebhookRetryPolicy(maxRetries, intervalSecs int) {
+	defaultWebhookRetryPolicy.Lock()
+	defer defaultWebhookRetryPolicy.Unlock()
+	defaultWebhookRetryPolicy.maxRetries = maxRetries
+	defaultWebhookRetryPolicy.retryInterval = intervalSecs
+}
+
+// WithDefaults returns a copy of the request with an unset Namespace set to
+// "default" and unset MaxRetries and RetryInterval filled in from the
+// default webhook retry policy
+func (r CreateWebhookRequest) WithDefaults() CreateWebhookRequest {
+	defaultWebhookRetryPolicy.RLock()
+	defer defaultWebhookRetryPolicy.RUnlock()
+
+	if r.Namespace == "" {
+		r.Namespace = "default"
+	}
+	if r.MaxRetries == nil {
+		maxRetries := defaultWebhookRetryPolicy.maxRetries
+		r.MaxRetries = &maxRetries
+	}
+	if r.RetryInterval == nil {
+		retryInterval := defaultWebhookRetryPolicy.retryInterval
+		r.RetryInterval = &retryInterval
+	}
+	return r
 }
 
 type CreateWebhookResponse struct {
@@ -458,14 +854,61 @@ type ListWebhooksResponse struct {
 	Total         int                   `json:"total"`
 }
 
+// FindByURL returns the first listed subscription for url
+func (r *ListWebhooksResponse) FindByURL(url string) (*WebhookSubscription, bool) {
+	for i := range r.Subscriptions {
+		if r.Subscriptions[i].URL == url {
+			return &r.Subscriptions[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindByID returns the listed subscription with the given ID
+func (r *ListWebhooksResponse) FindByID(id string) (*WebhookSubscription, bool) {
+	for i := range r.Subscriptions {
+		if r.Subscriptions[i].ID == id {
+			return &r.Subscriptions[i], true
+		}
+	}
+	return nil, false
+}
+
+// FilterByEvent returns the subscriptions whose event filter, including "*"
+// and "prefix.*" globs, matches eventType
+func (r *ListWebhooksResponse) FilterByEvent(eventType string) []WebhookSubscription {
+	var matched []WebhookSubscription
+	for _, sub := range r.Subscriptions {
+		for _, event := range sub.Events {
+			if eventFilterMatches(event, eventType) {
+				matched = append(matched, sub)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// ActiveSubscriptions returns the subscriptions that are active
+func (r *ListWebhooksResponse) ActiveSubscriptions() []WebhookSubscription {
+	var active []WebhookSubscription
+	for _, sub := range r.Subscriptions {
+		if sub.IsActive {
+			active = append(active, sub)
+		}
+	}
+	return active
+}
+
 type UpdateWebhookRequest struct {
-	URL           *string            `json:"url,omitempty"`
-	Events        []string           `json:"events,omitempty"`
-	Headers       *map[string]string `json:"headers,omitempty"`
-	Secret        *string            `json:"secret,omitempty"`
-	MaxRetries    *int               `json:"maxRetries,omitempty"`
-	RetryInterval *int               `json:"retryInterval,omitempty"`
-	IsActive      *bool              `json:"isActive,omitempty"`
+	URL           *string             `json:"url,omitempty"`
+	Events        []string            `json:"events,omitempty"`
+	Headers       *map[string]string  `json:"headers,omitempty"`
+	Secret        *string             `json:"secret,omitempty"`
+	MaxRetries    *int                `json:"maxRetries,omitempty"`
+	RetryInterval *int                `json:"retryInterval,omitempty"`
+	IsActive      *bool               `json:"isActive,omitempty"`
+	BatchConfig   *WebhookBatchConfig `json:"batchConfig,omitempty"`
 }
 
 type UpdateWebhookResponse struct {
@@ -479,8 +922,91 @@ type DeleteWebhookResponse struct {
 }
 
 type TestWebhookResponse struct {
-	Success        bool    `json:"success"`
-	StatusCode     int     `json:"statusCode"`
-	ResponseTimeMs int64   `json:"responseTimeMs"`
-	Error          *string `json:"error,omitempty"`
+	Success        bool              `json:"success"`
+	StatusCode     int               `json:"statusCode"`
+	ResponseTimeMs int64             `json:"responseTimeMs"`
+	Error          *WebhookTestError `json:"error,omitempty"`
+}
+
+// Succeeded reports whether the test delivery reached the endpoint and got a
+// non-error status
+func (r *TestWebhookResponse) Succeeded() bool {
+	return r.Error == nil && r.StatusCode < 400
+}
+
+// WebhookTestSummary aggregates the results of repeated webhook tests
+type WebhookTestSummary struct {
+	SuccessCount          int     `json:"successCount"`
+	FailureCount          int     `json:"failureCount"`
+	AverageResponseTimeMs float64 `json:"averageResponseTimeMs"`
+	AllSucceeded          bool    `json:"allSucceeded"`
+}
+
+// TestResultsAnalysis summarizes webhook test results, such as those returned
+// by WebhooksAPI.TestWithRetry. Nil results count as failures.
+func TestResultsAnalysis(results []*TestWebhookResponse) *WebhookTestSummary {
+	summary := &WebhookTestSummary{}
+	var totalMs int64
+	var timed int
+	for _, result := range results {
+		if result == nil {
+			summary.FailureCount++
+			continue
+		}
+		if result.Succeeded() {
+			summary.SuccessCount++
+		} else {
+			summary.FailureCount++
+		}
+		totalMs += result.ResponseTimeMs
+		timed++
+	}
+	if timed > 0 {
+		summary.AverageResponseTimeMs = float64(totalMs) / float64(timed)
+	}
+	summary.AllSucceeded = len(results) > 0 && summary.FailureCount == 0
+	return summary
+}
+
+// Webhook test error types
+const (
+	WebhookTestErrorNetwork   = "network"
+	WebhookTestErrorTimeout   = "timeout"
+	WebhookTestErrorHTTPError = "http_error"
+)
+
+// WebhookTestError describes why a test delivery failed
+type WebhookTestError struct {
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+	StatusCode *int   `json:"statusCode,omitempty"`
+	LatencyMs  *int64 `json:"latencyMs,omitempty"`
+}
+
+func (e *WebhookTestError) Error() string {
+	return fmt.Sprintf("webhook test %s: %s", e.Type, e.Message)
+}
+
+// UnmarshalJSON also accepts the plain error message the server sends for
+// deliveries that did not reach the endpoint
+func (e *WebhookTestError) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		*e = WebhookTestError{Type: classifyWebhookTestError(message), Message: message}
+		return nil
+	}
+
+	type plain WebhookTestError
+	return json.Unmarshal(data, (*plain)(e))
+}
+
+// classifyWebhookTestError derives the error type from a delivery error message
+func classifyWebhookTestError(message string) string {
+	lower := strings.ToLower(message)
+	for _, marker := range []string{"timeout", "timed out", "aborted"} {
+		if strings.Contains(lower, marker) {
+			return WebhookTestErrorTimeout
+		}
+	}
+	return WebhookTestErrorNetwork
 }
\ No newline at end of file
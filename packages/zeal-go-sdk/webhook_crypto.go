@@ -0,0 +1,58 @@
+package zeal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrCiphertextTooShort is returned when an encrypted payload is shorter than its nonce
+var ErrCiphertextTooShort = errors.New("encrypted webhook payload is too short")
+
+// EncryptWebhookPayload encrypts body with AES-GCM. The key must be 16, 24 or
+// 32 bytes. The random 12-byte nonce is prepended to the ciphertext.
+func EncryptWebhookPayload(body []byte, key []byte) ([]byte, error) {
+	gcm, err := newWebhookGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(body)+gcm.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, body, nil), nil
+}
+
+// DecryptWebhookPayload decrypts a payload produced by EncryptWebhookPayload
+func DecryptWebhookPayload(encryptedBody []byte, key []byte) ([]byte, error) {
+	gcm, err := newWebhookGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encryptedBody) < gcm.NonceSize() {
+		return nil, ErrCiphertextTooShort
+	}
+	nonce, ciphertext := encryptedBody[:gcm.NonceSize()], encryptedBody[gcm.NonceSize():]
+	body, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt webhook payload: %w", err)
+	}
+	return body, nil
+}
+
+func newWebhookGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,88 @@
+package zeal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookPayloadRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	body := []byte(`{"webhook_id":"wh_1","events":[]}`)
+
+	encrypted, err := EncryptWebhookPayload(body, key)
+	if err != nil {
+		t.Fatalf("EncryptWebhookPayload failed: %v", err)
+	}
+	if bytes.Contains(encrypted, body) {
+		t.Error("Expected payload to be encrypted")
+	}
+
+	decrypted, err := DecryptWebhookPayload(encrypted, key)
+	if err != nil {
+		t.Fatalf("DecryptWebhookPayload failed: %v", err)
+	}
+	if !bytes.Equal(decrypted, body) {
+		t.Errorf("Expected %s, got %s", body, decrypted)
+	}
+}
+
+func TestDecryptWebhookPayloadRejectsTampering(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 16)
+	encrypted, _ := EncryptWebhookPayload([]byte("secret"), key)
+
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := DecryptWebhookPayload(tampered, key); err == nil {
+		t.Error("Expected tampered ciphertext to fail")
+	}
+	if _, err := DecryptWebhookPayload(encrypted, bytes.Repeat([]byte{8}, 16)); err == nil {
+		t.Error("Expected wrong key to fail")
+	}
+	if _, err := DecryptWebhookPayload(encrypted[:5], key); !errors.Is(err, ErrCiphertextTooShort) {
+		t.Errorf("Expected ErrCiphertextTooShort, got %v", err)
+	}
+	if _, err := EncryptWebhookPayload([]byte("secret"), []byte("short")); err == nil {
+		t.Error("Expected invalid key length to fail")
+	}
+}
+
+func TestWebhookHandlerDecryptsBody(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	mockClient := &Client{}
+	mockWebhooksAPI := &WebhooksAPI{client: mockClient}
+	subscription := NewWebhookSubscription(mockWebhooksAPI, &SubscriptionOptions{AutoRegister: false, EncryptionKey: key})
+
+	received := make(chan string, 1)
+	subscription.OnEvent(func(_ context.Context, event map[string]interface{}) error {
+		received <- event["type"].(string)
+		return nil
+	})
+
+	encrypted, _ := EncryptWebhookPayload([]byte(`{"webhook_id":"wh_1","events":[{"type":"node.completed"}],"metadata":{}}`), key)
+	recorder := httptest.NewRecorder()
+	subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(encrypted)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+	select {
+	case eventType := <-received:
+		if eventType != "node.completed" {
+			t.Errorf("Expected node.completed, got %s", eventType)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+
+	recorder = httptest.NewRecorder()
+	subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader([]byte(`{"plain":true}`))))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for undecryptable body, got %d", recorder.Code)
+	}
+
+	subscription.StopGracefully(context.Background())
+}
@@ -0,0 +1,41 @@
+package zeal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// EchoQueryParamVerification returns a VerificationHandler that answers with
+// the value of the given query parameter, as providers such as those using a
+// "hub.challenge" handshake expect. Requests without the parameter get 400.
+func EchoQueryParamVerification(param string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value := r.URL.Query().Get(param)
+		if value == "" {
+			http.Error(w, "Missing "+param+" parameter", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(value))
+	}
+}
+
+// ChallengeResponseVerification returns a VerificationHandler that proves
+// knowledge of secret by answering the "challenge" query parameter with its
+// HMAC-SHA256, in the same "sha256=<hex>" form as X-Zeal-Signature.
+// Requests without a challenge get 400.
+func ChallengeResponseVerification(secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		challenge := r.URL.Query().Get("challenge")
+		if challenge == "" {
+			http.Error(w, "Missing challenge parameter", http.StatusBadRequest)
+			return
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(challenge))
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("sha256=" + hex.EncodeToString(mac.Sum(nil))))
+	}
+}
@@ -0,0 +1,53 @@
+package zeal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookVerificationHandlers(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("abc"))
+	expectedChallenge := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		url     string
+		code    int
+		body    string
+	}{
+		{"echo", EchoQueryParamVerification("hub.challenge"), "/webhooks?hub.challenge=xyz", http.StatusOK, "xyz"},
+		{"echo missing", EchoQueryParamVerification("hub.challenge"), "/webhooks", http.StatusBadRequest, ""},
+		{"challenge", ChallengeResponseVerification("secret"), "/webhooks?challenge=abc", http.StatusOK, expectedChallenge},
+		{"challenge missing", ChallengeResponseVerification("secret"), "/webhooks", http.StatusBadRequest, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subscription := NewWebhookSubscription(&WebhooksAPI{client: &Client{}}, &SubscriptionOptions{
+				AutoRegister:        false,
+				VerificationHandler: test.handler,
+			})
+			recorder := httptest.NewRecorder()
+			subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodGet, test.url, nil))
+			if recorder.Code != test.code {
+				t.Fatalf("Expected %d, got %d", test.code, recorder.Code)
+			}
+			if test.body != "" && recorder.Body.String() != test.body {
+				t.Errorf("Expected body %q, got %q", test.body, recorder.Body.String())
+			}
+		})
+	}
+
+	subscription := NewWebhookSubscription(&WebhooksAPI{client: &Client{}}, &SubscriptionOptions{AutoRegister: false})
+	recorder := httptest.NewRecorder()
+	subscription.webhookHandler(recorder, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 without a verification handler, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,239 @@
+package zeal
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExportOptions configures ExportWorkflow and ExportWorkflowWithTemplates
+type ExportOptions struct {
+	// GraphID selects the graph to export, defaulting to the main graph
+	GraphID *string `json:"graphId,omitempty"`
+	// Namespace is the template namespace used by ExportWorkflowWithTemplates,
+	// defaulting to "default"
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// WorkflowExport is a portable copy of one graph of a workflow. Nodes refer to
+// their templates by TemplateID.
+type WorkflowExport struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Nodes       []NodeDetail           `json:"nodes"`
+	Connections []ConnectionDetail     `json:"connections"`
+	Groups      []GroupDetail          `json:"groups"`
+}
+
+// WorkflowExportBundle is a WorkflowExport together with the templates its
+// nodes use, so it can be imported where those templates do not exist
+type WorkflowExportBundle struct {
+	Export    *WorkflowExport `json:"export"`
+	Templates []NodeTemplate  `json:"templates"`
+	Namespace string          `json:"namespace"`
+}
+
+// WorkflowImportResult describes a workflow created by ImportWorkflow
+type WorkflowImportResult struct {
+	WorkflowID string
+	// NodeIDs maps exported node IDs to the IDs of the imported nodes
+	NodeIDs map[string]string
+	// RegisteredTemplates lists the bundled templates that were missing from
+	// the namespace and were registered
+	RegisteredTemplates []string
+}
+
+// ExportWorkflow exports a graph of a workflow
+func (api *OrchestratorAPI) ExportWorkflow(ctx context.Context, workflowID string, opts *ExportOptions) (*WorkflowExport, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	state, err := api.GetWorkflowState(ctx, workflowID, opts.GraphID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow state: %w", err)
+	}
+	graph, err := decodeWorkflowGraph(state)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &WorkflowExport{
+		Name:        state.Name,
+		Description: state.Description,
+		Nodes:       graph.Nodes,
+		Connections: graph.Connections,
+		Groups:      graph.Groups,
+	}
+	if metadata, ok := state.Metadata.(map[string]interface{}); ok {
+		export.Metadata = metadata
+	}
+	return export, nil
+}
+
+// ExportWorkflowWithTemplates exports a workflow like ExportWorkflow and
+// bundles the templates its nodes use. It fails if a template is not found in
+// the namespace.
+func (api *OrchestratorAPI) ExportWorkflowWithTemplates(ctx context.Context, workflowID string, opts *ExportOptions) (*WorkflowExportBundle, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	export, err := api.ExportWorkflow(ctx, workflowID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := api.client.templates.List(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	byID := make(map[string]NodeTemplate, len(available.Templates))
+	for _, template := range available.Templates {
+		byID[template.ID] = template
+	}
+
+	bundle := &WorkflowExportBundle{Export: export, Namespace: namespace, Templates: []NodeTemplate{}}
+	seen := make(map[string]bool)
+	for _, node := range export.Nodes {
+		if node.TemplateID == "" || seen[node.TemplateID] {
+			continue
+		}
+		seen[node.TemplateID] = true
+		template, ok := byID[node.TemplateID]
+		if !ok {
+			return nil, fmt.Errorf("template %s used by node %s not found in namespace %s", node.TemplateID, node.ID, namespace)
+		}
+		bundle.Templates = append(bundle.Templates, template)
+	}
+	return bundle, nil
+}
+
+// ImportWorkflow creates a new workflow from a bundle. Bundled templates
+// missing from the bundle's namespace, "default" if unset, are registered
+// first; templates that already exist are left unchanged. Nodes, connections
+// and groups are created in the new workflow's main graph.
+//
+// The bundle is checked before anything is created, so connections and groups
+// referring to nodes missing from the export are rejected up front. The server
+// cannot delete workflows, so if creating a node, connection or group fails
+// the partially imported workflow is left in place; the returned result still
+// identifies it by WorkflowID alongside the error.
+func (api *OrchestratorAPI) ImportWorkflow(ctx context.Context, bundle *WorkflowExportBundle) (*WorkflowImportResult, error) {
+	if bundle == nil || bundle.Export == nil {
+		return nil, fmt.Errorf("workflow export bundle is empty")
+	}
+	export := bundle.Export
+	if err := validateWorkflowExport(export); err != nil {
+		return nil, err
+	}
+	namespace := bundle.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	result := &WorkflowImportResult{NodeIDs: make(map[string]string)}
+
+	if len(bundle.Templates) > 0 {
+		existing, err := api.client.templates.List(ctx, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list templates: %w", err)
+		}
+		present := make(map[string]bool, len(existing.Templates))
+		for _, template := range existing.Templates {
+			present[template.ID] = true
+		}
+
+		var missing []NodeTemplate
+		for _, template := range bundle.Templates {
+			if !present[template.ID] {
+				missing = append(missing, template)
+				result.RegisteredTemplates = append(result.RegisteredTemplates, template.ID)
+			}
+		}
+		if len(missing) > 0 {
+			if _, err := api.client.templates.Register(ctx, RegisterTemplatesRequest{Namespace: namespace, Templates: missing}); err != nil {
+				return nil, fmt.Errorf("failed to register templates: %w", err)
+			}
+		}
+	}
+
+	req := CreateWorkflowRequest{Name: export.Name, Metadata: export.Metadata}
+	if export.Description != "" {
+		req.Description = &export.Description
+	}
+	created, err := api.CreateWorkflow(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workflow: %w", err)
+	}
+	result.WorkflowID = created.WorkflowID
+
+	for _, node := range export.Nodes {
+		added, err := api.AddNode(ctx, AddNodeRequest{
+			WorkflowID:   created.WorkflowID,
+			TemplateID:   node.TemplateID,
+			Position:     node.Position,
+			Properties:   node.Properties,
+			Metadata:     node.Metadata,
+			InstanceName: node.InstanceName,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to add node %s: %w", node.ID, err)
+		}
+		result.NodeIDs[node.ID] = added.NodeID
+	}
+
+	for _, connection := range export.Connections {
+		source, target := connection.Source, connection.Target
+		source.NodeID = result.NodeIDs[source.NodeID]
+		target.NodeID = result.NodeIDs[target.NodeID]
+		if _, err := api.ConnectNodes(ctx, ConnectNodesRequest{WorkflowID: created.WorkflowID, Source: source, Target: target}); err != nil {
+			return result, fmt.Errorf("failed to add connection %s: %w", connection.ID, err)
+		}
+	}
+
+	for _, group := range export.Groups {
+		nodeIDs := make([]string, 0, len(group.MemberNodeIDs))
+		for _, id := range group.MemberNodeIDs {
+			nodeIDs = append(nodeIDs, result.NodeIDs[id])
+		}
+		if _, err := api.CreateGroup(ctx, CreateGroupRequest{
+			WorkflowID:  created.WorkflowID,
+			Title:       group.Title,
+			NodeIDs:     nodeIDs,
+			Color:       group.Color,
+			Description: group.Description,
+		}); err != nil {
+			return result, fmt.Errorf("failed to add group %s: %w", group.ID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// validateWorkflowExport checks that every connection and group of export
+// refers only to nodes in the export
+func validateWorkflowExport(export *WorkflowExport) error {
+	nodes := make(map[string]bool, len(export.Nodes))
+	for _, node := range export.Nodes {
+		nodes[node.ID] = true
+	}
+	for _, connection := range export.Connections {
+		for _, endpoint := range []NodePort{connection.Source, connection.Target} {
+			if !nodes[endpoint.NodeID] {
+				return fmt.Errorf("connection %s refers to node %s, which is not in the export", connection.ID, endpoint.NodeID)
+			}
+		}
+	}
+	for _, group := range export.Groups {
+		for _, id := range group.MemberNodeIDs {
+			if !nodes[id] {
+				return fmt.Errorf("group %s refers to node %s, which is not in the export", group.ID, id)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package zeal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExportAndImportWorkflow(t *testing.T) {
+	var mu sync.Mutex
+	var registered []string
+	var connections []ConnectNodesRequest
+	var groups []CreateGroupRequest
+	nodeCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/api/zip/orchestrator/workflows/wf-1/state":
+			w.Write([]byte(`{"workflowId":"wf-1","name":"Billing","description":"Nightly billing","metadata":{"team":"finance"},"state":{
+				"nodes":[{"id":"a","templateId":"http"},{"id":"b","templateId":"transform"},{"id":"c","templateId":"http"}],
+				"connections":[{"id":"c1","source":{"nodeId":"a","portId":"out"},"target":{"nodeId":"b","portId":"in"}}],
+				"groups":[{"id":"g1","title":"Fetch","nodeIds":["a","c"]}]}}`))
+		case r.URL.Path == "/api/zip/templates/source":
+			w.Write([]byte(`{"templates":[{"id":"http","title":"HTTP"},{"id":"transform","title":"Transform"},{"id":"unused"}]}`))
+		case r.URL.Path == "/api/zip/templates/target":
+			w.Write([]byte(`{"templates":[{"id":"http","title":"HTTP"}]}`))
+		case r.URL.Path == "/api/zip/templates/register":
+			var req RegisterTemplatesRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			for _, template := range req.Templates {
+				registered = append(registered, req.Namespace+"/"+template.ID)
+			}
+			w.Write([]byte(`{"success":true}`))
+		case r.URL.Path == "/api/zip/orchestrator/workflows":
+			w.Write([]byte(`{"workflowId":"wf-2","name":"Billing"}`))
+		case r.URL.Path == "/api/zip/orchestrator/nodes":
+			nodeCount++
+			fmt.Fprintf(w, `{"nodeId":"new-%d"}`, nodeCount)
+		case r.URL.Path == "/api/zip/orchestrator/connections":
+			var req ConnectNodesRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			connections = append(connections, req)
+			w.Write([]byte(`{"connectionId":"c"}`))
+		case strings.HasPrefix(r.URL.Path, "/api/zip/orchestrator/groups"):
+			var req CreateGroupRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			groups = append(groups, req)
+			w.Write([]byte(`{"success":true,"groupId":"g"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	orchestrator := client.Orchestrator()
+
+	bundle, err := orchestrator.ExportWorkflowWithTemplates(context.Background(), "wf-1", &ExportOptions{Namespace: "source"})
+	if err != nil {
+		t.Fatalf("ExportWorkflowWithTemplates failed: %v", err)
+	}
+	if bundle.Export.Name != "Billing" || bundle.Export.Metadata["team"] != "finance" || len(bundle.Export.Nodes) != 3 {
+		t.Errorf("Unexpected export %+v", bundle.Export)
+	}
+	var templateIDs []string
+	for _, template := range bundle.Templates {
+		templateIDs = append(templateIDs, template.ID)
+	}
+	if !reflect.DeepEqual(templateIDs, []string{"http", "transform"}) {
+		t.Errorf("Expected referenced templates only, got %v", templateIDs)
+	}
+
+	bundle.Namespace = "target"
+	result, err := orchestrator.ImportWorkflow(context.Background(), bundle)
+	if err != nil {
+		t.Fatalf("ImportWorkflow failed: %v", err)
+	}
+	if result.WorkflowID != "wf-2" || !reflect.DeepEqual(result.RegisteredTemplates, []string{"transform"}) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if !reflect.DeepEqual(registered, []string{"target/transform"}) {
+		t.Errorf("Expected only the missing template to be registered, got %v", registered)
+	}
+	if len(connections) != 1 || connections[0].Source.NodeID != "new-1" || connections[0].Target.NodeID != "new-2" || connections[0].WorkflowID != "wf-2" {
+		t.Errorf("Expected connection between imported nodes, got %+v", connections)
+	}
+	if len(groups) != 1 || !reflect.DeepEqual(groups[0].NodeIDs, []string{"new-1", "new-3"}) {
+		t.Errorf("Expected group of imported nodes, got %+v", groups)
+	}
+}
+
+func TestExportWorkflowWithMissingTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/zip/templates/default" {
+			w.Write([]byte(`{"templates":[]}`))
+			return
+		}
+		w.Write([]byte(`{"workflowId":"wf-1","state":{"nodes":[{"id":"a","templateId":"http"}]}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	if _, err := client.Orchestrator().ExportWorkflowWithTemplates(context.Background(), "wf-1", nil); err == nil || !strings.Contains(err.Error(), "template http") {
+		t.Errorf("Expected missing template error, got %v", err)
+	}
+}
+
+func TestImportWorkflowRejectsDanglingReferences(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientConfig{BaseURL: server.URL})
+	nodes := []NodeDetail{{ID: "a"}, {ID: "b"}}
+
+	connection := &WorkflowExportBundle{Export: &WorkflowExport{
+		Nodes:       nodes,
+		Connections: []ConnectionDetail{{ID: "c1", Source: NodePort{NodeID: "a"}, Target: NodePort{NodeID: "missing"}}},
+	}}
+	if _, err := client.Orchestrator().ImportWorkflow(context.Background(), connection); err == nil || !strings.Contains(err.Error(), "connection c1") {
+		t.Errorf("Expected dangling connection error, got %v", err)
+	}
+
+	group := &WorkflowExportBundle{Export: &WorkflowExport{
+		Nodes:  nodes,
+		Groups: []GroupDetail{{ID: "g1", MemberNodeIDs: []string{"a", "missing"}}},
+	}}
+	if _, err := client.Orchestrator().ImportWorkflow(context.Background(), group); err == nil || !strings.Contains(err.Error(), "group g1") {
+		t.Errorf("Expected dangling group member error, got %v", err)
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests for an invalid bundle, got %d", requests)
+	}
+}